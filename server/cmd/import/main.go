@@ -1,28 +1,53 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/csv"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
-	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq" // PostgresSQL driver
+	"serve/config"
+	"serve/services"
 )
 
 var TotalProjectsOnSheet = 56
 var serveDay = time.Date(2025, 7, 12, 8, 0, 0, 0, time.UTC)
 var serveDayPostgresStyle = serveDay.Format("2006-01-02 15:04:05-07:00") // "2025-07-12 00:00:00+00:00"
 
+// defaultLocation is used for projects whose address is still to be determined (Castle Rock)
+var defaultLocation = services.GeocodingResult{Latitude: 39.491482, Longitude: -104.874878}
+
 func main() {
+	// Parse command line flags
+	var envFile, csvFile string
+	flag.StringVar(&envFile, "env", ".env", "Path to environment file")
+	flag.StringVar(&csvFile, "file", "../csv/data.csv", "Path to the project spreadsheet export")
+	flag.Parse()
+
+	// Load environment variables
+	if err := godotenv.Load(envFile); err != nil {
+		log.Printf("Warning: Could not load %s file: %v", envFile, err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	geocoder := newGeocoder(cfg)
+
 	typeMap1 := make(map[string]int)
 	var typInserts []typeInsert
 	var projects []Project
-	file, err := os.Open("csv/data.csv")
+	file, err := os.Open(csvFile)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -64,7 +89,7 @@ func main() {
 
 		splitProjectLead := strings.Split(record[9], "\n")
 
-		lat, long := GeocodeAddress(record[13])
+		lat, long := geocodeAddress(geocoder, record[13])
 
 		p := Project{
 			GoogleID:        gID,
@@ -173,46 +198,36 @@ func sanitize(s string) string {
 	return strings.ReplaceAll(s, "'", "''")
 }
 
-// GeocodeAddress converts an address to latitude and longitude
-func GeocodeAddress(address string) (float64, float64) {
-	if strings.Contains(strings.ToLower(address), "tbd") || strings.TrimSpace(address) == "" {
-		return 39.491482, -104.874878
+// newGeocoder returns the app's geocoder, using the geocode cache when the database is reachable
+func newGeocoder(cfg *config.Config) services.Geocoder {
+	db, err := sql.Open("postgres", cfg.GetDBConnString())
+	if err == nil {
+		err = db.Ping()
 	}
-	apiKey := os.Getenv("GOOGLE_MAPS_API_KEY")
-
-	// Build the request URL
-	baseURL := "https://maps.googleapis.com/maps/api/place/textsearch/json"
-	params := url.Values{}
-	params.Add("query", address)
-	params.Add("key", apiKey)
-	requestURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
-
-	// Send the request
-	resp, err := http.Get(requestURL)
 	if err != nil {
-		log.Fatal("could not connect to the api")
+		log.Printf("Warning: geocoding without the cache, could not connect to the database: %v", err)
+		return services.NewGeocoder(cfg, nil)
 	}
-	defer resp.Body.Close()
+	return services.NewGeocoder(cfg, db)
+}
 
-	// Read and parse the response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatal("error reading body")
+// geocodeAddress converts an address to latitude and longitude, using the default location for
+// addresses that are missing, still to be determined or cannot be found
+func geocodeAddress(geocoder services.Geocoder, address string) (float64, float64) {
+	if strings.Contains(strings.ToLower(address), "tbd") || strings.TrimSpace(address) == "" {
+		return defaultLocation.Latitude, defaultLocation.Longitude
 	}
 
-	var placesResponse GooglePlacesResponse
-	if err := json.Unmarshal(body, &placesResponse); err != nil {
-		log.Fatal("could not parse")
+	result, err := geocoder.Geocode(context.Background(), address)
+	if errors.Is(err, services.ErrNoResults) {
+		log.Printf("could not find %q, using the default location", address)
+		return defaultLocation.Latitude, defaultLocation.Longitude
 	}
-
-	// Check if the response has results
-	if placesResponse.Status != "OK" || len(placesResponse.Results) == 0 {
-		return 39.491482, -104.874878
+	if err != nil {
+		log.Fatalf("could not geocode %q: %v", address, err)
 	}
 
-	// Get the first result
-	result := placesResponse.Results[0]
-	return result.Geometry.Location.Lat, result.Geometry.Location.Lng
+	return result.Latitude, result.Longitude
 }
 
 type Project struct {
@@ -241,17 +256,3 @@ type typeInsert struct {
 	projectID int
 	typeID    int
 }
-
-// GooglePlacesResponse represents the response from Google Places API
-type GooglePlacesResponse struct {
-	Results []struct {
-		FormattedAddress string `json:"formatted_address"`
-		Geometry         struct {
-			Location struct {
-				Lat float64 `json:"lat"`
-				Lng float64 `json:"lng"`
-			} `json:"location"`
-		} `json:"geometry"`
-	} `json:"results"`
-	Status string `json:"status"`
-}
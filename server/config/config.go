@@ -2,8 +2,18 @@ package config
 
 import (
 	"fmt"
+	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+	_ "time/tzdata" // timezones must load in containers without a zoneinfo database
+)
+
+// Auth modes
+const (
+	AuthModeAuth0 = "auth0"
+	AuthModeLocal = "local"
 )
 
 // Config holds all configuration for the application
@@ -11,9 +21,15 @@ type Config struct {
 	DevMode bool
 	// Date
 	ServeDay string
+	// Timezone is the IANA name of the timezone project shift times are given in
+	Timezone string
 
 	// Server config
 	ServerPort string
+	// AppURL is the public URL of the webapp, used to build links sent by email
+	AppURL string
+	// APIURL is the public URL of this server, used to build links to feeds it serves
+	APIURL string
 
 	// Database config
 	DBHost              string
@@ -29,6 +45,9 @@ type Config struct {
 	Auth0Audience     string
 	Auth0ClientID     string
 	Auth0ClientSecret string
+	// AuthMode is AuthModeAuth0, or AuthModeLocal to sign and check tokens with a generated key for
+	// offline development. Local tokens are refused outside dev mode.
+	AuthMode string
 
 	// Email config
 	MailHost string
@@ -42,13 +61,42 @@ type Config struct {
 	ClearStreamAPIKey string
 	TextFrom          string
 
-	// Google Maps API config
+	// Geocoding config - the provider is "google" or "nominatim"; Nominatim is also the fallback for Google
 	GoogleMapsAPIKey string
+	GeocoderProvider string
+	NominatimURL     string
+
+	// Location validation - admins are warned when project coordinates are this far from the address
+	LocationWarningMeters int
+
+	// Drive estimates - projects show the drive from this address, usually the church campus
+	CampusAddress string
+
+	// Private locations - exact addresses are revealed to volunteers this many days before the project,
+	// unless the project sets its own reveal time
+	LocationRevealDays int
+
+	// Group reservations - seats a group has not claimed are released to everyone this many days before
+	// the project, unless the leader picks their own release time
+	GroupReleaseDays int
+
+	// Deadlines - volunteers stop being able to register or cancel this many hours before a project, unless
+	// its event or the project itself sets other deadlines
+	RegistrationCloseHours int
+	CancellationCloseHours int
 
 	// Recaptcha config
 	RecaptchaProject string
 	RecaptchaKey     string
 	RecaptchaAction  string
+
+	// Retention config - soft deleted records are purged after this many days
+	TrashRetentionDays int
+
+	// Change notice config - registrants hear about project changes once edits have been quiet for
+	// ChangeNoticeQuietMinutes, or at most ChangeNoticeMaxWaitMinutes after the first edit
+	ChangeNoticeQuietMinutes   int
+	ChangeNoticeMaxWaitMinutes int
 }
 
 // Load loads configuration from environment variables
@@ -57,9 +105,12 @@ func Load() (*Config, error) {
 		DevMode: getEnv("DEV_MODE", "true") == "true",
 		// Serve Day Date
 		ServeDay: getEnv("SERVE_DAY", "07-12-25"),
+		Timezone: getEnv("TIMEZONE", "America/Denver"),
 
 		// Server config with default
 		ServerPort: getEnv("PORT", "8080"),
+		AppURL:     strings.TrimSuffix(getEnv("APP_URL", "http://localhost:3000"), "/"),
+		APIURL:     strings.TrimSuffix(getEnv("API_URL", "http://localhost:"+getEnv("PORT", "8080")), "/"),
 
 		// Database config
 		DBHost:              getEnv("PGHOST", "localhost"),
@@ -71,69 +122,84 @@ func Load() (*Config, error) {
 		DBURL:               getEnv("DATABASE_URL", ""),
 
 		// Auth0 config - in dev mode use placeholders
-		Auth0Domain:       getEnv("AUTH0_DOMAIN", "dev-placeholder.auth0.com"),
+		Auth0Domain:       getEnv("AUTH0_DOMAIN", placeholderAuth0Domain),
 		Auth0Audience:     getEnv("AUTH0_AUDIENCE", "https://api.projectregistration.com"),
-		Auth0ClientID:     getEnv("AUTH0_CLIENT_ID", "dev-client-id"),
-		Auth0ClientSecret: getEnv("AUTH0_CLIENT_SECRET", "dev-client-secret"),
+		Auth0ClientID:     getEnv("AUTH0_CLIENT_ID", placeholderAuth0ClientID),
+		Auth0ClientSecret: getEnv("AUTH0_CLIENT_SECRET", placeholderAuth0ClientSecret),
+		AuthMode:          getEnv("AUTH_MODE", AuthModeAuth0),
 
 		// Email config - in dev mode use placeholders
-		MailHost: getEnv("MAIL_HOST", "smtp.example.com"),
-		MailKey:  getEnv("MAIL_KEY", "apikey"),
+		MailHost: getEnv("MAIL_HOST", placeholderMailHost),
+		MailKey:  getEnv("MAIL_KEY", placeholderAPIKey),
 		MailFrom: getEnv("MAIL_FROM", "admin@serveday.journeycolorado.com"),
 		MailPort: getEnv("MAIL_REPLYTO_EMAIL", "scarrington@gmail.com"),
 		MailUser: getEnv("MAIL_REPLYTO_NAME", "Scott Carrington"),
 
 		// Text config
-		ClearStreamAPIKey: getEnv("CS_API_KEY", "apikey"),
+		ClearStreamAPIKey: getEnv("CS_API_KEY", placeholderAPIKey),
 		TextFrom:          getEnv("CS_TEXT_FROM", "9007"),
 
 		// Google Maps API config
 		GoogleMapsAPIKey: getEnv("GOOGLE_MAPS_API_KEY", ""),
+		GeocoderProvider: getEnv("GEOCODER", "google"),
+		NominatimURL:     strings.TrimSuffix(getEnv("NOMINATIM_URL", "https://nominatim.openstreetmap.org"), "/"),
+
+		// Location validation config
+		LocationWarningMeters: getEnvInt("LOCATION_WARNING_METERS", 1000),
+
+		// Drive estimate config
+		CampusAddress: getEnv("CAMPUS_ADDRESS", ""),
+
+		// Private location config
+		LocationRevealDays: getEnvInt("LOCATION_REVEAL_DAYS", 7),
+
+		// Group reservation config
+		GroupReleaseDays: getEnvInt("GROUP_RELEASE_DAYS", 7),
+
+		// Deadline config
+		RegistrationCloseHours: getEnvInt("REGISTRATION_CLOSE_HOURS", 0),
+		CancellationCloseHours: getEnvInt("CANCELLATION_CLOSE_HOURS", 0),
 
 		// Google Maps API config
 		RecaptchaKey:     getEnv("RECAPTCHA_KEY", ""),
 		RecaptchaProject: getEnv("RECAPTCHA_PROJECT", ""),
 		RecaptchaAction:  getEnv("RECAPTCHA_ACTION", ""),
-	}
 
-	// In production mode, validate required configuration
-	if !config.DevMode {
-		var missingVars []string
+		// Retention config
+		TrashRetentionDays: getEnvInt("TRASH_RETENTION_DAYS", 30),
 
-		// For Auth0
-		if getEnv("AUTH0_DOMAIN", "") == "" {
-			missingVars = append(missingVars, "AUTH0_DOMAIN")
-		}
-		if getEnv("AUTH0_AUDIENCE", "") == "" {
-			missingVars = append(missingVars, "AUTH0_AUDIENCE")
-		}
-		if getEnv("AUTH0_CLIENT_ID", "") == "" {
-			missingVars = append(missingVars, "AUTH0_CLIENT_ID")
-		}
-		if getEnv("AUTH0_CLIENT_SECRET", "") == "" {
-			missingVars = append(missingVars, "AUTH0_CLIENT_SECRET")
-		}
+		// Change notice config
+		ChangeNoticeQuietMinutes:   getEnvInt("CHANGE_NOTICE_QUIET_MINUTES", 15),
+		ChangeNoticeMaxWaitMinutes: getEnvInt("CHANGE_NOTICE_MAX_WAIT_MINUTES", 60),
+	}
 
-		// For Email
-		if getEnv("MAIL_HOST", "") == "" {
-			missingVars = append(missingVars, "MAIL_HOST")
-		}
-		if getEnv("MAIL_KEY", "") == "" {
-			missingVars = append(missingVars, "MAIL_KEY")
+	// Problems stop the server in production; in dev mode the placeholders above are expected
+	if err := config.Validate(); err != nil {
+		if !config.DevMode {
+			return nil, err
 		}
+		log.Printf("continuing in dev mode with %v", err)
+	}
 
-		// For Google Maps API
-		if getEnv("GOOGLE_MAPS_API_KEY", "") == "" {
-			missingVars = append(missingVars, "GOOGLE_MAPS_API_KEY")
-		}
+	return config, nil
+}
 
-		// If any required variables are missing, return an error
-		if len(missingVars) > 0 {
-			return nil, fmt.Errorf("missing required environment variables: %s", strings.Join(missingVars, ", "))
-		}
+// ServeDayDate returns the serve day as a date, starting at 8:00 UTC like the rest of the projects
+func (c *Config) ServeDayDate() (time.Time, error) {
+	day, err := time.Parse("01-02-06", c.ServeDay)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SERVE_DAY %q: %w", c.ServeDay, err)
 	}
+	return day.Add(8 * time.Hour), nil
+}
 
-	return config, nil
+// Location returns the timezone project shift times are given in
+func (c *Config) Location() (*time.Location, error) {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TIMEZONE %q: %w", c.Timezone, err)
+	}
+	return loc, nil
 }
 
 // GetDBConnString returns the database connection string
@@ -156,3 +222,12 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvInt gets an integer environment variable or returns a default value if it is unset or invalid
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
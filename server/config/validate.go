@@ -0,0 +1,257 @@
+package config
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Placeholders used as defaults in dev mode. A setting left at its placeholder counts as unset.
+const (
+	placeholderAuth0Domain       = "dev-placeholder.auth0.com"
+	placeholderAuth0ClientID     = "dev-client-id"
+	placeholderAuth0ClientSecret = "dev-client-secret"
+	placeholderMailHost          = "smtp.example.com"
+	placeholderAPIKey            = "apikey"
+)
+
+// Integrations checked by Validate
+const (
+	IntegrationAuth      = "auth"
+	IntegrationDatabase  = "database"
+	IntegrationMail      = "mail"
+	IntegrationSMS       = "sms"
+	IntegrationMaps      = "maps"
+	IntegrationRecaptcha = "recaptcha"
+	IntegrationServer    = "server"
+)
+
+// Problem is one thing wrong with a setting
+type Problem struct {
+	Setting string `json:"setting"`
+	Message string `json:"message"`
+}
+
+// String describes the problem with the setting named first
+func (p Problem) String() string {
+	return p.Setting + " " + p.Message
+}
+
+// ValidationError lists every problem found with the configuration
+type ValidationError struct {
+	Problems []Problem
+}
+
+// Error lists every problem on one line
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		messages[i] = p.String()
+	}
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+// Integration reports whether an external service is set up. Optional integrations with none of their
+// settings given are simply not configured; required ones always report what is missing.
+type Integration struct {
+	Name       string    `json:"name"`
+	Required   bool      `json:"required"`
+	Configured bool      `json:"configured"`
+	Problems   []Problem `json:"problems,omitempty"`
+}
+
+// Validate checks every integration's settings, returning a *ValidationError listing all of the
+// problems at once, or nil if there are none
+func (c *Config) Validate() error {
+	var problems []Problem
+	for _, i := range c.Integrations() {
+		problems = append(problems, i.Problems...)
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// Integrations reports the state of each integration. Secrets are never included.
+func (c *Config) Integrations() []Integration {
+	return []Integration{
+		c.checkServer(),
+		c.checkAuth(),
+		c.checkDatabase(),
+		c.checkMail(),
+		c.checkSMS(),
+		c.checkMaps(),
+		c.checkRecaptcha(),
+	}
+}
+
+// problems collects the problems of one integration
+type problems []Problem
+
+// add records a problem with a setting
+func (p *problems) add(setting, format string, args ...any) {
+	*p = append(*p, Problem{Setting: setting, Message: fmt.Sprintf(format, args...)})
+}
+
+// require adds a problem if the value is empty or still its dev mode placeholder
+func (p *problems) require(setting, value string, placeholders ...string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		p.add(setting, "is not set")
+		return
+	}
+	for _, placeholder := range placeholders {
+		if value == placeholder {
+			p.add(setting, "is still the dev mode placeholder")
+			return
+		}
+	}
+}
+
+// url adds a problem unless the value is an absolute http or https URL
+func (p *problems) url(setting, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		p.add(setting, "must be an absolute http or https URL")
+	}
+}
+
+// positive adds a problem unless the value is greater than zero
+func (p *problems) positive(setting string, value int) {
+	if value <= 0 {
+		p.add(setting, "must be greater than zero")
+	}
+}
+
+// integration reports an integration, which is only configured if it has no problems
+func integration(name string, required, configured bool, p problems) Integration {
+	return Integration{Name: name, Required: required, Configured: configured && len(p) == 0, Problems: p}
+}
+
+// checkServer checks the server's own settings
+func (c *Config) checkServer() Integration {
+	var p problems
+	if _, err := strconv.Atoi(c.ServerPort); err != nil {
+		p.add("PORT", "must be a number")
+	}
+	p.url("APP_URL", c.AppURL)
+	p.url("API_URL", c.APIURL)
+	if _, err := c.ServeDayDate(); err != nil {
+		p.add("SERVE_DAY", "must be a date like 07-12-25")
+	}
+	if _, err := c.Location(); err != nil {
+		p.add("TIMEZONE", "must be an IANA timezone such as America/Denver")
+	}
+	p.positive("LOCATION_WARNING_METERS", c.LocationWarningMeters)
+	p.positive("TRASH_RETENTION_DAYS", c.TrashRetentionDays)
+	p.positive("CHANGE_NOTICE_QUIET_MINUTES", c.ChangeNoticeQuietMinutes)
+	if c.ChangeNoticeMaxWaitMinutes < c.ChangeNoticeQuietMinutes {
+		p.add("CHANGE_NOTICE_MAX_WAIT_MINUTES", "must be at least CHANGE_NOTICE_QUIET_MINUTES")
+	}
+	if c.LocationRevealDays < 0 {
+		p.add("LOCATION_REVEAL_DAYS", "must not be negative")
+	}
+	if c.GroupReleaseDays < 0 {
+		p.add("GROUP_RELEASE_DAYS", "must not be negative")
+	}
+	if c.RegistrationCloseHours < 0 {
+		p.add("REGISTRATION_CLOSE_HOURS", "must not be negative")
+	}
+	if c.CancellationCloseHours < 0 {
+		p.add("CANCELLATION_CLOSE_HOURS", "must not be negative")
+	}
+	return integration(IntegrationServer, true, true, p)
+}
+
+// checkAuth checks token validation settings
+func (c *Config) checkAuth() Integration {
+	var p problems
+	switch c.AuthMode {
+	case AuthModeAuth0:
+		p.require("AUTH0_DOMAIN", c.Auth0Domain, placeholderAuth0Domain)
+		if strings.Contains(c.Auth0Domain, "/") {
+			p.add("AUTH0_DOMAIN", "must be a bare domain such as tenant.us.auth0.com, without a scheme or path")
+		} else if _, err := url.Parse("https://" + c.Auth0Domain + "/"); err != nil {
+			p.add("AUTH0_DOMAIN", "is not a valid domain")
+		}
+		p.require("AUTH0_CLIENT_ID", c.Auth0ClientID, placeholderAuth0ClientID)
+		p.require("AUTH0_CLIENT_SECRET", c.Auth0ClientSecret, placeholderAuth0ClientSecret)
+	case AuthModeLocal:
+		if !c.DevMode {
+			p.add("AUTH_MODE", "must be %q outside dev mode", AuthModeAuth0)
+		}
+	default:
+		p.add("AUTH_MODE", "must be %q or %q", AuthModeAuth0, AuthModeLocal)
+	}
+	p.require("AUTH0_AUDIENCE", c.Auth0Audience)
+	return integration(IntegrationAuth, true, true, p)
+}
+
+// checkDatabase checks the database connection settings
+func (c *Config) checkDatabase() Integration {
+	var p problems
+	if c.DBURL != "" {
+		if u, err := url.Parse(c.DBURL); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			p.add("DATABASE_URL", "must be a postgres:// URL")
+		}
+		return integration(IntegrationDatabase, true, true, p)
+	}
+
+	p.require("PGHOST", c.DBHost)
+	if _, err := strconv.Atoi(c.DBPort); err != nil {
+		p.add("PGPORT", "must be a number")
+	}
+	p.require("PGUSER", c.DBUser)
+	p.require("PGDATABASE", c.DBName)
+	return integration(IntegrationDatabase, true, true, p)
+}
+
+// checkMail checks the Mailtrap settings used for email
+func (c *Config) checkMail() Integration {
+	var p problems
+	p.require("MAIL_HOST", c.MailHost, placeholderMailHost)
+	p.require("MAIL_KEY", c.MailKey, placeholderAPIKey)
+	if _, err := mail.ParseAddress(c.MailFrom); err != nil {
+		p.add("MAIL_FROM", "must be an email address")
+	}
+	return integration(IntegrationMail, true, true, p)
+}
+
+// checkSMS checks the ClearStream settings used for texts
+func (c *Config) checkSMS() Integration {
+	var p problems
+	configured := c.ClearStreamAPIKey != "" && c.ClearStreamAPIKey != placeholderAPIKey
+	if configured {
+		p.require("CS_TEXT_FROM", c.TextFrom)
+	}
+	return integration(IntegrationSMS, false, configured, p)
+}
+
+// checkMaps checks the geocoder settings
+func (c *Config) checkMaps() Integration {
+	var p problems
+	switch c.GeocoderProvider {
+	case "google":
+		p.require("GOOGLE_MAPS_API_KEY", c.GoogleMapsAPIKey)
+	case "nominatim":
+	default:
+		p.add("GEOCODER", "must be \"google\" or \"nominatim\"")
+	}
+	p.url("NOMINATIM_URL", c.NominatimURL)
+	return integration(IntegrationMaps, true, true, p)
+}
+
+// checkRecaptcha checks the reCAPTCHA settings used for partner submissions
+func (c *Config) checkRecaptcha() Integration {
+	var p problems
+	configured := c.RecaptchaKey != "" || c.RecaptchaProject != "" || c.RecaptchaAction != ""
+	if configured {
+		p.require("RECAPTCHA_KEY", c.RecaptchaKey)
+		p.require("RECAPTCHA_PROJECT", c.RecaptchaProject)
+		p.require("RECAPTCHA_ACTION", c.RecaptchaAction)
+	}
+	return integration(IntegrationRecaptcha, false, configured, p)
+}
@@ -0,0 +1,112 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"serve/config"
+)
+
+// validConfig returns a production configuration with every required integration set up
+func validConfig() *config.Config {
+	return &config.Config{
+		ServeDay:                   "07-12-25",
+		Timezone:                   "America/Denver",
+		ServerPort:                 "8080",
+		AppURL:                     "https://serve.example.org",
+		APIURL:                     "https://api.serve.example.org",
+		DBHost:                     "db",
+		DBPort:                     "5432",
+		DBUser:                     "serve",
+		DBName:                     "serve",
+		Auth0Domain:                "tenant.us.auth0.com",
+		Auth0Audience:              "https://api.serve.example.org",
+		Auth0ClientID:              "client",
+		Auth0ClientSecret:          "secret",
+		AuthMode:                   config.AuthModeAuth0,
+		MailHost:                   "send.api.mailtrap.io",
+		MailKey:                    "key",
+		MailFrom:                   "admin@serve.example.org",
+		ClearStreamAPIKey:          "apikey",
+		GeocoderProvider:           "nominatim",
+		NominatimURL:               "https://nominatim.openstreetmap.org",
+		LocationWarningMeters:      1000,
+		LocationRevealDays:         7,
+		TrashRetentionDays:         30,
+		ChangeNoticeQuietMinutes:   15,
+		ChangeNoticeMaxWaitMinutes: 60,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(c *config.Config)
+		settings []string
+	}{
+		{"valid", func(c *config.Config) {}, nil},
+		{
+			"placeholder and malformed auth", func(c *config.Config) {
+				c.Auth0Domain = "https://tenant.us.auth0.com/"
+				c.Auth0ClientSecret = "dev-client-secret"
+			},
+			[]string{"AUTH0_DOMAIN", "AUTH0_CLIENT_SECRET"},
+		},
+		{
+			"local auth outside dev mode", func(c *config.Config) { c.AuthMode = config.AuthModeLocal },
+			[]string{"AUTH_MODE"},
+		},
+		{"local auth in dev mode", func(c *config.Config) { c.AuthMode, c.DevMode = config.AuthModeLocal, true }, nil},
+		{
+			"every problem reported at once", func(c *config.Config) {
+				c.MailKey = ""
+				c.GeocoderProvider = "google"
+				c.DBPort = "postgres"
+				c.Timezone = "Mountain"
+			},
+			[]string{"TIMEZONE", "PGPORT", "MAIL_KEY", "GOOGLE_MAPS_API_KEY"},
+		},
+		{
+			"partial recaptcha", func(c *config.Config) { c.RecaptchaKey = "key" },
+			[]string{"RECAPTCHA_PROJECT", "RECAPTCHA_ACTION"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				c := validConfig()
+				tt.change(c)
+
+				err := c.Validate()
+				if tt.settings == nil {
+					assert.NoError(t, err)
+					return
+				}
+
+				var validationErr *config.ValidationError
+				require.True(t, errors.As(err, &validationErr), "expected a validation error, got %v", err)
+				var settings []string
+				for _, p := range validationErr.Problems {
+					settings = append(settings, p.Setting)
+				}
+				assert.Equal(t, tt.settings, settings)
+			},
+		)
+	}
+}
+
+func TestIntegrations(t *testing.T) {
+	c := validConfig()
+	configured := map[string]bool{}
+	for _, i := range c.Integrations() {
+		configured[i.Name] = i.Configured
+	}
+
+	assert.True(t, configured[config.IntegrationAuth])
+	assert.True(t, configured[config.IntegrationMail])
+	assert.False(t, configured[config.IntegrationSMS], "the placeholder key leaves texts unconfigured")
+	assert.False(t, configured[config.IntegrationRecaptcha])
+}
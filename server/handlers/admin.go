@@ -4,12 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"serve/config"
 	"serve/middleware"
 	"serve/models"
 	"serve/services"
@@ -20,7 +23,11 @@ import (
 // AdminHandler handles admin-related requests
 type AdminHandler struct {
 	DB           *sql.DB
+	Config       *config.Config
 	EmailService *services.EmailService
+	TextService  *services.TextService
+	Locations    *services.LocationValidator
+	stats        *statsCache
 }
 
 type Lead struct {
@@ -32,40 +39,130 @@ type Lead struct {
 
 // ProjectInput represents the input for creating or updating a project
 type ProjectInput struct {
-	GoogleID        *int    `json:"google_id"`
-	Title           string  `json:"title"`
-	Description     string  `json:"description"`
-	Time            string  `json:"time"`
-	ProjectDate     string  `json:"project_date"`
-	MaxCapacity     int     `json:"max_capacity"`
-	ServeLeadID     string  `json:"serve_lead_id"`
-	Types           []int   `json:"types,omitempty"`
-	Ages            string  `json:"ages,omitempty"`
-	Area            string  `json:"area"`
-	LocationAddress string  `json:"location_address"`
-	Latitude        float64 `json:"latitude"`
-	Longitude       float64 `json:"longitude"`
-	ServeLeadName   string  `json:"serve_lead_name"`
-	ServeLeadEmail  string  `json:"serve_lead_email"`
-	Leads           []Lead  `json:"leads"`
+	GoogleID        *int        `json:"google_id"`
+	OrganizationID  OptionalInt `json:"organization_id"`
+	Title           string      `json:"title"`
+	Description     string      `json:"description"`
+	Time            string      `json:"time"`
+	ProjectDate     string      `json:"project_date"`
+	MaxCapacity     int         `json:"max_capacity"`
+	ServeLeadID     string      `json:"serve_lead_id"`
+	Types           []int       `json:"types,omitempty"`
+	Ages            string      `json:"ages,omitempty"`
+	Area            string      `json:"area"`
+	LocationAddress string      `json:"location_address"`
+	Latitude        float64     `json:"latitude"`
+	Longitude       float64     `json:"longitude"`
+	ServeLeadName   string      `json:"serve_lead_name"`
+	ServeLeadEmail  string      `json:"serve_lead_email"`
+	Leads           []Lead      `json:"leads"`
+	// The exact location of a project at a private home, hidden until LocationRevealAt
+	ExactAddress     string     `json:"exact_address"`
+	ExactLatitude    float64    `json:"exact_latitude"`
+	ExactLongitude   float64    `json:"exact_longitude"`
+	LocationRevealAt *time.Time `json:"location_reveal_at"`
+	// EarlyAccess reserves the project for group leaders and the groups they invite
+	EarlyAccess bool `json:"early_access"`
+	// CloseHours override the event's registration and cancellation deadlines for this project
+	models.CloseHours
 }
 
 // RegisterAdminRoutes registers the routes for admin handlers
-func RegisterAdminRoutes(router *mux.Router, db *sql.DB, emailService *services.EmailService) {
+func RegisterAdminRoutes(
+	router *mux.Router, db *sql.DB, cfg *config.Config, emailService *services.EmailService,
+	textService *services.TextService, locations *services.LocationValidator,
+) {
 	handler := &AdminHandler{
 		DB:           db,
+		Config:       cfg,
 		EmailService: emailService,
-	}
-
-	router.HandleFunc("/users", handler.GetAllUsers).Methods(http.MethodGet)
-	router.HandleFunc("/registrations", handler.GetAllRegistrations).Methods(http.MethodGet)
-	router.HandleFunc("/projects", handler.CreateProject).Methods(http.MethodPost)
-	router.HandleFunc("/projects/{id:[0-9]+}", handler.UpdateProject).Methods(http.MethodPut)
-	router.HandleFunc("/projects/{id:[0-9]+}", handler.DeleteProject).Methods(http.MethodDelete)
-	router.HandleFunc("/registrations/{id:[0-9]+}", handler.UpdateRegistrationGuestCount).Methods(http.MethodPut)
-	router.HandleFunc("/registrations/{id:[0-9]+}", handler.DeleteRegistration).Methods(http.MethodDelete)
-	router.HandleFunc("/projects/{id:[0-9]+}/{status}", handler.UpdateProjectActiveStatus).Methods(http.MethodPut)
-	router.HandleFunc("/send-thank-you-emails", handler.SendThankYouEmails).Methods(http.MethodPost)
+		TextService:  textService,
+		Locations:    locations,
+		stats:        &statsCache{},
+	}
+
+	// each route declares the permission it needs; AccessMiddleware has already loaded the user's access
+	reports := func(next http.HandlerFunc) http.HandlerFunc {
+		return middleware.RequirePermission(middleware.PermReadReports, next)
+	}
+	projects := func(next http.HandlerFunc) http.HandlerFunc {
+		return middleware.RequirePermission(middleware.PermManageProjects, next)
+	}
+	users := func(next http.HandlerFunc) http.HandlerFunc {
+		return middleware.RequirePermission(middleware.PermManageUsers, next)
+	}
+	roles := func(next http.HandlerFunc) http.HandlerFunc {
+		return middleware.RequirePermission(middleware.PermManageRoles, next)
+	}
+	broadcasts := func(next http.HandlerFunc) http.HandlerFunc {
+		return middleware.RequirePermission(middleware.PermSendBroadcasts, next)
+	}
+	projectReports := func(next http.HandlerFunc) http.HandlerFunc {
+		return middleware.RequireProjectPermission(middleware.PermReadReports, next)
+	}
+	projectManagers := func(next http.HandlerFunc) http.HandlerFunc {
+		return middleware.RequireProjectPermission(middleware.PermManageProjects, next)
+	}
+
+	router.HandleFunc("/access", handler.GetAccess).Methods(http.MethodGet)
+	router.HandleFunc("/roles", roles(handler.GetRoles)).Methods(http.MethodGet)
+	router.HandleFunc("/roles/users", roles(handler.GetUserRoles)).Methods(http.MethodGet)
+	router.HandleFunc("/roles/users", roles(handler.AssignRole)).Methods(http.MethodPost)
+	router.HandleFunc("/roles/users/{id:[0-9]+}", roles(handler.RemoveUserRole)).Methods(http.MethodDelete)
+	router.HandleFunc("/stats", reports(handler.GetStats)).Methods(http.MethodGet)
+	router.HandleFunc("/locations/validate", projects(handler.ValidateLocation)).Methods(http.MethodPost)
+	router.HandleFunc("/audit", reports(handler.GetAuditLog)).Methods(http.MethodGet)
+	router.HandleFunc("/submissions", reports(handler.GetSubmissions)).Methods(http.MethodGet)
+	router.HandleFunc("/organizations", reports(handler.GetOrganizations)).Methods(http.MethodGet)
+	router.HandleFunc("/organizations", projects(handler.CreateOrganization)).Methods(http.MethodPost)
+	router.HandleFunc("/organizations/{id:[0-9]+}", reports(handler.GetOrganization)).Methods(http.MethodGet)
+	router.HandleFunc("/organizations/{id:[0-9]+}", projects(handler.UpdateOrganization)).Methods(http.MethodPut)
+	router.HandleFunc("/organizations/{id:[0-9]+}", projects(handler.DeleteOrganization)).Methods(http.MethodDelete)
+	router.HandleFunc("/organizations/{id:[0-9]+}/roster", reports(handler.GetOrganizationRoster)).
+		Methods(http.MethodGet)
+	router.HandleFunc("/organizations/{id:[0-9]+}/history", reports(handler.GetOrganizationHistory)).
+		Methods(http.MethodGet)
+	router.HandleFunc("/templates", reports(handler.GetTemplates)).Methods(http.MethodGet)
+	router.HandleFunc("/templates", projects(handler.CreateTemplate)).Methods(http.MethodPost)
+	router.HandleFunc("/templates/{id:[0-9]+}", reports(handler.GetTemplate)).Methods(http.MethodGet)
+	router.HandleFunc("/templates/{id:[0-9]+}", projects(handler.UpdateTemplate)).Methods(http.MethodPut)
+	router.HandleFunc("/templates/{id:[0-9]+}", projects(handler.DeleteTemplate)).Methods(http.MethodDelete)
+	router.HandleFunc("/templates/{id:[0-9]+}/projects", projects(handler.CreateProjectFromTemplate)).
+		Methods(http.MethodPost)
+	router.HandleFunc("/trash", projects(handler.GetTrash)).Methods(http.MethodGet)
+	router.HandleFunc("/users", reports(handler.GetAllUsers)).Methods(http.MethodGet)
+	router.HandleFunc("/users/{id}", users(handler.DeleteUser)).Methods(http.MethodDelete)
+	router.HandleFunc("/users/{id}/restore", users(handler.RestoreUser)).Methods(http.MethodPost)
+	router.HandleFunc("/registrations", reports(handler.GetAllRegistrations)).Methods(http.MethodGet)
+	router.HandleFunc("/projects", projects(handler.CreateProject)).Methods(http.MethodPost)
+	router.HandleFunc("/projects/clone", projects(handler.CloneProjects)).Methods(http.MethodPost)
+	router.HandleFunc("/registration-windows", reports(handler.GetRegistrationWindows)).Methods(http.MethodGet)
+	router.HandleFunc("/registration-windows", projects(handler.SaveRegistrationWindow)).Methods(http.MethodPut)
+	router.HandleFunc("/registration-windows/{id:[0-9]+}", projects(handler.DeleteRegistrationWindow)).
+		Methods(http.MethodDelete)
+	router.HandleFunc("/lead-pipeline", reports(handler.GetLeadPipeline)).Methods(http.MethodGet)
+	router.HandleFunc("/lead-invitations", reports(handler.GetLeadInvitations)).Methods(http.MethodGet)
+	router.HandleFunc("/lead-invitations", projects(handler.CreateLeadInvitation)).Methods(http.MethodPost)
+	router.HandleFunc("/lead-invitations/{id:[0-9]+}", projects(handler.WithdrawLeadInvitation)).
+		Methods(http.MethodDelete)
+	router.HandleFunc("/event-deadlines", reports(handler.GetEventDeadlines)).Methods(http.MethodGet)
+	router.HandleFunc("/event-deadlines", projects(handler.SaveEventDeadlines)).Methods(http.MethodPut)
+	router.HandleFunc("/event-deadlines/{date}", projects(handler.DeleteEventDeadlines)).Methods(http.MethodDelete)
+	router.HandleFunc("/projects/{id:[0-9]+}/template", projects(handler.CreateTemplateFromProject)).
+		Methods(http.MethodPost)
+	router.HandleFunc("/projects/{id:[0-9]+}", projectManagers(handler.UpdateProject)).Methods(http.MethodPut)
+	router.HandleFunc("/projects/{id:[0-9]+}", projects(handler.DeleteProject)).Methods(http.MethodDelete)
+	router.HandleFunc("/projects/{id:[0-9]+}/restore", projects(handler.RestoreProject)).Methods(http.MethodPost)
+	router.HandleFunc("/projects/{id:[0-9]+}/carpools", projectReports(handler.GetProjectCarpools)).
+		Methods(http.MethodGet)
+	router.HandleFunc("/registrations/{id:[0-9]+}", projects(handler.UpdateRegistrationGuestCount)).
+		Methods(http.MethodPut)
+	router.HandleFunc("/registrations/{id:[0-9]+}", projects(handler.DeleteRegistration)).Methods(http.MethodDelete)
+	router.HandleFunc("/registrations/{id:[0-9]+}/restore", projects(handler.RestoreRegistration)).
+		Methods(http.MethodPost)
+	router.HandleFunc("/projects/{id:[0-9]+}/{status}", projectManagers(handler.UpdateProjectActiveStatus)).
+		Methods(http.MethodPut)
+	router.HandleFunc("/send-thank-you-emails", broadcasts(handler.SendThankYouEmails)).Methods(http.MethodPost)
 }
 
 // GetAllRegistrations returns all registrations across all projects
@@ -78,6 +175,7 @@ func (h *AdminHandler) GetAllRegistrations(w http.ResponseWriter, r *http.Reques
 		FROM registrations r
 		JOIN users u ON r.user_id = u.id
 		JOIN projects p ON r.project_id = p.id
+		WHERE r.deleted_at IS NULL AND u.deleted_at IS NULL AND p.deleted_at IS NULL
 		ORDER BY r.created_at DESC
 	`
 
@@ -138,7 +236,22 @@ func (h *AdminHandler) UpdateRegistrationGuestCount(w http.ResponseWriter, r *ht
 		return
 	}
 
-	query := `UPDATE registrations SET guest_count = $1 WHERE id = $2`
+	before, err := models.GetRegistrationByID(r.Context(), h.DB, regID)
+	if err != nil {
+		log.Println("failed to retrieve registration for updating guest count: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve registration")
+		return
+	}
+
+	if before == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Registration not found")
+		return
+	}
+
+	query := `
+		UPDATE registrations SET guest_count = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND deleted_at IS NULL
+	`
 	result, err := h.DB.Exec(query, input.GuestCount, regID)
 	if err != nil {
 		log.Println("failed to update guest count: ", err)
@@ -153,6 +266,12 @@ func (h *AdminHandler) UpdateRegistrationGuestCount(w http.ResponseWriter, r *ht
 		return
 	}
 
+	after := *before
+	after.GuestCount = input.GuestCount
+	recordAudit(
+		r, h.DB, auditActor(r, ""), models.AuditActionUpdate, models.AuditEntityRegistration, regID, before, after,
+	)
+
 	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Registration updated successfully"})
 }
 
@@ -165,19 +284,30 @@ func (h *AdminHandler) DeleteRegistration(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	query := `DELETE FROM registrations WHERE id = $1`
-	result, err := h.DB.Exec(query, regID)
+	before, err := models.GetRegistrationByID(r.Context(), h.DB, regID)
 	if err != nil {
-		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to delete registration")
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve registration")
 		return
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil || rowsAffected == 0 {
+	if before == nil {
 		middleware.RespondWithError(w, http.StatusNotFound, "Registration not found")
 		return
 	}
 
+	if err = models.DeleteRegistration(r.Context(), h.DB, regID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			middleware.RespondWithError(w, http.StatusNotFound, "Registration not found")
+			return
+		}
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to delete registration")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, auditActor(r, ""), models.AuditActionDelete, models.AuditEntityRegistration, regID, before, nil,
+	)
+
 	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Registration deleted successfully"})
 }
 
@@ -210,6 +340,13 @@ func (h *AdminHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 		)
 		return
 	}
+	if err := input.CloseHours.Validate(); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !h.organizationExists(w, r, input.OrganizationID.Value) {
+		return
+	}
 
 	// hard code
 	if input.ProjectDate == "" { // default to serve day
@@ -220,11 +357,7 @@ func (h *AdminHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 		input.ServeLeadID = "example-user-123"
 	}
 
-	projectDate, err := time.Parse(time.RFC3339, input.ProjectDate)
-	if projectDate.Hour() < 8 {
-		projectDate = projectDate.Add(time.Hour * 8)
-	}
-
+	projectDate, err := parseProjectDate(input.ProjectDate)
 	if err != nil {
 		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid project date format")
 		return
@@ -243,16 +376,68 @@ func (h *AdminHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 		Longitude:       input.Longitude,
 		ServeLeadID:     input.ServeLeadID,
 		Ages:            input.Ages,
+		OrganizationID:  input.OrganizationID.Value,
+
+		ExactAddress:     input.ExactAddress,
+		ExactLatitude:    input.ExactLatitude,
+		ExactLongitude:   input.ExactLongitude,
+		LocationRevealAt: input.LocationRevealAt,
+		EarlyAccess:      input.EarlyAccess,
+		CloseHours:       input.CloseHours,
 	}
 
 	project = applyAccessories(input, project)
+	check := h.checkLocation(r, project)
 
 	if err = models.CreateProject(ctx, h.DB, project); err != nil {
 		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to create project")
 		return
 	}
 
-	middleware.RespondWithJSON(w, http.StatusCreated, project)
+	recordAudit(
+		r, h.DB, auditActor(r, ""), models.AuditActionCreate, models.AuditEntityProject, project.ID, nil, project,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusCreated, checkedProject{project, check})
+}
+
+// OptionalInt is an integer field of a request that may be left out, so updates only change it when sent
+type OptionalInt struct {
+	Set   bool
+	Value *int
+}
+
+// UnmarshalJSON records that the field was sent, as a number or null
+func (o *OptionalInt) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Value = nil
+		return nil
+	}
+	var v int
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	o.Value = &v
+	return nil
+}
+
+// organizationExists checks that a project's organization exists, responding with a bad request if not
+func (h *AdminHandler) organizationExists(w http.ResponseWriter, r *http.Request, id *int) bool {
+	if id == nil {
+		return true
+	}
+	org, err := models.GetOrganizationByID(r.Context(), h.DB, *id)
+	if err != nil {
+		log.Println("error retrieving project organization: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve organization")
+		return false
+	}
+	if org == nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Organization not found")
+		return false
+	}
+	return true
 }
 
 // UpdateProject updates an existing project
@@ -295,17 +480,23 @@ func (h *AdminHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 		)
 		return
 	}
-
-	projectDate, err := time.Parse(time.RFC3339, input.ProjectDate)
-	if projectDate.Hour() < 8 {
-		projectDate = projectDate.Add(time.Hour * 8)
+	if err := input.CloseHours.Validate(); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !h.organizationExists(w, r, input.OrganizationID.Value) {
+		return
 	}
+
+	projectDate, err := parseProjectDate(input.ProjectDate)
 	if err != nil {
 		log.Println("could not parse date")
 		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid project date format (use YYYY-MM-DD)")
 		return
 	}
 
+	before := *project
+
 	// Update project
 	project.GoogleID = input.GoogleID
 	project.Title = input.Title
@@ -318,6 +509,15 @@ func (h *AdminHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 	project.Latitude = input.Latitude
 	project.Longitude = input.Longitude
 	project.Ages = input.Ages
+	if input.OrganizationID.Set {
+		project.OrganizationID = input.OrganizationID.Value
+	}
+	project.ExactAddress = input.ExactAddress
+	project.ExactLatitude = input.ExactLatitude
+	project.ExactLongitude = input.ExactLongitude
+	project.LocationRevealAt = input.LocationRevealAt
+	project.EarlyAccess = input.EarlyAccess
+	project.CloseHours = input.CloseHours
 
 	if len(input.Types) > 0 {
 		var typeList []models.ProjectAccessory
@@ -338,6 +538,7 @@ func (h *AdminHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	project.Leads = leads
+	check := h.checkLocation(r, project)
 
 	if err = models.UpdateProject(ctx, h.DB, project); err != nil {
 		log.Println("internal server error updating project")
@@ -345,7 +546,17 @@ func (h *AdminHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	middleware.RespondWithJSON(w, http.StatusOK, project)
+	recordAudit(r, h.DB, auditActor(r, ""), models.AuditActionUpdate, models.AuditEntityProject, id, before, project)
+
+	// Registrants hear about material changes once the edits settle; notify=false skips this for fixes
+	// they do not need to know about
+	if changes := models.DiffProjects(&before, project); len(changes) > 0 && r.URL.Query().Get("notify") != "false" {
+		if err = models.QueueProjectChanges(ctx, h.DB, id, changes); err != nil {
+			log.Println("error queueing project change notice: ", err)
+		}
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, checkedProject{project, check})
 }
 
 // DeleteProject deletes a project
@@ -374,10 +585,16 @@ func (h *AdminHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
 
 	// Delete project
 	if err := models.DeleteProject(ctx, h.DB, id); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			middleware.RespondWithError(w, http.StatusNotFound, "Project not found")
+			return
+		}
 		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to delete project")
 		return
 	}
 
+	recordAudit(r, h.DB, auditActor(r, ""), models.AuditActionDelete, models.AuditEntityProject, id, project, nil)
+
 	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Project deleted successfully"})
 }
 
@@ -392,11 +609,23 @@ func (h *AdminHandler) UpdateProjectActiveStatus(w http.ResponseWriter, r *http.
 	}
 
 	status := vars["status"]
-	if status != "open" && status != "in_review" {
-		middleware.RespondWithError(w, http.StatusBadRequest, "Status must be either 'open' or 'in_review'")
+	if !models.IsValidStatus(status) {
+		middleware.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid project status '%s'", status))
 		return
 	}
 
+	// The reason is optional in general but required for rejections and cancellations
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+			middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+	}
+	reason := strings.TrimSpace(input.Reason)
+
 	// Check if project exists
 	project, err := models.GetProjectByID(ctx, h.DB, id)
 	if err != nil {
@@ -409,13 +638,42 @@ func (h *AdminHandler) UpdateProjectActiveStatus(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// Update the active status
-	if err := models.UpdateProjectActiveStatus(ctx, h.DB, id, status); err != nil {
-		log.Printf("Error updating project active status: %v", err)
-		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to update project status")
+	// Update the status, enforcing the allowed transitions
+	released, err := models.UpdateProjectStatus(ctx, h.DB, id, project.Status, status, reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidTransition):
+			middleware.RespondWithError(
+				w, http.StatusConflict, fmt.Sprintf(
+					"Project cannot move from '%s' to '%s'; allowed: %s", project.Status, status,
+					strings.Join(models.AllowedTransitions(project.Status), ", "),
+				),
+			)
+		case errors.Is(err, models.ErrReasonRequired):
+			middleware.RespondWithError(
+				w, http.StatusBadRequest, fmt.Sprintf("A reason is required to move to '%s'", status),
+			)
+		case errors.Is(err, models.ErrNotFound):
+			middleware.RespondWithError(w, http.StatusConflict, "Project was changed or deleted, please reload")
+		default:
+			log.Printf("Error updating project status: %v", err)
+			middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to update project status")
+		}
 		return
 	}
 
+	recordAudit(
+		r, h.DB, auditActor(r, ""), models.AuditActionStatusChange, models.AuditEntityProject, id,
+		map[string]string{"status": project.Status, "reason": project.StatusReason},
+		map[string]any{"status": status, "reason": reason, "released_registrations": len(released)},
+	)
+
+	// Let everyone who was signed up know the project will not take place
+	if len(released) > 0 {
+		go h.EmailService.SendProjectCancelled(project, released, reason)
+		go h.TextService.SendProjectCancelledText(project, released)
+	}
+
 	middleware.RespondWithJSON(
 		w, http.StatusOK, map[string]string{
 			"message": fmt.Sprintf("Project status updated to %s successfully", status),
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"serve/middleware"
+	"serve/models"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// auditActor identifies who performed a request: the Auth0 subject when the request carries a
+// token, otherwise the email the anonymous volunteer supplied
+func auditActor(r *http.Request, email string) string {
+	if sub, err := middleware.GetUserIDFromRequest(r); err == nil && sub != "" {
+		return sub
+	}
+	if email != "" {
+		return "anonymous:" + email
+	}
+	return "anonymous"
+}
+
+// recordAudit appends an entry to the audit log for a completed mutation. Failures are logged
+// rather than returned so they never undo a mutation that already succeeded.
+func recordAudit(r *http.Request, db *sql.DB, actor, action, entityType string, entityID, before, after any) {
+	entry, err := models.NewAuditEntry(actor, action, entityType, entityID, before, after)
+	if err != nil {
+		log.Printf("error building audit entry for %s %s %v: %v", action, entityType, entityID, err)
+		return
+	}
+	entry.IP = middleware.GetClientIP(r)
+
+	if err = models.CreateAuditEntry(r.Context(), db, entry); err != nil {
+		log.Printf("error recording audit entry for %s %s %v: %v", action, entityType, entityID, err)
+	}
+}
+
+// GetAuditLog returns audit log entries, newest first. Supports filtering by actor, action,
+// entity_type, entity_id and an RFC3339 from/to range, paginated with limit and offset.
+func (h *AdminHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := r.URL.Query()
+
+	filter := models.AuditFilter{
+		Actor:      params.Get("actor"),
+		Action:     params.Get("action"),
+		EntityType: params.Get("entity_type"),
+		EntityID:   params.Get("entity_id"),
+		Limit:      defaultAuditLimit,
+	}
+
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			middleware.RespondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		filter.Limit = min(limit, maxAuditLimit)
+	}
+
+	if v := params.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			middleware.RespondWithError(w, http.StatusBadRequest, "Invalid offset")
+			return
+		}
+		filter.Offset = offset
+	}
+
+	for key, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		v := params.Get(key)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			middleware.RespondWithError(w, http.StatusBadRequest, "Invalid "+key+" date format (use RFC3339)")
+			return
+		}
+		*target = &t
+	}
+
+	entries, total, err := models.GetAuditEntries(ctx, h.DB, filter)
+	if err != nil {
+		log.Println("error retrieving audit log: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve audit log")
+		return
+	}
+
+	middleware.RespondWithJSON(
+		w, http.StatusOK, map[string]any{
+			"entries": entries,
+			"total":   total,
+			"limit":   filter.Limit,
+			"offset":  filter.Offset,
+		},
+	)
+}
@@ -3,11 +3,14 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"serve/config"
+	"serve/middleware"
 )
 
 // AuthHandler handles authentication-related requests
@@ -70,3 +73,56 @@ func (h *AuthHandler) GetAuthConfig(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(c)
 }
+
+// LocalAuthHandler hands out tokens from the local issuer, for development without Auth0
+type LocalAuthHandler struct {
+	Issuer *middleware.LocalIssuer
+}
+
+// TokenRequest asks the local issuer for a token
+type TokenRequest struct {
+	Subject       string   `json:"subject"`
+	Permissions   []string `json:"permissions"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	FirstName     string   `json:"first_name"`
+	LastName      string   `json:"last_name"`
+}
+
+// RegisterLocalAuthRoutes registers the route that mints development tokens. It must only be mounted
+// when the server is running with local auth.
+func RegisterLocalAuthRoutes(router *mux.Router, issuer *middleware.LocalIssuer) {
+	handler := &LocalAuthHandler{
+		Issuer: issuer,
+	}
+
+	router.HandleFunc("/token", handler.MintToken).Methods(http.MethodPost)
+}
+
+// MintToken signs a token for any subject with any permissions. Tokens last a day.
+func (h *LocalAuthHandler) MintToken(w http.ResponseWriter, r *http.Request) {
+	var input TokenRequest
+	if err := middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if strings.TrimSpace(input.Subject) == "" {
+		input.Subject = "local|developer"
+	}
+
+	claims := middleware.CustomClaims{
+		Permissions:   input.Permissions,
+		Email:         input.Email,
+		EmailVerified: input.EmailVerified,
+		GivenName:     input.FirstName,
+		FamilyName:    input.LastName,
+	}
+	token, err := h.Issuer.Mint(input.Subject, claims, 24*time.Hour)
+	if err != nil {
+		log.Println("error minting token: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to mint token")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"access_token": token, "token_type": "Bearer"})
+}
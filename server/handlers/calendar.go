@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"serve/config"
+	"serve/middleware"
+	"serve/models"
+	"serve/services"
+)
+
+// CalendarHandler serves volunteers' subscribable calendar feeds
+type CalendarHandler struct {
+	DB     *sql.DB
+	Config *config.Config
+}
+
+// RegisterCalendarRoutes registers the routes for calendar feeds. Feeds are public; the token in the
+// URL is the only thing identifying the volunteer.
+func RegisterCalendarRoutes(router *mux.Router, db *sql.DB, cfg *config.Config) {
+	handler := &CalendarHandler{
+		DB:     db,
+		Config: cfg,
+	}
+
+	router.HandleFunc("/{token:[0-9a-f]+}.ics", handler.GetFeed).Methods(http.MethodGet)
+}
+
+// GetFeed returns the iCalendar feed of the shifts a volunteer is registered for. Calendar apps poll
+// it, so changes to a project show up without the volunteer doing anything.
+func (h *CalendarHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	user, err := models.GetUserByCalendarToken(ctx, h.DB, mux.Vars(r)["token"])
+	if err != nil {
+		log.Println("error retrieving calendar user: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve calendar")
+		return
+	}
+	if user == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Calendar not found")
+		return
+	}
+
+	loc, err := h.Config.Location()
+	if err != nil {
+		log.Println("error loading calendar timezone: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to build calendar")
+		return
+	}
+
+	projects, err := models.GetUserRegisteredProjects(ctx, h.DB, user.ID)
+	if err != nil {
+		log.Println("error retrieving calendar projects: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve calendar")
+		return
+	}
+
+	// the feed is the volunteer's own, so private addresses appear once they are revealed
+	now := time.Now()
+	events := make([]services.CalendarEvent, 0, len(projects))
+	for _, project := range projects {
+		visible := project.ForVolunteers(now, h.Config.LocationRevealDays)
+		events = append(events, services.NewCalendarEvent(visible, loc))
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write([]byte(services.BuildCalendar("Serve Day", events, time.Now()))); err != nil {
+		log.Println("error writing calendar: ", err)
+	}
+}
+
+// GetCalendarFeed returns the URL of the signed-in volunteer's calendar feed, creating the feed on first use
+func (h *UserHandler) GetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromRequest(r)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusUnauthorized, "Failed to get user information")
+		return
+	}
+
+	feedURL, err := calendarFeedURL(r.Context(), h.DB, h.Config, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			middleware.RespondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		log.Println("error creating calendar feed: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to create calendar feed")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"url": feedURL})
+}
+
+// ResetCalendarFeed replaces the signed-in volunteer's calendar feed URL, for when the old one has been shared
+func (h *UserHandler) ResetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromRequest(r)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusUnauthorized, "Failed to get user information")
+		return
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		log.Println("error generating calendar token: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to reset calendar feed")
+		return
+	}
+	if err = models.ResetCalendarToken(r.Context(), h.DB, userID, token); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			middleware.RespondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		log.Println("error resetting calendar token: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to reset calendar feed")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"url": calendarFeedPath(h.Config, token)})
+}
+
+// calendarFeedURL returns the URL of the user's calendar feed, giving them a feed token if they have none
+func calendarFeedURL(ctx context.Context, db *sql.DB, cfg *config.Config, userID string) (string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+
+	token, err = models.EnsureCalendarToken(ctx, db, userID, token)
+	if err != nil {
+		return "", err
+	}
+
+	return calendarFeedPath(cfg, token), nil
+}
+
+// calendarFeedPath builds the feed URL for a token
+func calendarFeedPath(cfg *config.Config, token string) string {
+	return cfg.APIURL + "/api/calendar/" + token + ".ics"
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"serve/middleware"
+	"serve/models"
+)
+
+// CarpoolInput is a registration's offer of, or request for, a ride. Riders that leave seats unset
+// need one for themselves and each of their guests.
+type CarpoolInput struct {
+	Role          string `json:"role"`
+	Seats         int    `json:"seats"`
+	DepartureArea string `json:"departure_area"`
+}
+
+// carpool builds the carpool for a registration from the input
+func (in CarpoolInput) carpool(registration models.Registration) *models.Carpool {
+	role := strings.ToLower(strings.TrimSpace(in.Role))
+	seats := in.Seats
+	if role == models.CarpoolRider && seats <= 0 {
+		seats = 1 + registration.GuestCount
+	}
+	return &models.Carpool{
+		RegistrationID: registration.ID,
+		Role:           role,
+		Seats:          seats,
+		DepartureArea:  in.DepartureArea,
+	}
+}
+
+// SaveCarpool offers or requests a ride for the signed-in volunteer's registration on the project, then
+// matches riders with drivers. Each newly matched driver and rider is emailed the other's contact, which
+// is left out of the response.
+func (h *ProjectHandler) SaveCarpool(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	projectID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	var input CarpoolInput
+	if err = middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	registration, ok := h.lookupProjectRegistration(w, r, projectID)
+	if !ok {
+		return
+	}
+
+	carpool := input.carpool(*registration)
+	if err = models.SaveCarpool(ctx, h.DB, carpool); err != nil {
+		if errors.Is(err, models.ErrInvalidCarpool) {
+			middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Println("error saving carpool: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to save carpool")
+		return
+	}
+
+	h.matchCarpools(ctx, projectID)
+
+	matches, err := models.GetCarpoolMatches(ctx, h.DB, projectID)
+	if err != nil {
+		log.Println("error getting carpool matches: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve carpool matches")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]any{
+		"carpool": carpool,
+		"matches": registrationMatches(matches, registration.ID),
+	})
+}
+
+// DeleteCarpool withdraws the signed-in volunteer's carpool on the project. Riders left without a driver are
+// matched again where possible.
+func (h *ProjectHandler) DeleteCarpool(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	projectID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	registration, ok := h.lookupProjectRegistration(w, r, projectID)
+	if !ok {
+		return
+	}
+
+	if err = models.DeleteCarpool(ctx, h.DB, registration.ID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			middleware.RespondWithError(w, http.StatusNotFound, "No carpool found for this registration")
+			return
+		}
+		log.Println("error deleting carpool: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to delete carpool")
+		return
+	}
+
+	h.matchCarpools(ctx, projectID)
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Carpool withdrawn successfully"})
+}
+
+// GetProjectCarpools returns a project's ride offers and requests and the matches between them
+func (h *AdminHandler) GetProjectCarpools(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	projectID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	carpools, err := models.GetProjectCarpools(ctx, h.DB, projectID)
+	if err != nil {
+		log.Println("error getting carpools: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve carpools")
+		return
+	}
+
+	matches, err := models.GetCarpoolMatches(ctx, h.DB, projectID)
+	if err != nil {
+		log.Println("error getting carpool matches: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve carpool matches")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]any{"carpools": carpools, "matches": matches})
+}
+
+// matchCarpools runs carpool matching for a project and emails the new matches. A failure is logged
+// and does not fail the request, as matching runs again on the next change.
+func (h *ProjectHandler) matchCarpools(ctx context.Context, projectID int) {
+	matches, err := models.MatchCarpools(ctx, h.DB, projectID)
+	if err != nil {
+		log.Println("error matching carpools: ", err)
+		return
+	}
+	if len(matches) == 0 {
+		return
+	}
+
+	project, err := models.GetProjectByID(ctx, h.DB, projectID)
+	if err != nil || project == nil {
+		log.Println("error getting project for carpool emails: ", err)
+		return
+	}
+	for _, match := range matches {
+		go h.EmailService.SendCarpoolMatch(project, match)
+	}
+}
+
+// lookupProjectRegistration finds the signed-in volunteer's active registration on the project, responding
+// with an error if there is none
+func (h *ProjectHandler) lookupProjectRegistration(
+	w http.ResponseWriter, r *http.Request, projectID int,
+) (*models.Registration, bool) {
+	subject, err := middleware.GetUserIDFromRequest(r)
+	if err != nil || subject == "" {
+		middleware.RespondWithError(w, http.StatusUnauthorized, "Failed to get user information")
+		return nil, false
+	}
+
+	registration, err := models.GetUserRegistration(r.Context(), h.DB, subject)
+	if err != nil {
+		log.Println("error getting registration: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve registration")
+		return nil, false
+	}
+	if registration.ID == 0 || registration.ProjectID != projectID || registration.Status != "registered" {
+		middleware.RespondWithError(w, http.StatusNotFound, "No registration found for this project")
+		return nil, false
+	}
+
+	return &registration, true
+}
+
+// registrationMatches picks out the matches a registration is part of, without either side's contact;
+// the match email introduces them
+func registrationMatches(matches []models.CarpoolMatch, registrationID int) []models.CarpoolMatch {
+	mine := []models.CarpoolMatch{}
+	for _, m := range matches {
+		if m.Driver.RegistrationID == registrationID || m.Rider.RegistrationID == registrationID {
+			m.Driver.User, m.Rider.User = nil, nil
+			mine = append(mine, m)
+		}
+	}
+	return mine
+}
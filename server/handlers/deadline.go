@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"serve/config"
+	"serve/middleware"
+	"serve/models"
+)
+
+// GetEventDeadlines returns the close hours of every event on or after the from date, today by default
+func (h *AdminHandler) GetEventDeadlines(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	if from == "" {
+		from = time.Now().Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", from); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "from must be a date like 2025-07-12")
+		return
+	}
+
+	deadlines, err := models.GetAllEventDeadlines(r.Context(), h.DB, from)
+	if err != nil {
+		log.Println("error retrieving event deadlines: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve event deadlines")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, deadlines)
+}
+
+// SaveEventDeadlines sets how many hours before an event's projects registration and cancellation close
+func (h *AdminHandler) SaveEventDeadlines(w http.ResponseWriter, r *http.Request) {
+	var deadlines models.EventDeadlines
+	if err := middleware.ParseJSON(r, &deadlines); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	before, err := models.GetEventDeadlines(r.Context(), h.DB, deadlines.EventDate)
+	if err != nil {
+		log.Println("error retrieving event deadlines: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to save event deadlines")
+		return
+	}
+
+	if err := models.SaveEventDeadlines(r.Context(), h.DB, &deadlines); err != nil {
+		if errors.Is(err, models.ErrInvalidDeadlines) {
+			middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Println("error saving event deadlines: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to save event deadlines")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, auditActor(r, ""), models.AuditActionUpdate, models.AuditEntityEventDeadlines, deadlines.EventDate,
+		before, deadlines,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusOK, deadlines)
+}
+
+// DeleteEventDeadlines removes an event's close hours, so its projects fall back to the defaults
+func (h *AdminHandler) DeleteEventDeadlines(w http.ResponseWriter, r *http.Request) {
+	date := mux.Vars(r)["date"]
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Event date must look like 2025-07-12")
+		return
+	}
+
+	before, err := models.GetEventDeadlines(r.Context(), h.DB, date)
+	if err != nil {
+		log.Println("error retrieving event deadlines: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to delete event deadlines")
+		return
+	}
+
+	if err := models.DeleteEventDeadlines(r.Context(), h.DB, date); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			middleware.RespondWithError(w, http.StatusNotFound, "Event deadlines not found")
+			return
+		}
+		log.Println("error deleting event deadlines: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to delete event deadlines")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, auditActor(r, ""), models.AuditActionDelete, models.AuditEntityEventDeadlines, date, before, nil,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Event deadlines deleted"})
+}
+
+// projectDeadlines works out when registration and cancellation close for a project from its own close
+// hours, its event's and the server defaults
+func projectDeadlines(
+	ctx context.Context, db *sql.DB, cfg *config.Config, project *models.Project,
+) (models.Deadlines, error) {
+	loc, err := cfg.Location()
+	if err != nil {
+		return models.Deadlines{}, err
+	}
+
+	event, err := models.GetEventDeadlines(ctx, db, models.EventDate(project.ProjectDate, loc))
+	if err != nil {
+		return models.Deadlines{}, err
+	}
+
+	defaults := models.CloseHours{Registration: &cfg.RegistrationCloseHours, Cancellation: &cfg.CancellationCloseHours}
+	return models.ResolveDeadlines(project.ProjectDate, project.CloseHours, event.CloseHours, defaults), nil
+}
+
+// deadlineAllows checks a volunteer's change to their registration against one of the project's deadlines,
+// responding with when it passed if it has. Admins can still make changes after the deadlines.
+func deadlineAllows(
+	w http.ResponseWriter, r *http.Request, db *sql.DB, cfg *config.Config, project *models.Project,
+	check func(models.Deadlines, time.Time) error,
+) bool {
+	deadlines, err := projectDeadlines(r.Context(), db, cfg, project)
+	if err != nil {
+		log.Println("error retrieving project deadlines: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to check project deadlines")
+		return false
+	}
+
+	passed := check(deadlines, time.Now())
+	if passed == nil {
+		return true
+	}
+
+	admin, err := requestCan(r, db, middleware.PermManageProjects)
+	if err != nil {
+		log.Println("error checking permissions for project deadlines: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to check project deadlines")
+		return false
+	}
+	if admin {
+		return true
+	}
+
+	loc, err := cfg.Location()
+	if err != nil {
+		loc = time.UTC
+	}
+	middleware.RespondWithJSON(w, http.StatusForbidden, map[string]any{
+		"error":     deadlineMessage(passed, deadlines, loc),
+		"deadlines": deadlines,
+	})
+	return false
+}
+
+// deadlineMessage tells the volunteer which deadline passed and when
+func deadlineMessage(passed error, deadlines models.Deadlines, loc *time.Location) string {
+	if errors.Is(passed, models.ErrCancellationDeadline) {
+		return "Cancellations for this project closed " +
+			deadlines.CancellationClosesAt.In(loc).Format(friendlyTimeLayout) +
+			"; contact the project lead to change your registration"
+	}
+	return "Registration for this project closed " + deadlines.RegistrationClosesAt.In(loc).Format(friendlyTimeLayout)
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"serve/models"
+	"serve/services"
 )
 
 type Lead struct {
@@ -13,6 +14,13 @@ type Lead struct {
 	Active bool   `json:"active"`
 }
 
+// Organization is the public side of the organization hosting a project. Contacts and notes stay in the
+// admin app.
+type Organization struct {
+	Name    string `json:"name"`
+	Website string `json:"website"`
+}
+
 // ProjectAccessory represents an accessory to the project
 type ProjectAccessory struct {
 	ID   int    `json:"id"`
@@ -23,6 +31,8 @@ type ProjectAccessory struct {
 type Project struct {
 	ID              int                       `json:"id"`
 	GoogleID        *int                      `json:"google_id"`
+	OrganizationID  *int                      `json:"organization_id"`
+	Organization    *Organization             `json:"organization,omitempty"`
 	Title           string                    `json:"title"`
 	Description     string                    `json:"description"`
 	Website         string                    `json:"website"`
@@ -30,6 +40,7 @@ type Project struct {
 	ProjectDate     time.Time                 `json:"project_date"`
 	MaxCapacity     int                       `json:"max_capacity"`
 	CurrentReg      int                       `json:"current_registrations"`
+	ReservedSeats   int                       `json:"reserved_seats"`
 	Area            string                    `json:"area"`
 	LocationAddress string                    `json:"location_address"`
 	Latitude        float64                   `json:"latitude"`
@@ -44,4 +55,12 @@ type Project struct {
 	CreatedAt       time.Time                 `json:"created_at"`
 	UpdatedAt       time.Time                 `json:"updated_at"`
 	Status          string                    `json:"status"`
+	StatusReason    string                    `json:"status_reason"`
+	DistanceKm      *float64                  `json:"distance_km,omitempty"`
+	Drive           *services.DriveEstimate   `json:"drive,omitempty"`
+	EarlyAccess     bool                      `json:"early_access"`
+	// Registration is whether the volunteer viewing the project may register for it now, and when they can
+	Registration *models.RegistrationAccess `json:"registration,omitempty"`
+	// Deadlines are when volunteers stop being able to register for the project and cancel
+	Deadlines *models.Deadlines `json:"deadlines,omitempty"`
 }
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strings"
 
 	"serve/middleware"
 	"serve/services"
@@ -10,7 +13,7 @@ import (
 
 // GeocodingHandler handles geocoding requests
 type GeocodingHandler struct {
-	MapsService *services.MapsService
+	Geocoder services.Geocoder
 }
 
 // GeocodeRequest represents a request to geocode an address
@@ -27,13 +30,56 @@ func (h *GeocodingHandler) GeocodeAddress(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if strings.TrimSpace(req.Address) == "" {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Address is required")
+		return
+	}
+
 	// Geocode the address
-	result, err := h.MapsService.GeocodeAddress(req.Address)
+	result, err := h.Geocoder.Geocode(r.Context(), req.Address)
 	if err != nil {
-		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to geocode address: "+err.Error())
+		if errors.Is(err, services.ErrNoResults) {
+			middleware.RespondWithError(w, http.StatusNotFound, "Address not found")
+			return
+		}
+		log.Println("error geocoding address: ", err)
+		middleware.RespondWithError(w, http.StatusBadGateway, "Failed to geocode address")
 		return
 	}
 
 	// Return the result
 	middleware.RespondWithJSON(w, http.StatusOK, result)
 }
+
+// ReverseGeocodeRequest represents a request to find the address at a dropped pin
+type ReverseGeocodeRequest struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// ReverseGeocode handles requests to find the address at a latitude and longitude
+func (h *GeocodingHandler) ReverseGeocode(w http.ResponseWriter, r *http.Request) {
+	var req ReverseGeocodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if req.Latitude < -90 || req.Latitude > 90 || req.Longitude < -180 || req.Longitude > 180 {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Latitude or longitude is out of range")
+		return
+	}
+
+	result, err := h.Geocoder.ReverseGeocode(r.Context(), req.Latitude, req.Longitude)
+	if err != nil {
+		if errors.Is(err, services.ErrNoResults) {
+			middleware.RespondWithError(w, http.StatusNotFound, "No address found at that location")
+			return
+		}
+		log.Println("error reverse geocoding location: ", err)
+		middleware.RespondWithError(w, http.StatusBadGateway, "Failed to reverse geocode location")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, result)
+}
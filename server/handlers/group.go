@@ -0,0 +1,430 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"serve/config"
+	"serve/middleware"
+	"serve/models"
+)
+
+// GroupHandler handles group leader requests
+type GroupHandler struct {
+	DB     *sql.DB
+	Config *config.Config
+}
+
+// ReservationInput reserves seats on a project for a group. Without a release time, unclaimed seats are
+// released the configured number of days before the project.
+type ReservationInput struct {
+	ProjectID int        `json:"project_id"`
+	Seats     int        `json:"seats"`
+	ReleaseAt *time.Time `json:"release_at"`
+}
+
+// reservationResponse is a reservation along with the link its members register through
+type reservationResponse struct {
+	models.GroupReservation
+	InviteURL string `json:"invite_url"`
+}
+
+// RegisterGroupRoutes registers the routes for group handlers. Invite links are public; everything else
+// needs a signed-in user with the group-leader role.
+func RegisterGroupRoutes(
+	router *mux.Router, db *sql.DB, cfg *config.Config, auth func(http.Handler) http.Handler,
+) {
+	handler := &GroupHandler{
+		DB:     db,
+		Config: cfg,
+	}
+
+	access := middleware.AccessMiddleware(AccessLoader(db))
+	leader := func(next http.HandlerFunc) http.Handler {
+		return auth(access(middleware.RequirePermission(middleware.PermLeadGroups, next)))
+	}
+
+	router.HandleFunc("/invites/{code}", handler.GetGroupInvite).Methods(http.MethodGet)
+	router.Handle("", leader(handler.GetGroups)).Methods(http.MethodGet)
+	router.Handle("", leader(handler.CreateGroup)).Methods(http.MethodPost)
+	router.Handle("/{id:[0-9]+}", leader(handler.GetGroup)).Methods(http.MethodGet)
+	router.Handle("/{id:[0-9]+}", leader(handler.UpdateGroup)).Methods(http.MethodPut)
+	router.Handle("/{id:[0-9]+}/reservations", leader(handler.ReserveSeats)).Methods(http.MethodPost)
+	router.Handle(
+		"/{id:[0-9]+}/reservations/{reservationId:[0-9]+}", leader(handler.UpdateReservation),
+	).Methods(http.MethodPut)
+	router.Handle(
+		"/{id:[0-9]+}/reservations/{reservationId:[0-9]+}", leader(handler.ReleaseReservation),
+	).Methods(http.MethodDelete)
+	router.Handle(
+		"/{id:[0-9]+}/reservations/{reservationId:[0-9]+}/claims", leader(handler.GetReservationClaims),
+	).Methods(http.MethodGet)
+}
+
+// GetGroups returns the groups the signed-in leader leads with their reservations
+func (h *GroupHandler) GetGroups(w http.ResponseWriter, r *http.Request) {
+	leaderID, err := middleware.GetUserIDFromRequest(r)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusUnauthorized, "Failed to get user information")
+		return
+	}
+
+	groups, err := models.GetGroupsByLeader(r.Context(), h.DB, leaderID)
+	if err != nil {
+		respondWithGroupError(w, err, "retrieve")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, groups)
+}
+
+// CreateGroup creates a group led by the signed-in user
+func (h *GroupHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	leaderID, err := middleware.GetUserIDFromRequest(r)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusUnauthorized, "Failed to get user information")
+		return
+	}
+
+	var group models.Group
+	if err = middleware.ParseJSON(r, &group); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	group.LeaderID = leaderID
+
+	user, err := models.GetUserByID(ctx, h.DB, leaderID)
+	if err != nil {
+		log.Println("error retrieving group leader: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve user profile")
+		return
+	}
+	if user == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "User profile not found")
+		return
+	}
+
+	if err = models.CreateGroup(ctx, h.DB, &group); err != nil {
+		respondWithGroupError(w, err, "create")
+		return
+	}
+
+	recordAudit(r, h.DB, leaderID, models.AuditActionCreate, models.AuditEntityGroup, group.ID, nil, group)
+
+	middleware.RespondWithJSON(w, http.StatusCreated, group)
+}
+
+// GetGroup returns one of the signed-in leader's groups with its reservations and their invite links
+func (h *GroupHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.leaderGroup(w, r)
+	if !ok {
+		return
+	}
+
+	reservations := make([]reservationResponse, len(group.Reservations))
+	for i, reservation := range group.Reservations {
+		reservations[i] = h.reservationResponse(reservation)
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]any{
+		"group":        group,
+		"reservations": reservations,
+	})
+}
+
+// UpdateGroup renames one of the signed-in leader's groups or changes its kind
+func (h *GroupHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	before, ok := h.leaderGroup(w, r)
+	if !ok {
+		return
+	}
+
+	var group models.Group
+	if err := middleware.ParseJSON(r, &group); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	group.ID, group.LeaderID = before.ID, before.LeaderID
+
+	if err := models.UpdateGroup(r.Context(), h.DB, &group); err != nil {
+		respondWithGroupError(w, err, "update")
+		return
+	}
+	group.Reservations = before.Reservations
+
+	recordAudit(r, h.DB, group.LeaderID, models.AuditActionUpdate, models.AuditEntityGroup, group.ID, before, group)
+
+	middleware.RespondWithJSON(w, http.StatusOK, group)
+}
+
+// ReserveSeats reserves a block of seats on a project for one of the signed-in leader's groups
+func (h *GroupHandler) ReserveSeats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	group, ok := h.leaderGroup(w, r)
+	if !ok {
+		return
+	}
+
+	var input ReservationInput
+	if err := middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	inviteCode, err := generateToken()
+	if err != nil {
+		log.Println("error generating invite code: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to reserve seats")
+		return
+	}
+
+	reservation := &models.GroupReservation{
+		GroupID:    group.ID,
+		ProjectID:  input.ProjectID,
+		Seats:      input.Seats,
+		InviteCode: inviteCode,
+	}
+	if input.ReleaseAt != nil {
+		reservation.ReleaseAt = *input.ReleaseAt
+	} else {
+		project, err := models.GetProjectByID(ctx, h.DB, input.ProjectID)
+		if err != nil {
+			log.Println("error retrieving project for reservation: ", err)
+			middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to reserve seats")
+			return
+		}
+		if project == nil {
+			middleware.RespondWithError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		reservation.ReleaseAt = project.ProjectDate.AddDate(0, 0, -h.Config.GroupReleaseDays)
+	}
+
+	if err = models.ReserveSeats(ctx, h.DB, reservation); err != nil {
+		respondWithGroupError(w, err, "reserve seats for")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, group.LeaderID, models.AuditActionCreate, models.AuditEntityGroupReservation, reservation.ID, nil,
+		reservation,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusCreated, h.reservationResponse(*reservation))
+}
+
+// UpdateReservation changes how many seats a group holds on a project and when unclaimed ones are released
+func (h *GroupHandler) UpdateReservation(w http.ResponseWriter, r *http.Request) {
+	reservationID, err := strconv.Atoi(mux.Vars(r)["reservationId"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
+
+	group, ok := h.leaderGroup(w, r)
+	if !ok {
+		return
+	}
+	before := groupReservation(group, reservationID)
+	if before == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Reservation not found")
+		return
+	}
+
+	var input ReservationInput
+	if err = middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	reservation := &models.GroupReservation{
+		ID:        reservationID,
+		GroupID:   group.ID,
+		Seats:     input.Seats,
+		ReleaseAt: before.ReleaseAt,
+	}
+	if input.ReleaseAt != nil {
+		reservation.ReleaseAt = *input.ReleaseAt
+	}
+
+	if err = models.UpdateReservation(r.Context(), h.DB, reservation); err != nil {
+		respondWithGroupError(w, err, "update reservation for")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, group.LeaderID, models.AuditActionUpdate, models.AuditEntityGroupReservation, reservation.ID,
+		before, reservation,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusOK, h.reservationResponse(*reservation))
+}
+
+// ReleaseReservation gives a reservation's unclaimed seats back to everyone now rather than at its
+// release time
+func (h *GroupHandler) ReleaseReservation(w http.ResponseWriter, r *http.Request) {
+	reservationID, err := strconv.Atoi(mux.Vars(r)["reservationId"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
+
+	group, ok := h.leaderGroup(w, r)
+	if !ok {
+		return
+	}
+
+	if err = models.ReleaseReservation(r.Context(), h.DB, group.ID, reservationID); err != nil {
+		respondWithGroupError(w, err, "release seats for")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, group.LeaderID, models.AuditActionDelete, models.AuditEntityGroupReservation, reservationID,
+		groupReservation(group, reservationID), nil,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Seats released successfully"})
+}
+
+// GetReservationClaims returns who has claimed seats from one of the signed-in leader's reservations
+func (h *GroupHandler) GetReservationClaims(w http.ResponseWriter, r *http.Request) {
+	reservationID, err := strconv.Atoi(mux.Vars(r)["reservationId"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
+
+	group, ok := h.leaderGroup(w, r)
+	if !ok {
+		return
+	}
+	if groupReservation(group, reservationID) == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Reservation not found")
+		return
+	}
+
+	claims, err := models.GetReservationClaims(r.Context(), h.DB, reservationID)
+	if err != nil {
+		respondWithGroupError(w, err, "retrieve claims for")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, claims)
+}
+
+// GetGroupInvite returns the group, project and open seats behind an invite link, so members can see
+// what they are joining before they register
+func (h *GroupHandler) GetGroupInvite(w http.ResponseWriter, r *http.Request) {
+	invite, err := models.GetGroupInvite(r.Context(), h.DB, mux.Vars(r)["code"])
+	if err != nil {
+		respondWithGroupError(w, err, "retrieve invite for")
+		return
+	}
+	if invite == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Invite not found")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, invite)
+}
+
+// leaderGroup loads the group in the URL, responding with an error unless the signed-in user leads it
+func (h *GroupHandler) leaderGroup(w http.ResponseWriter, r *http.Request) (*models.Group, bool) {
+	groupID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid group ID")
+		return nil, false
+	}
+
+	leaderID, err := middleware.GetUserIDFromRequest(r)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusUnauthorized, "Failed to get user information")
+		return nil, false
+	}
+
+	group, err := models.GetGroup(r.Context(), h.DB, groupID, leaderID)
+	if err != nil {
+		respondWithGroupError(w, err, "retrieve")
+		return nil, false
+	}
+	if group == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Group not found")
+		return nil, false
+	}
+	return group, true
+}
+
+// reservationResponse adds the invite link to a reservation
+func (h *GroupHandler) reservationResponse(reservation models.GroupReservation) reservationResponse {
+	return reservationResponse{
+		GroupReservation: reservation,
+		InviteURL:        groupInviteURL(h.Config, reservation.ProjectID, reservation.InviteCode),
+	}
+}
+
+// groupInviteURL builds the link members open to register with their group
+func groupInviteURL(cfg *config.Config, projectID int, code string) string {
+	return cfg.AppURL + "/projects/" + strconv.Itoa(projectID) + "?invite=" + code
+}
+
+// groupReservation finds one of the group's reservations
+func groupReservation(group *models.Group, reservationID int) *models.GroupReservation {
+	for i := range group.Reservations {
+		if group.Reservations[i].ID == reservationID {
+			return &group.Reservations[i]
+		}
+	}
+	return nil
+}
+
+// inviteReservation resolves the invite code a volunteer registered with to the reservation whose seats
+// they claim, responding with an error if the code is unknown or for another project. Invites keep
+// working after their seats are released, but then take spots from everyone's.
+func inviteReservation(
+	w http.ResponseWriter, r *http.Request, db *sql.DB, projectID int, code string,
+) (*int, bool) {
+	if code == "" {
+		return nil, true
+	}
+
+	invite, err := models.GetGroupInvite(r.Context(), db, code)
+	if err != nil {
+		respondWithGroupError(w, err, "retrieve invite for")
+		return nil, false
+	}
+	if invite == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Invite not found")
+		return nil, false
+	}
+	if invite.ProjectID != projectID {
+		middleware.RespondWithError(w, http.StatusBadRequest, "This invite is for a different project")
+		return nil, false
+	}
+	return &invite.ReservationID, true
+}
+
+// respondWithGroupError maps group and reservation errors to responses
+func respondWithGroupError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		middleware.RespondWithError(w, http.StatusNotFound, "Reservation or project not found")
+	case errors.Is(err, models.ErrInvalidGroup), errors.Is(err, models.ErrInvalidReservation),
+		errors.Is(err, models.ErrProjectNotOpen):
+		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrReservationExists), errors.Is(err, models.ErrNotEnoughSeats),
+		errors.Is(err, models.ErrSeatsClaimed):
+		middleware.RespondWithError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("failed to %s group: %v", action, err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to "+action+" group")
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"serve/config"
+	"serve/middleware"
+)
+
+// HealthHandler reports whether the server and its integrations are ready
+type HealthHandler struct {
+	DB     *sql.DB
+	Config *config.Config
+}
+
+// HealthReport is the body of /healthz. It names the settings that are missing, never their values.
+type HealthReport struct {
+	Status       string               `json:"status"`
+	DevMode      bool                 `json:"dev_mode"`
+	Database     string               `json:"database"`
+	Integrations []config.Integration `json:"integrations"`
+}
+
+// Health statuses
+const (
+	healthOK       = "ok"
+	healthDegraded = "degraded"
+)
+
+// GetHealth returns the health report. It answers 503 when the database cannot be reached, so load
+// balancers stop sending traffic, and 200 otherwise; unconfigured integrations only degrade the status.
+func (h *HealthHandler) GetHealth(w http.ResponseWriter, r *http.Request) {
+	report := HealthReport{
+		Status:       healthOK,
+		DevMode:      h.Config.DevMode,
+		Database:     healthOK,
+		Integrations: h.Config.Integrations(),
+	}
+
+	for _, integration := range report.Integrations {
+		if integration.Required && !integration.Configured {
+			report.Status = healthDegraded
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+	if err := h.DB.PingContext(ctx); err != nil {
+		log.Println("error pinging database for health check: ", err)
+		report.Status = healthDegraded
+		report.Database = "unreachable"
+		middleware.RespondWithJSON(w, http.StatusServiceUnavailable, report)
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, report)
+}
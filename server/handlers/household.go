@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"serve/middleware"
+	"serve/models"
+)
+
+// HouseholdInput names the signed-in user's household
+type HouseholdInput struct {
+	Name string `json:"name"`
+}
+
+// GetHousehold returns the signed-in user's household with its members
+func (h *UserHandler) GetHousehold(w http.ResponseWriter, r *http.Request) {
+	household, ok := h.signedInHousehold(w, r)
+	if !ok {
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, household)
+}
+
+// SaveHousehold creates the signed-in user's household, making them its primary contact, or renames it
+func (h *UserHandler) SaveHousehold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := middleware.GetUserIDFromRequest(r)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusUnauthorized, "Failed to get user information")
+		return
+	}
+
+	var input HouseholdInput
+	if err = middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	user, err := models.GetUserByID(ctx, h.DB, userID)
+	if err != nil {
+		log.Println("error retrieving household user: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve user profile")
+		return
+	}
+	if user == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "User profile not found")
+		return
+	}
+
+	before, err := models.GetHouseholdByUser(ctx, h.DB, userID)
+	if err != nil {
+		respondWithHouseholdError(w, err, "retrieve")
+		return
+	}
+
+	household := &models.Household{PrimaryUserID: userID, Name: input.Name}
+	if household.Name == "" {
+		household.Name = user.LastName
+	}
+	if err = models.SaveHousehold(ctx, h.DB, household); err != nil {
+		respondWithHouseholdError(w, err, "save")
+		return
+	}
+	household.Members = []models.HouseholdMember{}
+	if before != nil {
+		household.Members = before.Members
+	}
+
+	action := models.AuditActionCreate
+	if before != nil {
+		action = models.AuditActionUpdate
+	}
+	recordAudit(r, h.DB, userID, action, models.AuditEntityHousehold, household.ID, before, household)
+
+	middleware.RespondWithJSON(w, http.StatusOK, household)
+}
+
+// CreateHouseholdMember adds a member to the signed-in user's household
+func (h *UserHandler) CreateHouseholdMember(w http.ResponseWriter, r *http.Request) {
+	household, ok := h.signedInHousehold(w, r)
+	if !ok {
+		return
+	}
+
+	var member models.HouseholdMember
+	if err := middleware.ParseJSON(r, &member); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	member.HouseholdID = household.ID
+
+	if err := models.CreateHouseholdMember(r.Context(), h.DB, &member); err != nil {
+		respondWithHouseholdError(w, err, "add member to")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, household.PrimaryUserID, models.AuditActionCreate, models.AuditEntityHousehold, household.ID,
+		nil, member,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusCreated, member)
+}
+
+// UpdateHouseholdMember updates a member of the signed-in user's household
+func (h *UserHandler) UpdateHouseholdMember(w http.ResponseWriter, r *http.Request) {
+	memberID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid member ID")
+		return
+	}
+
+	household, ok := h.signedInHousehold(w, r)
+	if !ok {
+		return
+	}
+
+	var member models.HouseholdMember
+	if err = middleware.ParseJSON(r, &member); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	member.ID, member.HouseholdID = memberID, household.ID
+
+	if err = models.UpdateHouseholdMember(r.Context(), h.DB, &member); err != nil {
+		respondWithHouseholdError(w, err, "update member of")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, household.PrimaryUserID, models.AuditActionUpdate, models.AuditEntityHousehold, household.ID,
+		householdMember(household, memberID), member,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusOK, member)
+}
+
+// DeleteHouseholdMember removes a member from the signed-in user's household
+func (h *UserHandler) DeleteHouseholdMember(w http.ResponseWriter, r *http.Request) {
+	memberID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid member ID")
+		return
+	}
+
+	household, ok := h.signedInHousehold(w, r)
+	if !ok {
+		return
+	}
+
+	if err = models.DeleteHouseholdMember(r.Context(), h.DB, household.ID, memberID); err != nil {
+		respondWithHouseholdError(w, err, "remove member from")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, household.PrimaryUserID, models.AuditActionDelete, models.AuditEntityHousehold, household.ID,
+		householdMember(household, memberID), nil,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Member removed successfully"})
+}
+
+// GetHouseholdHistory returns the projects each member of the signed-in user's household has been
+// registered for, including members who have since been removed
+func (h *UserHandler) GetHouseholdHistory(w http.ResponseWriter, r *http.Request) {
+	household, ok := h.signedInHousehold(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := models.GetHouseholdHistory(r.Context(), h.DB, household.ID)
+	if err != nil {
+		respondWithHouseholdError(w, err, "retrieve history for")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, history)
+}
+
+// signedInHousehold loads the household the signed-in user is the primary contact for, responding
+// with an error if they have none
+func (h *UserHandler) signedInHousehold(w http.ResponseWriter, r *http.Request) (*models.Household, bool) {
+	userID, err := middleware.GetUserIDFromRequest(r)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusUnauthorized, "Failed to get user information")
+		return nil, false
+	}
+
+	household, err := models.GetHouseholdByUser(r.Context(), h.DB, userID)
+	if err != nil {
+		respondWithHouseholdError(w, err, "retrieve")
+		return nil, false
+	}
+	if household == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Household not found")
+		return nil, false
+	}
+	return household, true
+}
+
+// selectHouseholdMembers loads the registrant's household and picks out the members they are registering,
+// responding with an error if any of them is not in it. Registrations are made by email, so only the
+// household's primary user, signed in, may register its members.
+func selectHouseholdMembers(
+	w http.ResponseWriter, r *http.Request, db *sql.DB, userID string, memberIDs []int,
+) ([]models.HouseholdMember, bool) {
+	if len(memberIDs) == 0 {
+		return nil, true
+	}
+
+	subject, err := middleware.GetUserIDFromRequest(r)
+	if err != nil || subject == "" {
+		middleware.RespondWithError(w, http.StatusUnauthorized, "Sign in to register members of your household")
+		return nil, false
+	}
+	if subject != userID {
+		middleware.RespondWithError(w, http.StatusForbidden, "Only the household's primary member can register it")
+		return nil, false
+	}
+
+	household, err := models.GetHouseholdByUser(r.Context(), db, userID)
+	if err != nil {
+		respondWithHouseholdError(w, err, "retrieve")
+		return nil, false
+	}
+
+	members, err := models.SelectHouseholdMembers(household, memberIDs)
+	if err != nil {
+		respondWithHouseholdError(w, err, "register members of")
+		return nil, false
+	}
+	return members, true
+}
+
+// householdMember finds a current member of the household, for audit entries
+func householdMember(household *models.Household, memberID int) *models.HouseholdMember {
+	for i := range household.Members {
+		if household.Members[i].ID == memberID {
+			return &household.Members[i]
+		}
+	}
+	return nil
+}
+
+// respondWithHouseholdError maps household errors to responses
+func respondWithHouseholdError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		middleware.RespondWithError(w, http.StatusNotFound, "Household member not found")
+	case errors.Is(err, models.ErrInvalidMember), errors.Is(err, models.ErrNotHouseholdMember):
+		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("failed to %s household: %v", action, err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to "+action+" household")
+	}
+}
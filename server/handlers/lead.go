@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"serve/middleware"
+	"serve/models"
+)
+
+// LeadInvitationHandler handles volunteers answering invitations to lead a project
+type LeadInvitationHandler struct {
+	DB *sql.DB
+}
+
+// LeadInvitationInput invites a lead-interested volunteer to lead a project
+type LeadInvitationInput struct {
+	ProjectID int    `json:"project_id"`
+	UserID    string `json:"user_id"`
+	Message   string `json:"message"`
+}
+
+// leadInvitationResponse is a new invitation along with the link the volunteer answers it through. The
+// link carries the token, so it is only returned when the invitation is created.
+type leadInvitationResponse struct {
+	models.LeadInvitation
+	InviteURL string `json:"invite_url,omitempty"`
+}
+
+// RegisterLeadInvitationRoutes registers the public routes volunteers answer invitations to lead through.
+// The token in the link is all they need.
+func RegisterLeadInvitationRoutes(router *mux.Router, db *sql.DB) {
+	handler := &LeadInvitationHandler{
+		DB: db,
+	}
+
+	router.HandleFunc("/{token:[0-9a-f]+}", handler.GetLeadInvitation).Methods(http.MethodGet)
+	router.HandleFunc("/{token:[0-9a-f]+}/accept", handler.AcceptLeadInvitation).Methods(http.MethodPost)
+	router.HandleFunc("/{token:[0-9a-f]+}/decline", handler.DeclineLeadInvitation).Methods(http.MethodPost)
+}
+
+// GetLeadInvitation returns the invitation a volunteer's link points to
+func (h *LeadInvitationHandler) GetLeadInvitation(w http.ResponseWriter, r *http.Request) {
+	invitation, err := models.GetLeadInvitationByToken(r.Context(), h.DB, mux.Vars(r)["token"])
+	if err != nil {
+		log.Println("error retrieving lead invitation: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve invitation")
+		return
+	}
+	if invitation == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Invitation not found")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, invitation)
+}
+
+// AcceptLeadInvitation makes the volunteer a lead of the project they were invited to lead
+func (h *LeadInvitationHandler) AcceptLeadInvitation(w http.ResponseWriter, r *http.Request) {
+	h.respond(w, r, true)
+}
+
+// DeclineLeadInvitation records that the volunteer will not lead the project
+func (h *LeadInvitationHandler) DeclineLeadInvitation(w http.ResponseWriter, r *http.Request) {
+	h.respond(w, r, false)
+}
+
+// respond records the volunteer's answer to the invitation in the URL
+func (h *LeadInvitationHandler) respond(w http.ResponseWriter, r *http.Request, accept bool) {
+	invitation, err := models.RespondToLeadInvitation(r.Context(), h.DB, mux.Vars(r)["token"], accept)
+	if err != nil {
+		respondWithLeadInvitationError(w, err, "answer")
+		return
+	}
+
+	before := *invitation
+	before.Status, before.RespondedAt = models.LeadInvitationPending, nil
+	recordAudit(
+		r, h.DB, auditActor(r, invitation.Email), models.AuditActionUpdate, models.AuditEntityLeadInvitation,
+		invitation.ID, before, invitation,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusOK, invitation)
+}
+
+// GetLeadPipeline returns the volunteers willing to lead alongside the upcoming projects without an active
+// lead, and the invitations still waiting on an answer
+func (h *AdminHandler) GetLeadPipeline(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	from := time.Now()
+
+	candidates, err := models.GetLeadCandidates(ctx, h.DB, from)
+	if err != nil {
+		log.Println("error retrieving lead candidates: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve lead candidates")
+		return
+	}
+
+	projects, err := models.GetUnledProjects(ctx, h.DB, from)
+	if err != nil {
+		log.Println("error retrieving projects without leads: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve projects without leads")
+		return
+	}
+
+	invitations, err := models.GetLeadInvitations(ctx, h.DB, models.LeadInvitationPending)
+	if err != nil {
+		log.Println("error retrieving lead invitations: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve lead invitations")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]any{
+		"candidates":  candidates,
+		"projects":    projects,
+		"invitations": invitations,
+	})
+}
+
+// GetLeadInvitations returns every invitation to lead, optionally only those with the status in the query.
+// The links volunteers answer them through are left out.
+func (h *AdminHandler) GetLeadInvitations(w http.ResponseWriter, r *http.Request) {
+	invitations, err := models.GetLeadInvitations(r.Context(), h.DB, r.URL.Query().Get("status"))
+	if err != nil {
+		log.Println("error retrieving lead invitations: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve lead invitations")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, invitations)
+}
+
+// CreateLeadInvitation invites a volunteer to lead a project and emails them the link to answer it
+func (h *AdminHandler) CreateLeadInvitation(w http.ResponseWriter, r *http.Request) {
+	var input LeadInvitationInput
+	if err := middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if input.ProjectID <= 0 || strings.TrimSpace(input.UserID) == "" {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Project and volunteer are required")
+		return
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		log.Println("error generating lead invitation token: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to create invitation")
+		return
+	}
+
+	actor := auditActor(r, "")
+	invitation := &models.LeadInvitation{
+		ProjectID: input.ProjectID,
+		UserID:    strings.TrimSpace(input.UserID),
+		Token:     token,
+		Message:   input.Message,
+		InvitedBy: actor,
+	}
+	if err := models.CreateLeadInvitation(r.Context(), h.DB, invitation); err != nil {
+		respondWithLeadInvitationError(w, err, "create")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, actor, models.AuditActionCreate, models.AuditEntityLeadInvitation, invitation.ID, nil, invitation,
+	)
+
+	inviteURL := h.leadInviteURL(token)
+	go h.EmailService.SendLeadInvitation(invitation, inviteURL)
+
+	middleware.RespondWithJSON(w, http.StatusCreated, leadInvitationResponse{*invitation, inviteURL})
+}
+
+// WithdrawLeadInvitation takes back an invitation the volunteer has not answered
+func (h *AdminHandler) WithdrawLeadInvitation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid invitation ID")
+		return
+	}
+
+	invitation, err := models.WithdrawLeadInvitation(r.Context(), h.DB, id)
+	if err != nil {
+		respondWithLeadInvitationError(w, err, "withdraw")
+		return
+	}
+
+	before := *invitation
+	before.Status = models.LeadInvitationPending
+	recordAudit(
+		r, h.DB, auditActor(r, ""), models.AuditActionCancel, models.AuditEntityLeadInvitation, id, before,
+		invitation,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusOK, invitation)
+}
+
+// leadInviteURL builds the link a volunteer answers an invitation to lead through
+func (h *AdminHandler) leadInviteURL(token string) string {
+	return h.Config.AppURL + "/lead-invitations/" + token
+}
+
+// respondWithLeadInvitationError maps lead invitation errors to responses
+func respondWithLeadInvitationError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		middleware.RespondWithError(w, http.StatusNotFound, "Invitation, project or volunteer not found")
+	case errors.Is(err, models.ErrInvitationExists), errors.Is(err, models.ErrInvitationAnswered),
+		errors.Is(err, models.ErrProjectNotLeadable):
+		middleware.RespondWithError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("failed to %s lead invitation: %v", action, err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to "+action+" invitation")
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"serve/middleware"
+	"serve/models"
+	"serve/services"
+)
+
+// checkedProject is a saved project along with the result of checking its location, which the admin
+// app shows as a warning and an offer to use the normalized address
+type checkedProject struct {
+	*models.Project
+	LocationCheck *services.LocationCheck `json:"location_check,omitempty"`
+}
+
+// LocationInput is a location to validate before it is saved on a project
+type LocationInput struct {
+	Address   string  `json:"address"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// checkLocation compares the project's address with its coordinates, filling in missing coordinates
+// from the geocoded address. Projects with a private location have their exact address checked, as
+// the public one is only an area. A failed check is logged and never blocks saving the project.
+func (h *AdminHandler) checkLocation(r *http.Request, project *models.Project) *services.LocationCheck {
+	if h.Locations == nil {
+		return nil
+	}
+
+	address, lat, lng := &project.LocationAddress, &project.Latitude, &project.Longitude
+	if project.HasPrivateLocation() {
+		address, lat, lng = &project.ExactAddress, &project.ExactLatitude, &project.ExactLongitude
+	}
+
+	check, err := h.Locations.Check(r.Context(), *address, *lat, *lng)
+	if err != nil {
+		log.Println("error checking project location: ", err)
+		return nil
+	}
+
+	if check != nil && *lat == 0 && *lng == 0 && check.FormattedAddress != "" {
+		*lat, *lng = check.Latitude, check.Longitude
+	}
+	return check
+}
+
+// ValidateLocation checks an address and coordinates without saving anything
+func (h *AdminHandler) ValidateLocation(w http.ResponseWriter, r *http.Request) {
+	var input LocationInput
+	if err := middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if strings.TrimSpace(input.Address) == "" {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Address is required")
+		return
+	}
+
+	if h.Locations == nil {
+		middleware.RespondWithError(w, http.StatusServiceUnavailable, "Location validation is not configured")
+		return
+	}
+
+	check, err := h.Locations.Check(r.Context(), input.Address, input.Latitude, input.Longitude)
+	if err != nil {
+		log.Println("error validating location: ", err)
+		middleware.RespondWithError(w, http.StatusBadGateway, "Failed to validate location")
+		return
+	}
+	if check == nil {
+		check = &services.LocationCheck{Warning: "The address is still to be determined"}
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, check)
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"serve/middleware"
+	"serve/models"
+)
+
+// OrganizationInput represents the input for creating or updating a partner organization
+type OrganizationInput struct {
+	Name         string `json:"name"`
+	Website      string `json:"website"`
+	ContactName  string `json:"contact_name"`
+	ContactEmail string `json:"contact_email"`
+	ContactPhone string `json:"contact_phone"`
+	LogoURL      string `json:"logo_url"`
+	Address      string `json:"address"`
+	Notes        string `json:"notes"`
+}
+
+// apply copies the input fields onto the organization
+func (in *OrganizationInput) apply(o *models.Organization) {
+	o.Name = strings.TrimSpace(in.Name)
+	o.Website = strings.TrimSpace(in.Website)
+	o.ContactName = strings.TrimSpace(in.ContactName)
+	o.ContactEmail = strings.TrimSpace(in.ContactEmail)
+	o.ContactPhone = strings.TrimSpace(in.ContactPhone)
+	o.LogoURL = strings.TrimSpace(in.LogoURL)
+	o.Address = in.Address
+	o.Notes = in.Notes
+}
+
+// GetOrganizations returns all partner organizations
+func (h *AdminHandler) GetOrganizations(w http.ResponseWriter, r *http.Request) {
+	organizations, err := models.GetAllOrganizations(r.Context(), h.DB)
+	if err != nil {
+		log.Println("error retrieving organizations: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve organizations")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, organizations)
+}
+
+// GetOrganization returns a single partner organization
+func (h *AdminHandler) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	organization, ok := h.lookupOrganization(w, r)
+	if !ok {
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, organization)
+}
+
+// CreateOrganization creates a new partner organization
+func (h *AdminHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	var input OrganizationInput
+	if err := middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if strings.TrimSpace(input.Name) == "" {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Organization name is required")
+		return
+	}
+
+	organization := &models.Organization{}
+	input.apply(organization)
+
+	if err := models.CreateOrganization(r.Context(), h.DB, organization); err != nil {
+		respondWithOrganizationError(w, err, "create")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, auditActor(r, ""), models.AuditActionCreate, models.AuditEntityOrganization, organization.ID, nil,
+		organization,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusCreated, organization)
+}
+
+// UpdateOrganization updates an existing partner organization
+func (h *AdminHandler) UpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	organization, ok := h.lookupOrganization(w, r)
+	if !ok {
+		return
+	}
+
+	var input OrganizationInput
+	if err := middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if strings.TrimSpace(input.Name) == "" {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Organization name is required")
+		return
+	}
+
+	before := *organization
+	input.apply(organization)
+
+	if err := models.UpdateOrganization(r.Context(), h.DB, organization); err != nil {
+		respondWithOrganizationError(w, err, "update")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, auditActor(r, ""), models.AuditActionUpdate, models.AuditEntityOrganization, organization.ID, before,
+		organization,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusOK, organization)
+}
+
+// DeleteOrganization deletes a partner organization. Its projects are kept but no longer linked to it.
+func (h *AdminHandler) DeleteOrganization(w http.ResponseWriter, r *http.Request) {
+	organization, ok := h.lookupOrganization(w, r)
+	if !ok {
+		return
+	}
+
+	if err := models.DeleteOrganization(r.Context(), h.DB, organization.ID); err != nil {
+		respondWithOrganizationError(w, err, "delete")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, auditActor(r, ""), models.AuditActionDelete, models.AuditEntityOrganization, organization.ID,
+		organization, nil,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Organization deleted successfully"})
+}
+
+// GetOrganizationRoster returns the active registrations across all of an organization's projects
+func (h *AdminHandler) GetOrganizationRoster(w http.ResponseWriter, r *http.Request) {
+	organization, ok := h.lookupOrganization(w, r)
+	if !ok {
+		return
+	}
+
+	roster, err := models.GetOrganizationRoster(r.Context(), h.DB, organization.ID)
+	if err != nil {
+		log.Println("error retrieving organization roster: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve roster")
+		return
+	}
+
+	middleware.RespondWithJSON(
+		w, http.StatusOK, map[string]any{
+			"organization":  organization,
+			"registrations": roster,
+		},
+	)
+}
+
+// GetOrganizationHistory returns what an organization hosted, year over year
+func (h *AdminHandler) GetOrganizationHistory(w http.ResponseWriter, r *http.Request) {
+	organization, ok := h.lookupOrganization(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := models.GetOrganizationHistory(r.Context(), h.DB, organization.ID)
+	if err != nil {
+		log.Println("error retrieving organization history: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve history")
+		return
+	}
+
+	middleware.RespondWithJSON(
+		w, http.StatusOK, map[string]any{
+			"organization": organization,
+			"years":        history,
+		},
+	)
+}
+
+// lookupOrganization loads the organization for the ID in the URL, responding with an error if there is none
+func (h *AdminHandler) lookupOrganization(w http.ResponseWriter, r *http.Request) (*models.Organization, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid organization ID")
+		return nil, false
+	}
+
+	organization, err := models.GetOrganizationByID(r.Context(), h.DB, id)
+	if err != nil {
+		log.Println("error retrieving organization: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve organization")
+		return nil, false
+	}
+
+	if organization == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Organization not found")
+		return nil, false
+	}
+
+	return organization, true
+}
+
+// respondWithOrganizationError maps an organization write error to the matching HTTP status
+func respondWithOrganizationError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		middleware.RespondWithError(w, http.StatusNotFound, "Organization not found")
+	case errors.Is(err, models.ErrOrganizationExists):
+		middleware.RespondWithError(w, http.StatusConflict, "An organization with this name already exists")
+	default:
+		log.Printf("failed to %s organization: %v", action, err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to "+action+" organization")
+	}
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"math"
 	"net/http"
@@ -25,56 +27,103 @@ type ProjectHandler struct {
 	EmailService *services.EmailService
 	Config       *config.Config
 	TextService  *services.TextService
+	Geocoder     services.Geocoder
+	Drive        *services.DriveEstimator
 }
 
 // regRequest defines the JSON request for registration
 type regRequest struct {
-	GuestCount       int    `json:"guest_count"`
-	IsLeadInterested bool   `json:"lead_interest"`
-	FirstName        string `json:"first_name"`
-	LastName         string `json:"last_name"`
-	Phone            string `json:"phone"`
-	Email            string `json:"email"`
-	TextPerm         bool   `json:"text_permission"`
-	Recaptcha        string `json:"recaptcha"`
+	GuestCount       int           `json:"guest_count"`
+	IsLeadInterested bool          `json:"lead_interest"`
+	FirstName        string        `json:"first_name"`
+	LastName         string        `json:"last_name"`
+	Phone            string        `json:"phone"`
+	Email            string        `json:"email"`
+	TextPerm         bool          `json:"text_permission"`
+	Recaptcha        string        `json:"recaptcha"`
+	Carpool          *CarpoolInput `json:"carpool,omitempty"`
+	// MemberIDs are the registrant's household members coming along; each takes one of the guest spots
+	MemberIDs []int `json:"member_ids,omitempty"`
+	// InviteCode is the group invite the volunteer registered through, whose reserved seats they claim
+	InviteCode string `json:"invite_code,omitempty"`
+	// AccessCode lets group leaders register in their early window without signing in
+	AccessCode string `json:"access_code,omitempty"`
 }
 
-// RegisterProjectRoutes registers the routes for project handlers
+// RegisterProjectRoutes registers the routes for project handlers. Routes that share a volunteer's
+// private details need a signed-in user; others only use one when there is one.
 func RegisterProjectRoutes(
 	router *mux.Router, db *sql.DB, cfg *config.Config, emailService *services.EmailService,
-	textService *services.TextService,
+	textService *services.TextService, geocoder services.Geocoder, auth, optionalAuth func(http.Handler) http.Handler,
 ) {
 	handler := &ProjectHandler{
 		DB:           db,
 		EmailService: emailService,
 		Config:       cfg,
 		TextService:  textService,
+		Geocoder:     geocoder,
+		Drive:        services.NewDriveEstimator(geocoder, cfg.CampusAddress),
 	}
 
 	router.HandleFunc("", handler.GetProjects).Methods("GET")
 	router.HandleFunc("/my", handler.GetMyProject).Methods("GET")
+	router.HandleFunc("/map", handler.GetProjectMap).Methods("GET")
 	router.HandleFunc("/types", handler.GetTypes).Methods("GET")
-	router.HandleFunc("/{id:[0-9]+}", handler.GetProject).Methods("GET")
-	router.HandleFunc("/{id:[0-9]+}/register", handler.RegisterForProject).Methods("POST")
-	router.HandleFunc("/{id:[0-9]+}/cancel", handler.CancelRegistration).Methods("POST")
-	router.HandleFunc("/{id:[0-9]+}/registrations", handler.GetProjectRegistrations).Methods("GET")
+	router.HandleFunc("/registration-windows", handler.GetRegistrationWindows).Methods(http.MethodGet)
+	router.Handle("/{id:[0-9]+}", optionalAuth(http.HandlerFunc(handler.GetProject))).Methods("GET")
+	router.Handle("/{id:[0-9]+}/register", optionalAuth(http.HandlerFunc(handler.RegisterForProject))).Methods("POST")
+	router.Handle("/{id:[0-9]+}/cancel", optionalAuth(http.HandlerFunc(handler.CancelRegistration))).Methods("POST")
+	router.Handle("/{id:[0-9]+}/registrations", auth(http.HandlerFunc(handler.GetProjectRegistrations))).Methods("GET")
+	router.Handle("/{id:[0-9]+}/location", auth(http.HandlerFunc(handler.GetProjectLocation))).Methods("GET")
+	router.Handle("/{id:[0-9]+}/carpool", auth(http.HandlerFunc(handler.SaveCarpool))).Methods("POST")
+	router.Handle("/{id:[0-9]+}/carpool", auth(http.HandlerFunc(handler.DeleteCarpool))).Methods("DELETE")
 }
 
-// GetProjects returns all projects
+// GetProjects returns the public projects matching the search, filter and sort query parameters. When
+// a limit is given the results are paginated: the total number of matches is sent in the X-Total-Count
+// header and the cursor for the next page, if any, in the X-Next-Cursor header. Projects near a point,
+// given as near=lat,lng or as a near_address to geocode, are sorted by distance.
 func (h *ProjectHandler) GetProjects(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	projects, err := models.GetAllProjects(ctx, h.DB)
+
+	filter, err := parseProjectFilter(r.URL.Query())
 	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if address := strings.TrimSpace(r.URL.Query().Get("near_address")); address != "" && filter.Near == nil {
+		result, err := h.Geocoder.Geocode(ctx, address)
+		if err != nil {
+			log.Println("error geocoding near_address: ", err)
+			middleware.RespondWithError(w, http.StatusBadRequest, "Could not find near_address")
+			return
+		}
+		filter.Near = &models.Location{Latitude: result.Latitude, Longitude: result.Longitude}
+	}
+
+	page, err := models.SearchProjects(ctx, h.DB, filter)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidSort) || errors.Is(err, models.ErrInvalidCursor) {
+			middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		log.Println("error getting projects: ", err)
 		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve projects")
 		return
 	}
 
-	var response []dto.Project
-	for _, project := range projects {
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
+
+	response := []dto.Project{}
+	for _, project := range page.Projects {
 		proj := dto.Project{
 			ID:              project.ID,
 			GoogleID:        project.GoogleID,
+			OrganizationID:  project.OrganizationID,
 			Title:           project.Title,
 			Description:     project.Description,
 			Website:         project.Website,
@@ -82,6 +131,7 @@ func (h *ProjectHandler) GetProjects(w http.ResponseWriter, r *http.Request) {
 			ProjectDate:     project.ProjectDate,
 			MaxCapacity:     project.MaxCapacity,
 			CurrentReg:      project.CurrentReg,
+			ReservedSeats:   project.ReservedSeats,
 			Area:            project.Area,
 			LocationAddress: project.LocationAddress,
 			Latitude:        project.Latitude,
@@ -95,6 +145,9 @@ func (h *ProjectHandler) GetProjects(w http.ResponseWriter, r *http.Request) {
 			CreatedAt:       project.CreatedAt,
 			UpdatedAt:       project.UpdatedAt,
 			Status:          project.Status,
+			StatusReason:    project.StatusReason,
+			DistanceKm:      project.DistanceKm,
+			Drive:           h.driveEstimate(ctx, project),
 		}
 
 		if len(project.Leads) > 0 {
@@ -115,6 +168,49 @@ func (h *ProjectHandler) GetProjects(w http.ResponseWriter, r *http.Request) {
 	middleware.RespondWithJSON(w, http.StatusOK, response)
 }
 
+// GetProjectMap returns the public projects as GeoJSON, with projects at the same or nearby
+// coordinates grouped into a single feature. The precision query parameter sets how many decimal
+// places coordinates are rounded to before grouping. The search and filter parameters of GetProjects
+// also apply.
+func (h *ProjectHandler) GetProjectMap(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	filter, err := parseProjectFilter(params)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.Sort, filter.Cursor, filter.Limit = "", "", 0
+
+	precision, err := intParam(params, "precision", models.DefaultClusterPrecision)
+	if err != nil || precision > models.MaxClusterPrecision {
+		middleware.RespondWithError(
+			w, http.StatusBadRequest, fmt.Sprintf("precision must be between 0 and %d", models.MaxClusterPrecision),
+		)
+		return
+	}
+
+	page, err := models.SearchProjects(r.Context(), h.DB, filter)
+	if err != nil {
+		log.Println("error getting projects for map: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve projects")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, models.ClusterProjects(page.Projects, precision))
+}
+
+// driveEstimate estimates the drive to the project from the campus. Failures are logged and leave
+// the estimate out.
+func (h *ProjectHandler) driveEstimate(ctx context.Context, project models.Project) *services.DriveEstimate {
+	estimate, err := h.Drive.Estimate(ctx, project.Latitude, project.Longitude)
+	if err != nil {
+		log.Println("error estimating drive: ", err)
+		return nil
+	}
+	return estimate
+}
+
 // GetMyProject returns the project for a user that has already signed up
 func (h *ProjectHandler) GetMyProject(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -155,7 +251,8 @@ func (h *ProjectHandler) GetMyProject(w http.ResponseWriter, r *http.Request) {
 	middleware.RespondWithJSON(w, http.StatusOK, registration)
 }
 
-// GetProject returns a specific project by ID
+// GetProject returns a specific project by ID. Submissions waiting on review or turned down are only
+// returned to admins.
 func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	vars := mux.Vars(r)
@@ -176,6 +273,19 @@ func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !models.IsPublicStatus(project.Status) {
+		admin, err := requestCan(r, h.DB, middleware.PermManageProjects)
+		if err != nil {
+			log.Println("error checking permissions for project: ", err)
+			middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve project")
+			return
+		}
+		if !admin {
+			middleware.RespondWithError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+	}
+
 	// Get serve lead details if serve lead ID exists
 	if project.ServeLeadID != "" {
 		serveLead, err := models.GetUserByID(ctx, h.DB, project.ServeLeadID)
@@ -188,9 +298,53 @@ func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var organization *dto.Organization
+	if project.OrganizationID != nil {
+		org, err := models.GetOrganizationByID(ctx, h.DB, *project.OrganizationID)
+		if err != nil {
+			middleware.RespondWithError(w, http.StatusInternalServerError, "organization query error")
+			return
+		}
+		if org != nil {
+			organization = &dto.Organization{Name: org.Name, Website: org.Website}
+		}
+	}
+
+	reservedSeats, err := models.GetReservedSeats(ctx, h.DB, project.ID)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusInternalServerError, "reserved seats query error")
+		return
+	}
+
+	invited := false
+	if code := r.URL.Query().Get("invite"); code != "" {
+		invite, err := models.GetGroupInvite(ctx, h.DB, code)
+		if err != nil {
+			middleware.RespondWithError(w, http.StatusInternalServerError, "group invite query error")
+			return
+		}
+		invited = invite != nil && invite.ProjectID == project.ID
+	}
+
+	access, err := registrationAccess(r, h.DB, h.Config, project, r.URL.Query().Get("access_code"), invited)
+	if err != nil {
+		log.Println("error checking registration windows: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "registration window query error")
+		return
+	}
+
+	deadlines, err := projectDeadlines(ctx, h.DB, h.Config, project)
+	if err != nil {
+		log.Println("error retrieving project deadlines: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "deadline query error")
+		return
+	}
+
 	proj := dto.Project{
 		ID:              project.ID,
 		GoogleID:        project.GoogleID,
+		OrganizationID:  project.OrganizationID,
+		Organization:    organization,
 		Title:           project.Title,
 		Description:     project.Description,
 		Website:         project.Website,
@@ -198,6 +352,7 @@ func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
 		ProjectDate:     project.ProjectDate,
 		MaxCapacity:     project.MaxCapacity,
 		CurrentReg:      project.CurrentReg,
+		ReservedSeats:   reservedSeats,
 		Area:            project.Area,
 		LocationAddress: project.LocationAddress,
 		Latitude:        project.Latitude,
@@ -211,6 +366,11 @@ func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
 		CreatedAt:       project.CreatedAt,
 		UpdatedAt:       project.UpdatedAt,
 		Status:          project.Status,
+		StatusReason:    project.StatusReason,
+		Drive:           h.driveEstimate(ctx, *project),
+		EarlyAccess:     project.EarlyAccess,
+		Registration:    &access,
+		Deadlines:       &deadlines,
 	}
 
 	if len(project.Leads) > 0 {
@@ -279,6 +439,16 @@ func (h *ProjectHandler) RegisterForProject(w http.ResponseWriter, r *http.Reque
 
 	err = nil
 
+	groupReservationID, ok := inviteReservation(w, r, h.DB, projectID, strings.TrimSpace(reg.InviteCode))
+	if !ok {
+		return
+	}
+
+	// Registration may not be open to everyone yet
+	if !h.registrationOpen(w, r, projectID, strings.TrimSpace(reg.AccessCode), groupReservationID != nil) {
+		return
+	}
+
 	// if err := services.CreateAssessment(h.Config, reg.Recaptcha); err != nil {
 	// 	middleware.RespondWithError(w, http.StatusBadRequest, "Recaptcha validation failed")
 	// 	return
@@ -345,15 +515,38 @@ func (h *ProjectHandler) RegisterForProject(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	members, ok := selectHouseholdMembers(w, r, h.DB, userID, reg.MemberIDs)
+	if !ok {
+		return
+	}
+	reg.GuestCount = max(reg.GuestCount, len(members))
+
 	// Register for the project
 	registration, err := models.RegisterForProject(
-		h.DB, userID, projectID, reg.GuestCount, reg.IsLeadInterested,
+		ctx, h.DB, userID, projectID, reg.GuestCount, reg.IsLeadInterested, groupReservationID, members,
 	)
 	if err != nil {
 		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	recordAudit(
+		r, h.DB, auditActor(r, reg.Email), models.AuditActionCreate, models.AuditEntityRegistration, registration.ID,
+		nil, registration,
+	)
+	// members' details are only shown on the signed-in household routes
+	response := *registration
+	response.Members = nil
+
+	// Offer or request a ride; the registration stands even if the carpool cannot be saved
+	if reg.Carpool != nil {
+		if err = models.SaveCarpool(ctx, h.DB, reg.Carpool.carpool(*registration)); err != nil {
+			log.Println("error saving carpool with registration: ", err)
+		} else {
+			h.matchCarpools(ctx, projectID)
+		}
+	}
+
 	// Get project details for email
 	project, err := models.GetProjectByID(ctx, h.DB, projectID)
 	if err != nil {
@@ -365,13 +558,17 @@ func (h *ProjectHandler) RegisterForProject(w http.ResponseWriter, r *http.Reque
 
 	// Send confirmation email
 	if project != nil {
-		go h.EmailService.SendRegistrationConfirmation(user, project, reg.GuestCount)
+		calendarURL, err := calendarFeedURL(ctx, h.DB, h.Config, user.ID)
+		if err != nil {
+			log.Println("error creating calendar feed: ", err)
+		}
+		go h.EmailService.SendRegistrationConfirmation(user, project, reg.GuestCount, calendarURL)
 		if user.TextPermission {
 			go h.TextService.SendRegistrationConfirmation(user, project)
 		}
 	}
 
-	middleware.RespondWithJSON(w, http.StatusCreated, registration)
+	middleware.RespondWithJSON(w, http.StatusCreated, response)
 }
 
 // CancelRegistration cancels a user's registration for a project
@@ -388,11 +585,34 @@ func (h *ProjectHandler) CancelRegistration(w http.ResponseWriter, r *http.Reque
 
 	// Get user ID from the token
 	user, err := models.GetUserByEmail(ctx, h.DB, email)
-	if err != nil {
+	if err != nil || user == nil {
 		middleware.RespondWithError(w, http.StatusUnauthorized, "Failed to get user information")
 		return
 	}
 
+	before, err := models.GetUserProjectRegistration(ctx, h.DB, user.ID, projectID)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve registration")
+		return
+	}
+	if before == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "No registration found for this project")
+		return
+	}
+
+	project, err := models.GetProjectByID(ctx, h.DB, projectID)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve project")
+		return
+	}
+	if project == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	if !deadlineAllows(w, r, h.DB, h.Config, project, models.Deadlines.CheckCancellation) {
+		return
+	}
+
 	// Cancel the registration
 	err = models.CancelRegistration(ctx, h.DB, user.ID, projectID)
 	if err != nil {
@@ -400,6 +620,13 @@ func (h *ProjectHandler) CancelRegistration(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	recordAudit(
+		r, h.DB, auditActor(r, email), models.AuditActionCancel, models.AuditEntityRegistration, before.ID, before, nil,
+	)
+
+	// riders that were driven by this volunteer need a new match
+	h.matchCarpools(ctx, projectID)
+
 	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Registration cancelled successfully"})
 }
 
@@ -416,7 +643,7 @@ func (h *ProjectHandler) GetTypes(w http.ResponseWriter, r *http.Request) {
 	middleware.RespondWithJSON(w, http.StatusOK, types)
 }
 
-// GetProjectRegistrations returns all registrations for a project (admin only)
+// GetProjectRegistrations returns all registrations for a project to admins and the project's leads
 func (h *ProjectHandler) GetProjectRegistrations(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	vars := mux.Vars(r)
@@ -426,6 +653,19 @@ func (h *ProjectHandler) GetProjectRegistrations(w http.ResponseWriter, r *http.
 		return
 	}
 
+	access, err := requestAccess(r, h.DB)
+	if err != nil {
+		log.Println("error checking permissions for project registrations: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve registrations")
+		return
+	}
+	if !access.CanOnProject(middleware.PermReadReports, projectID) {
+		middleware.RespondWithError(
+			w, http.StatusForbidden, "Forbidden: "+middleware.PermReadReports+" permission required",
+		)
+		return
+	}
+
 	registrations, err := models.GetProjectRegistrations(ctx, h.DB, projectID)
 	if err != nil {
 		log.Println("failed to get project registrations")
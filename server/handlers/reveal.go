@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"serve/middleware"
+	"serve/models"
+)
+
+// projectLocation is the location of a project as shown to one volunteer
+type projectLocation struct {
+	Address   string     `json:"address"`
+	Latitude  float64    `json:"latitude"`
+	Longitude float64    `json:"longitude"`
+	Private   bool       `json:"private"`
+	Revealed  bool       `json:"revealed"`
+	RevealAt  *time.Time `json:"reveal_at,omitempty"`
+}
+
+// GetProjectLocation returns the location of a project for the signed-in volunteer. Projects at private
+// homes show only their public area until the reveal time, after which registered volunteers and the
+// project's leads see the exact address. Everyone else only ever sees the area.
+func (h *ProjectHandler) GetProjectLocation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	project, err := models.GetProjectByID(ctx, h.DB, id)
+	if err != nil {
+		log.Println("error retrieving project location: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve project")
+		return
+	}
+	if project == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+
+	location := projectLocation{
+		Address:   project.LocationAddress,
+		Latitude:  project.Latitude,
+		Longitude: project.Longitude,
+		Private:   project.HasPrivateLocation(),
+	}
+	if !location.Private {
+		middleware.RespondWithJSON(w, http.StatusOK, location)
+		return
+	}
+
+	allowed, err := h.canSeeExactLocation(r, project)
+	if err != nil {
+		log.Println("error checking project location access: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve project location")
+		return
+	}
+	if !allowed {
+		middleware.RespondWithError(
+			w, http.StatusForbidden, "The exact location is only shared with the project's registered volunteers",
+		)
+		return
+	}
+
+	revealAt := project.LocationRevealTime(h.Config.LocationRevealDays)
+	location.RevealAt = &revealAt
+	if project.LocationRevealed(time.Now(), h.Config.LocationRevealDays) {
+		location.Address, location.Latitude, location.Longitude =
+			project.ExactAddress, project.ExactLatitude, project.ExactLongitude
+		location.Revealed = true
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, location)
+}
+
+// canSeeExactLocation reports whether the signed-in user leads the project or is registered for it. Leads
+// are known by their user ID, a role for the project, or the verified email on their token; lead emails
+// alone are public, so an unverified one is not enough.
+func (h *ProjectHandler) canSeeExactLocation(r *http.Request, project *models.Project) (bool, error) {
+	subject, err := middleware.GetUserIDFromRequest(r)
+	if err != nil || subject == "" {
+		return false, nil
+	}
+	if project.ServeLeadID == subject {
+		return true, nil
+	}
+
+	access, err := requestAccess(r, h.DB)
+	if err != nil {
+		return false, err
+	}
+	if access.CanOnProject(middleware.PermReadReports, project.ID) {
+		return true, nil
+	}
+
+	if claims, err := middleware.GetUserFromRequest(r); err == nil && claims.EmailVerified &&
+		project.IsProjectLead(claims.Email) {
+		return true, nil
+	}
+
+	registration, err := models.GetUserRegistration(r.Context(), h.DB, subject)
+	if err != nil {
+		return false, err
+	}
+	return registration.ProjectID == project.ID && registration.Status == "registered", nil
+}
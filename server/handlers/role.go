@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"serve/middleware"
+	"serve/models"
+)
+
+// UserRoleInput assigns a role to an Auth0 user, for a single project when ProjectID is set
+type UserRoleInput struct {
+	Subject   string `json:"subject"`
+	Role      string `json:"role"`
+	ProjectID *int   `json:"project_id"`
+}
+
+// AccessLoader loads a user's grants from the roles tables, for AccessMiddleware
+func AccessLoader(db *sql.DB) middleware.AccessLoader {
+	return func(ctx context.Context, subject string, permissions []string) ([]middleware.Grant, error) {
+		return models.GetRoleGrants(ctx, db, subject, permissions)
+	}
+}
+
+// requestAccess works out what the user signed in on an optionally authenticated request may do. It is
+// nil for anonymous requests.
+func requestAccess(r *http.Request, db *sql.DB) (*middleware.Access, error) {
+	subject, err := middleware.GetUserIDFromRequest(r)
+	if err != nil {
+		return nil, nil
+	}
+	claims, err := middleware.GetUserFromRequest(r)
+	if err != nil {
+		return nil, nil
+	}
+
+	grants, err := AccessLoader(db)(r.Context(), subject, claims.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	return middleware.NewAccess(subject, grants), nil
+}
+
+// requestCan reports whether the request is signed in as a user with the permission. Routes using it are
+// optionally authenticated, so anonymous requests can't.
+func requestCan(r *http.Request, db *sql.DB, permission string) (bool, error) {
+	access, err := requestAccess(r, db)
+	return access.Can(permission), err
+}
+
+// GetAccess returns the signed-in user's roles and permissions, so the admin app can show only what
+// they are allowed to use
+func (h *AdminHandler) GetAccess(w http.ResponseWriter, r *http.Request) {
+	access := middleware.AccessFromContext(r.Context())
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]any{
+		"subject":             access.Subject,
+		"roles":               access.Roles(),
+		"permissions":         access.Permissions(),
+		"project_permissions": access.ProjectPermissions(),
+	})
+}
+
+// GetRoles returns every role with its permissions
+func (h *AdminHandler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := models.GetRoles(r.Context(), h.DB)
+	if err != nil {
+		log.Println("error retrieving roles: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve roles")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, roles)
+}
+
+// GetUserRoles returns the roles assigned to users. Supports filtering by subject.
+func (h *AdminHandler) GetUserRoles(w http.ResponseWriter, r *http.Request) {
+	userRoles, err := models.GetUserRoles(r.Context(), h.DB, r.URL.Query().Get("subject"))
+	if err != nil {
+		log.Println("error retrieving user roles: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve user roles")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, userRoles)
+}
+
+// AssignRole gives an Auth0 user a role
+func (h *AdminHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	var input UserRoleInput
+	if err := middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if strings.TrimSpace(input.Subject) == "" || strings.TrimSpace(input.Role) == "" {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Subject and role are required")
+		return
+	}
+
+	actor := auditActor(r, "")
+	userRole := &models.UserRole{
+		Subject:   input.Subject,
+		Role:      strings.TrimSpace(input.Role),
+		ProjectID: input.ProjectID,
+		GrantedBy: actor,
+	}
+	if err := models.AssignRole(r.Context(), h.DB, userRole); err != nil {
+		respondWithRoleError(w, err, "assign")
+		return
+	}
+
+	recordAudit(r, h.DB, actor, models.AuditActionCreate, models.AuditEntityUserRole, userRole.ID, nil, userRole)
+
+	middleware.RespondWithJSON(w, http.StatusCreated, userRole)
+}
+
+// RemoveUserRole takes a role away from a user
+func (h *AdminHandler) RemoveUserRole(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid user role ID")
+		return
+	}
+
+	userRole, err := models.RemoveUserRole(r.Context(), h.DB, id)
+	if err != nil {
+		respondWithRoleError(w, err, "remove")
+		return
+	}
+
+	recordAudit(r, h.DB, auditActor(r, ""), models.AuditActionDelete, models.AuditEntityUserRole, id, userRole, nil)
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Role removed successfully"})
+}
+
+// respondWithRoleError maps role assignment errors to responses
+func respondWithRoleError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		middleware.RespondWithError(w, http.StatusNotFound, "User role not found")
+	case errors.Is(err, models.ErrUnknownRole), errors.Is(err, models.ErrProjectRequired):
+		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrRoleAssigned):
+		middleware.RespondWithError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("failed to %s user role: %v", action, err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to "+action+" role")
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"serve/models"
+)
+
+// maxProjectPageSize is the largest page of projects a client may request
+const maxProjectPageSize = 100
+
+// parseProjectFilter builds a project search filter from the query parameters of GET /api/projects
+func parseProjectFilter(params url.Values) (models.ProjectFilter, error) {
+	f := models.ProjectFilter{
+		Query:  strings.TrimSpace(params.Get("q")),
+		Areas:  listParam(params, "area"),
+		Ages:   strings.TrimSpace(params.Get("ages")),
+		Sort:   params.Get("sort"),
+		Cursor: params.Get("cursor"),
+	}
+
+	for _, value := range listParam(params, "types") {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return f, fmt.Errorf("invalid type ID %q", value)
+		}
+		f.TypeIDs = append(f.TypeIDs, id)
+	}
+
+	for _, status := range listParam(params, "status") {
+		if !models.IsValidStatus(status) {
+			return f, fmt.Errorf("invalid status %q", status)
+		}
+		f.Statuses = append(f.Statuses, status)
+	}
+
+	if value := params.Get("has_capacity"); value != "" {
+		hasCapacity, err := strconv.ParseBool(value)
+		if err != nil {
+			return f, fmt.Errorf("invalid has_capacity %q", value)
+		}
+		f.HasCapacity = hasCapacity
+	}
+
+	if near := params.Get("near"); near != "" {
+		location, err := parseLocation(near)
+		if err != nil {
+			return f, err
+		}
+		f.Near = location
+	}
+
+	if value := params.Get("radius_km"); value != "" {
+		radius, err := strconv.ParseFloat(value, 64)
+		if err != nil || radius <= 0 {
+			return f, fmt.Errorf("invalid radius_km %q", value)
+		}
+		f.RadiusKm = radius
+	}
+
+	var err error
+	if f.MinOpenSpots, err = intParam(params, "min_spots", 0); err != nil {
+		return f, err
+	}
+	if f.Limit, err = intParam(params, "limit", 0); err != nil {
+		return f, err
+	}
+	if f.Limit > maxProjectPageSize {
+		return f, fmt.Errorf("limit must not exceed %d", maxProjectPageSize)
+	}
+
+	// date selects a single day; date_from and date_to select an inclusive range of days
+	from, to := params.Get("date_from"), params.Get("date_to")
+	if date := params.Get("date"); date != "" {
+		from, to = date, date
+	}
+	if from != "" {
+		day, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			return f, fmt.Errorf("invalid date %q, use YYYY-MM-DD", from)
+		}
+		f.From = &day
+	}
+	if to != "" {
+		day, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			return f, fmt.Errorf("invalid date %q, use YYYY-MM-DD", to)
+		}
+		end := day.AddDate(0, 0, 1)
+		f.To = &end
+	}
+
+	return f, nil
+}
+
+// parseLocation parses a "lat,lng" pair
+func parseLocation(value string) (*models.Location, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid location %q, use lat,lng", value)
+	}
+
+	lat, latErr := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lng, lngErr := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if latErr != nil || lngErr != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, fmt.Errorf("invalid location %q, use lat,lng", value)
+	}
+
+	return &models.Location{Latitude: lat, Longitude: lng}, nil
+}
+
+// listParam returns the values of a query parameter that may be repeated or comma-separated
+func listParam(params url.Values, name string) []string {
+	var values []string
+	for _, param := range params[name] {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// intParam returns the value of a non-negative integer query parameter, or def when it is absent
+func intParam(params url.Values, name string, def int) (int, error) {
+	value := params.Get(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return n, nil
+}
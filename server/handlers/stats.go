@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"serve/middleware"
+	"serve/models"
+)
+
+// statsCacheTTL is how long computed dashboard statistics are served before being recomputed
+const statsCacheTTL = time.Minute
+
+// statsCache holds the most recently computed dashboard statistics
+type statsCache struct {
+	mu      sync.Mutex
+	stats   *models.Stats
+	expires time.Time
+}
+
+// GetStats returns the aggregated admin dashboard statistics. Results are cached for a short
+// TTL; pass ?refresh=true to force a recomputation.
+func (h *AdminHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats := h.stats.get()
+	if stats == nil || r.URL.Query().Get("refresh") == "true" {
+		var err error
+		stats, err = models.GetStats(r.Context(), h.DB)
+		if err != nil {
+			log.Println("error computing stats: ", err)
+			middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve statistics")
+			return
+		}
+		h.stats.set(stats)
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(statsCacheTTL.Seconds())))
+	middleware.RespondWithJSON(w, http.StatusOK, stats)
+}
+
+// get returns the cached statistics, or nil once they have expired
+func (c *statsCache) get() *models.Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stats == nil || time.Now().After(c.expires) {
+		return nil
+	}
+	return c.stats
+}
+
+// set caches newly computed statistics, keeping whichever were generated last
+func (c *statsCache) set(stats *models.Stats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stats != nil && c.stats.GeneratedAt.After(stats.GeneratedAt) {
+		return
+	}
+	c.stats = stats
+	c.expires = stats.GeneratedAt.Add(statsCacheTTL)
+}
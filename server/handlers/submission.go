@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"serve/config"
+	"serve/middleware"
+	"serve/models"
+	"serve/services"
+)
+
+// SubmissionHandler handles project submissions from partner organizations
+type SubmissionHandler struct {
+	DB           *sql.DB
+	Config       *config.Config
+	EmailService *services.EmailService
+}
+
+// SubmissionInput represents a partner organization's proposed project
+type SubmissionInput struct {
+	Organization    string  `json:"organization"`
+	ContactName     string  `json:"contact_name"`
+	ContactEmail    string  `json:"contact_email"`
+	ContactPhone    string  `json:"contact_phone"`
+	Title           string  `json:"title"`
+	Description     string  `json:"description"`
+	Needs           string  `json:"needs"`
+	MaxCapacity     int     `json:"max_capacity"`
+	Ages            string  `json:"ages"`
+	Time            string  `json:"time"`
+	Website         string  `json:"website"`
+	Area            string  `json:"area"`
+	LocationAddress string  `json:"location_address"`
+	Latitude        float64 `json:"latitude"`
+	Longitude       float64 `json:"longitude"`
+	Types           []int   `json:"types,omitempty"`
+	Recaptcha       string  `json:"recaptcha"`
+}
+
+// RegisterSubmissionRoutes registers the public routes for partner project submissions
+func RegisterSubmissionRoutes(
+	router *mux.Router, db *sql.DB, cfg *config.Config, emailService *services.EmailService,
+) {
+	handler := &SubmissionHandler{
+		DB:           db,
+		Config:       cfg,
+		EmailService: emailService,
+	}
+
+	router.HandleFunc("", handler.CreateSubmission).Methods(http.MethodPost)
+	router.HandleFunc("/{token:[0-9a-f]+}", handler.GetSubmission).Methods(http.MethodGet)
+	router.HandleFunc("/{token:[0-9a-f]+}", handler.UpdateSubmission).Methods(http.MethodPut)
+}
+
+// validate checks the required fields of a submission
+func (in *SubmissionInput) validate() string {
+	if strings.TrimSpace(in.Organization) == "" || strings.TrimSpace(in.ContactName) == "" ||
+		strings.TrimSpace(in.ContactEmail) == "" {
+		return "Organization, contact name and contact email are required"
+	}
+	if strings.TrimSpace(in.Title) == "" || strings.TrimSpace(in.Description) == "" || in.MaxCapacity <= 0 {
+		return "Title and description are required and the number of volunteers needed must be greater than 0"
+	}
+	return ""
+}
+
+// apply copies the submitted fields onto the submission and its project
+func (in *SubmissionInput) apply(s *models.Submission) {
+	s.Organization = strings.TrimSpace(in.Organization)
+	s.ContactName = strings.TrimSpace(in.ContactName)
+	s.ContactEmail = strings.TrimSpace(in.ContactEmail)
+	s.ContactPhone = strings.TrimSpace(in.ContactPhone)
+	s.Needs = in.Needs
+
+	p := s.Project
+	p.Title = strings.TrimSpace(in.Title)
+	p.Description = in.Description
+	p.MaxCapacity = in.MaxCapacity
+	p.Ages = in.Ages
+	p.Time = in.Time
+	if strings.TrimSpace(p.Time) == "" {
+		p.Time = "TBD"
+	}
+	p.Website = in.Website
+	p.Area = in.Area
+	p.LocationAddress = in.LocationAddress
+	p.Latitude = in.Latitude
+	p.Longitude = in.Longitude
+
+	p.Types = nil
+	for _, id := range in.Types {
+		p.Types = append(p.Types, models.ProjectAccessory{ID: id})
+	}
+}
+
+// CreateSubmission accepts a proposed project from a partner organization. The project is created
+// pending admin review and the partner is emailed a private tracking link.
+func (h *SubmissionHandler) CreateSubmission(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input SubmissionInput
+	if err := middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if msg := input.validate(); msg != "" {
+		middleware.RespondWithError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	// reCAPTCHA can only be left unconfigured in dev mode; anywhere else this endpoint stays closed without it
+	switch {
+	case h.Config.RecaptchaKey != "":
+		if err := services.CreateAssessment(h.Config, input.Recaptcha); err != nil {
+			middleware.RespondWithError(w, http.StatusBadRequest, "Recaptcha validation failed")
+			return
+		}
+	case !h.Config.DevMode:
+		log.Println("refusing project submission: reCAPTCHA is not configured")
+		middleware.RespondWithError(w, http.StatusServiceUnavailable, "Project submissions are not configured")
+		return
+	}
+
+	projectDate, err := h.Config.ServeDayDate()
+	if err != nil {
+		log.Println("error determining serve day: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to create submission")
+		return
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		log.Println("error generating submission token: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to create submission")
+		return
+	}
+
+	submission := &models.Submission{
+		Token:   token,
+		Project: &models.Project{ProjectDate: projectDate},
+	}
+	input.apply(submission)
+
+	if err = models.CreateSubmission(ctx, h.DB, submission); err != nil {
+		log.Println("error creating submission: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to create submission")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, auditActor(r, submission.ContactEmail), models.AuditActionCreate, models.AuditEntityProject,
+		submission.ProjectID, nil, submission,
+	)
+
+	trackingURL := h.trackingURL(token)
+	go h.EmailService.SendSubmissionReceived(submission, trackingURL)
+
+	middleware.RespondWithJSON(
+		w, http.StatusCreated, map[string]any{
+			"submission":   submission,
+			"token":        token,
+			"tracking_url": trackingURL,
+		},
+	)
+}
+
+// GetSubmission returns a submission and its review status by tracking token
+func (h *SubmissionHandler) GetSubmission(w http.ResponseWriter, r *http.Request) {
+	submission, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, submission)
+}
+
+// UpdateSubmission lets a partner edit their submission until it is approved
+func (h *SubmissionHandler) UpdateSubmission(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	submission, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	if !submission.Editable {
+		middleware.RespondWithError(w, http.StatusConflict, "Submission can no longer be edited")
+		return
+	}
+
+	var input SubmissionInput
+	if err := middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if msg := input.validate(); msg != "" {
+		middleware.RespondWithError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	before := *submission
+	beforeProject := *submission.Project
+	before.Project = &beforeProject
+
+	input.apply(submission)
+
+	if err := models.UpdateSubmission(ctx, h.DB, submission); err != nil {
+		if errors.Is(err, models.ErrSubmissionLocked) {
+			middleware.RespondWithError(w, http.StatusConflict, "Submission can no longer be edited")
+			return
+		}
+		log.Println("error updating submission: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to update submission")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, auditActor(r, submission.ContactEmail), models.AuditActionUpdate, models.AuditEntityProject,
+		submission.ProjectID, before, submission,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusOK, submission)
+}
+
+// lookup loads the submission for the token in the URL, responding with an error if there is none
+func (h *SubmissionHandler) lookup(w http.ResponseWriter, r *http.Request) (*models.Submission, bool) {
+	submission, err := models.GetSubmissionByToken(r.Context(), h.DB, mux.Vars(r)["token"])
+	if err != nil {
+		log.Println("error retrieving submission: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve submission")
+		return nil, false
+	}
+
+	if submission == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Submission not found")
+		return nil, false
+	}
+
+	return submission, true
+}
+
+// trackingURL builds the link a partner uses to follow and edit their submission
+func (h *SubmissionHandler) trackingURL(token string) string {
+	return h.Config.AppURL + "/submissions/" + token
+}
+
+// GetSubmissions returns every partner submission for admin review
+func (h *AdminHandler) GetSubmissions(w http.ResponseWriter, r *http.Request) {
+	submissions, err := models.GetSubmissions(r.Context(), h.DB)
+	if err != nil {
+		log.Println("error retrieving submissions: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve submissions")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, submissions)
+}
+
+// generateToken returns a random, URL-safe hex token
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"serve/middleware"
+	"serve/models"
+)
+
+// TemplateInput represents the input for creating or updating a project template
+type TemplateInput struct {
+	Name            string  `json:"name"`
+	OrganizationID  *int    `json:"organization_id"`
+	Title           string  `json:"title"`
+	Description     string  `json:"description"`
+	Website         string  `json:"website"`
+	Time            string  `json:"time"`
+	MaxCapacity     int     `json:"max_capacity"`
+	Area            string  `json:"area"`
+	LocationAddress string  `json:"location_address"`
+	Latitude        float64 `json:"latitude"`
+	Longitude       float64 `json:"longitude"`
+	Ages            string  `json:"ages"`
+	ServeLeadName   string  `json:"serve_lead_name"`
+	ServeLeadEmail  string  `json:"serve_lead_email"`
+	Leads           []Lead  `json:"leads"`
+	Types           []int   `json:"types"`
+}
+
+// cloneRequest defines the JSON request for cloning projects onto a new date
+type cloneRequest struct {
+	ProjectIDs  []int  `json:"project_ids"`
+	ProjectDate string `json:"project_date"`
+}
+
+// validate checks the required fields of a template
+func (in *TemplateInput) validate() string {
+	if strings.TrimSpace(in.Name) == "" || strings.TrimSpace(in.Title) == "" ||
+		strings.TrimSpace(in.Description) == "" || in.MaxCapacity <= 0 {
+		return "Name, title and description are required and max capacity must be greater than 0"
+	}
+	return ""
+}
+
+// apply copies the input fields onto the template
+func (in *TemplateInput) apply(t *models.ProjectTemplate) error {
+	t.Name = strings.TrimSpace(in.Name)
+	t.OrganizationID = in.OrganizationID
+	t.Title = strings.TrimSpace(in.Title)
+	t.Description = in.Description
+	t.Website = in.Website
+	t.Time = in.Time
+	if strings.TrimSpace(t.Time) == "" {
+		t.Time = "TBD"
+	}
+	t.MaxCapacity = in.MaxCapacity
+	t.Area = in.Area
+	t.LocationAddress = in.LocationAddress
+	t.Latitude = in.Latitude
+	t.Longitude = in.Longitude
+	t.Ages = in.Ages
+	t.ServeLeadName = in.ServeLeadName
+	t.ServeLeadEmail = in.ServeLeadEmail
+
+	t.Types = nil
+	for _, id := range in.Types {
+		t.Types = append(t.Types, models.ProjectAccessory{ID: id})
+	}
+
+	leads := in.Leads
+	if leads == nil {
+		leads = []Lead{}
+	}
+	var err error
+	t.Leads, err = json.Marshal(leads)
+	return err
+}
+
+// GetTemplates returns all project templates
+func (h *AdminHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := models.GetAllTemplates(r.Context(), h.DB)
+	if err != nil {
+		log.Println("error retrieving templates: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve templates")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, templates)
+}
+
+// GetTemplate returns a single project template
+func (h *AdminHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	template, ok := h.lookupTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, template)
+}
+
+// CreateTemplate creates a new project template
+func (h *AdminHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var input TemplateInput
+	if err := middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if msg := input.validate(); msg != "" {
+		middleware.RespondWithError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	template := &models.ProjectTemplate{}
+	if err := input.apply(template); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid leads")
+		return
+	}
+
+	if err := models.CreateTemplate(r.Context(), h.DB, template); err != nil {
+		respondWithTemplateError(w, err, "create")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, auditActor(r, ""), models.AuditActionCreate, models.AuditEntityTemplate, template.ID, nil, template,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusCreated, template)
+}
+
+// UpdateTemplate updates an existing project template
+func (h *AdminHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	template, ok := h.lookupTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	var input TemplateInput
+	if err := middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if msg := input.validate(); msg != "" {
+		middleware.RespondWithError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	before := *template
+	if err := input.apply(template); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid leads")
+		return
+	}
+
+	if err := models.UpdateTemplate(r.Context(), h.DB, template); err != nil {
+		respondWithTemplateError(w, err, "update")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, auditActor(r, ""), models.AuditActionUpdate, models.AuditEntityTemplate, template.ID, before,
+		template,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusOK, template)
+}
+
+// DeleteTemplate deletes a project template
+func (h *AdminHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	template, ok := h.lookupTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	if err := models.DeleteTemplate(r.Context(), h.DB, template.ID); err != nil {
+		respondWithTemplateError(w, err, "delete")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, auditActor(r, ""), models.AuditActionDelete, models.AuditEntityTemplate, template.ID, template, nil,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Template deleted successfully"})
+}
+
+// CreateTemplateFromProject saves an existing project as a reusable template
+func (h *AdminHandler) CreateTemplateFromProject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	var input struct {
+		Name string `json:"name"`
+	}
+	if err = middleware.ParseJSON(r, &input); err != nil || strings.TrimSpace(input.Name) == "" {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Template name is required")
+		return
+	}
+
+	id, err := models.CreateTemplateFromProject(ctx, h.DB, projectID, strings.TrimSpace(input.Name))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			middleware.RespondWithError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		respondWithTemplateError(w, err, "create")
+		return
+	}
+
+	template, err := models.GetTemplateByID(ctx, h.DB, id)
+	if err != nil || template == nil {
+		log.Println("error retrieving created template: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve template")
+		return
+	}
+
+	recordAudit(r, h.DB, auditActor(r, ""), models.AuditActionCreate, models.AuditEntityTemplate, id, nil, template)
+
+	middleware.RespondWithJSON(w, http.StatusCreated, template)
+}
+
+// CreateProjectFromTemplate creates a pending project on the requested date from a template
+func (h *AdminHandler) CreateProjectFromTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	templateID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	var input struct {
+		ProjectDate string `json:"project_date"`
+	}
+	if err = middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	projectDate, err := parseProjectDate(input.ProjectDate)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid project date format")
+		return
+	}
+
+	id, err := models.CreateProjectFromTemplate(ctx, h.DB, templateID, projectDate)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			middleware.RespondWithError(w, http.StatusNotFound, "Template not found")
+			return
+		}
+		log.Println("error creating project from template: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to create project")
+		return
+	}
+
+	projects, ok := h.createdProjects(w, r, []int{id})
+	if !ok {
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusCreated, projects[0])
+}
+
+// CloneProjects copies one or many projects onto a new date, for the next event. The copies keep
+// their types and leads but start pending with no registrations.
+func (h *AdminHandler) CloneProjects(w http.ResponseWriter, r *http.Request) {
+	var input cloneRequest
+	if err := middleware.ParseJSON(r, &input); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if len(input.ProjectIDs) == 0 {
+		middleware.RespondWithError(w, http.StatusBadRequest, "At least one project ID is required")
+		return
+	}
+
+	projectDate, err := parseProjectDate(input.ProjectDate)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid project date format")
+		return
+	}
+
+	ids, err := models.CloneProjects(r.Context(), h.DB, input.ProjectIDs, projectDate)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			middleware.RespondWithError(w, http.StatusNotFound, "One or more projects not found")
+			return
+		}
+		log.Println("error cloning projects: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to clone projects")
+		return
+	}
+
+	projects, ok := h.createdProjects(w, r, ids)
+	if !ok {
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusCreated, projects)
+}
+
+// createdProjects loads newly created projects and records their creation in the audit log
+func (h *AdminHandler) createdProjects(w http.ResponseWriter, r *http.Request, ids []int) ([]*models.Project, bool) {
+	projects := make([]*models.Project, 0, len(ids))
+	for _, id := range ids {
+		project, err := models.GetProjectByID(r.Context(), h.DB, id)
+		if err != nil || project == nil {
+			log.Println("error retrieving created project: ", err)
+			middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve created project")
+			return nil, false
+		}
+
+		recordAudit(r, h.DB, auditActor(r, ""), models.AuditActionCreate, models.AuditEntityProject, id, nil, project)
+		projects = append(projects, project)
+	}
+
+	return projects, true
+}
+
+// lookupTemplate loads the template for the ID in the URL, responding with an error if there is none
+func (h *AdminHandler) lookupTemplate(w http.ResponseWriter, r *http.Request) (*models.ProjectTemplate, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid template ID")
+		return nil, false
+	}
+
+	template, err := models.GetTemplateByID(r.Context(), h.DB, id)
+	if err != nil {
+		log.Println("error retrieving template: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve template")
+		return nil, false
+	}
+
+	if template == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Template not found")
+		return nil, false
+	}
+
+	return template, true
+}
+
+// respondWithTemplateError maps a template write error to the matching HTTP status
+func respondWithTemplateError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		middleware.RespondWithError(w, http.StatusNotFound, "Template not found")
+	case errors.Is(err, models.ErrTemplateExists):
+		middleware.RespondWithError(w, http.StatusConflict, "A template with this name already exists")
+	default:
+		log.Printf("failed to %s template: %v", action, err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to "+action+" template")
+	}
+}
+
+// parseProjectDate parses an RFC3339 project date, moving dates without a time to the 8am start
+func parseProjectDate(value string) (time.Time, error) {
+	projectDate, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if projectDate.Hour() < 8 {
+		projectDate = projectDate.Add(time.Hour * 8)
+	}
+	return projectDate, nil
+}
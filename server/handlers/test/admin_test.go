@@ -0,0 +1,53 @@
+package project_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"serve/testutils"
+)
+
+func TestAdminAccess(t *testing.T) {
+	ts := testutils.NewTestServer()
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"no token", http.MethodGet, "/api/admin/stats", "", http.StatusBadRequest},
+		{"no permissions", http.MethodGet, "/api/admin/stats", ts.Token("local|nobody"), http.StatusForbidden},
+		{
+			"viewer reads reports", http.MethodGet, "/api/admin/stats",
+			ts.Token("local|viewer", "role:viewer"), http.StatusOK,
+		},
+		{
+			"viewer cannot send broadcasts", http.MethodPost, "/api/admin/send-thank-you-emails",
+			ts.Token("local|viewer", "role:viewer"), http.StatusForbidden,
+		},
+		{
+			"admin reads access", http.MethodGet, "/api/admin/access",
+			ts.Token("local|admin", "edit:projects"), http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req, err := ts.NewRequest(tt.method, tt.path, tt.token, nil)
+				require.NoError(t, err)
+
+				resp, err := http.DefaultClient.Do(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+
+				assert.Equal(t, tt.want, resp.StatusCode)
+			},
+		)
+	}
+}
@@ -0,0 +1,74 @@
+package project_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"serve/middleware"
+	"serve/models"
+	"serve/testutils"
+)
+
+func TestGetUserProfileLinksRegistrations(t *testing.T) {
+	ts := testutils.NewTestServer()
+	defer ts.Close()
+	defer ts.DB.Exec(`DELETE FROM users WHERE lower(email) = 'volunteer@example.com' OR id LIKE 'google-oauth2|%'`)
+	defer testutils.CleanTestData(ts.DB)
+
+	projectID, err := testutils.CreateTestProject(ts.DB)
+	require.NoError(t, err)
+	emailUserID, err := testutils.CreateTestRegistration(ts.DB, projectID, "volunteer@example.com")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		subject  string
+		verified bool
+		linked   bool
+		status   int
+	}{
+		{"unverified email is not linked", "google-oauth2|unverified", false, false, http.StatusOK},
+		{"verified email is linked", "google-oauth2|verified", true, true, http.StatusOK},
+		{"email held by another account conflicts", "google-oauth2|second", true, true, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				token := ts.TokenWithClaims(
+					tt.subject, middleware.CustomClaims{
+						Email: "Volunteer@example.com", EmailVerified: tt.verified, GivenName: "Pat",
+					},
+				)
+				req, err := ts.NewRequest(http.MethodGet, "/api/users/profile", token, nil)
+				require.NoError(t, err)
+
+				resp, err := http.DefaultClient.Do(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				require.Equal(t, tt.status, resp.StatusCode)
+				if tt.status != http.StatusOK {
+					return
+				}
+
+				var user models.User
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+				assert.Equal(t, tt.subject, user.ID)
+				assert.Equal(t, "Pat", user.FirstName)
+
+				var owner string
+				row := ts.DB.QueryRow(`SELECT user_id FROM registrations WHERE project_id = $1`, projectID)
+				require.NoError(t, row.Scan(&owner))
+				if tt.linked {
+					assert.Equal(t, tt.subject, owner)
+					assert.Equal(t, "Volunteer@example.com", user.Email)
+				} else {
+					assert.Equal(t, emailUserID, owner)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"serve/middleware"
+	"serve/models"
+)
+
+// GetTrash returns all soft deleted projects, registrations and users
+func (h *AdminHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	trash, err := models.GetTrash(r.Context(), h.DB)
+	if err != nil {
+		log.Println("error retrieving trash: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve deleted records")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, trash)
+}
+
+// RestoreProject restores a soft deleted project
+func (h *AdminHandler) RestoreProject(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	if err = models.RestoreProject(r.Context(), h.DB, id); err != nil {
+		respondWithRestoreError(w, err, "Project")
+		return
+	}
+
+	recordAudit(r, h.DB, auditActor(r, ""), models.AuditActionRestore, models.AuditEntityProject, id, nil, nil)
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Project restored successfully"})
+}
+
+// RestoreRegistration restores a soft deleted or cancelled registration
+func (h *AdminHandler) RestoreRegistration(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid registration ID")
+		return
+	}
+
+	if err = models.RestoreRegistration(r.Context(), h.DB, id); err != nil {
+		respondWithRestoreError(w, err, "Registration")
+		return
+	}
+
+	recordAudit(r, h.DB, auditActor(r, ""), models.AuditActionRestore, models.AuditEntityRegistration, id, nil, nil)
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Registration restored successfully"})
+}
+
+// DeleteUser soft deletes a user and their registrations
+func (h *AdminHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+
+	user, err := models.GetUserByID(ctx, h.DB, id)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve user")
+		return
+	}
+
+	if user == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if err = models.DeleteUser(ctx, h.DB, id); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			middleware.RespondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		log.Println("error deleting user: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to delete user")
+		return
+	}
+
+	recordAudit(r, h.DB, auditActor(r, ""), models.AuditActionDelete, models.AuditEntityUser, id, user, nil)
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "User deleted successfully"})
+}
+
+// RestoreUser restores a soft deleted user
+func (h *AdminHandler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := models.RestoreUser(r.Context(), h.DB, id); err != nil {
+		respondWithRestoreError(w, err, "User")
+		return
+	}
+
+	recordAudit(r, h.DB, auditActor(r, ""), models.AuditActionRestore, models.AuditEntityUser, id, nil, nil)
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "User restored successfully"})
+}
+
+// respondWithRestoreError maps a restore error to the matching HTTP status
+func respondWithRestoreError(w http.ResponseWriter, err error, entity string) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		middleware.RespondWithError(w, http.StatusNotFound, entity+" not found in trash")
+	case errors.Is(err, models.ErrRestoreConflict):
+		middleware.RespondWithError(w, http.StatusConflict, entity+" conflicts with an active record")
+	case errors.Is(err, models.ErrProjectNotOpen), errors.Is(err, models.ErrNoCapacity):
+		middleware.RespondWithError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("error restoring %s: %v", entity, err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to restore "+entity)
+	}
+}
@@ -3,11 +3,13 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
+	"serve/config"
 	"serve/middleware"
 	"serve/models"
 	"serve/services"
@@ -17,22 +19,41 @@ import (
 type UserHandler struct {
 	DB           *sql.DB
 	EmailService *services.EmailService
+	Config       *config.Config
 }
 
-// RegisterUserRoutes registers the routes for user handlers
-func RegisterUserRoutes(router *mux.Router, db *sql.DB, emailService *services.EmailService) {
+// RegisterUserRoutes registers the routes for user handlers. Profile, household and calendar routes need
+// a signed-in user and are wrapped with auth; the rest identify the volunteer by email.
+func RegisterUserRoutes(
+	router *mux.Router, db *sql.DB, cfg *config.Config, emailService *services.EmailService,
+	auth func(http.Handler) http.Handler,
+) {
 	handler := &UserHandler{
 		DB:           db,
 		EmailService: emailService,
-	}
-
-	router.HandleFunc("/profile", handler.GetUserProfile).Methods("GET")
-	router.HandleFunc("/profile", handler.UpdateUserProfile).Methods("PUT")
+		Config:       cfg,
+	}
+
+	router.Handle("/profile", auth(http.HandlerFunc(handler.GetUserProfile))).Methods("GET")
+	router.Handle("/profile", auth(http.HandlerFunc(handler.UpdateUserProfile))).Methods("PUT")
+	router.Handle("/household", auth(http.HandlerFunc(handler.GetHousehold))).Methods(http.MethodGet)
+	router.Handle("/household", auth(http.HandlerFunc(handler.SaveHousehold))).Methods(http.MethodPut)
+	router.Handle("/household/members", auth(http.HandlerFunc(handler.CreateHouseholdMember))).Methods(http.MethodPost)
+	router.Handle(
+		"/household/members/{id:[0-9]+}", auth(http.HandlerFunc(handler.UpdateHouseholdMember)),
+	).Methods(http.MethodPut)
+	router.Handle(
+		"/household/members/{id:[0-9]+}", auth(http.HandlerFunc(handler.DeleteHouseholdMember)),
+	).Methods(http.MethodDelete)
+	router.Handle("/household/history", auth(http.HandlerFunc(handler.GetHouseholdHistory))).Methods(http.MethodGet)
 	router.HandleFunc("/registrations", handler.GetUserRegistrations).Methods("GET")
 	router.HandleFunc("/registrations/{id:[0-9]+}", handler.UpdateRegistrationGuestCount).Methods(http.MethodPut)
+	router.Handle("/calendar", auth(http.HandlerFunc(handler.GetCalendarFeed))).Methods(http.MethodGet)
+	router.Handle("/calendar/reset", auth(http.HandlerFunc(handler.ResetCalendarFeed))).Methods(http.MethodPost)
 }
 
-// GetUserProfile returns the profile of the authenticated user
+// GetUserProfile returns the profile of the authenticated user. The first time someone signs in with a
+// verified email they registered with, their earlier registrations are linked to the account.
 func (h *UserHandler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -44,50 +65,38 @@ func (h *UserHandler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user claims from token
-	_, err = middleware.GetUserFromRequest(r)
+	claims, err := middleware.GetUserFromRequest(r)
 	if err != nil {
 		middleware.RespondWithError(w, http.StatusUnauthorized, "Failed to get user information")
 		return
 	}
 
-	// Get or create user in database
-	user, err := models.GetUserByID(ctx, h.DB, userID)
+	// Get or create the user, merging in any user who registered with the same email
+	link, err := models.LinkAccount(
+		ctx, h.DB, userID, models.AccountClaims{
+			Email:         claims.Email,
+			EmailVerified: claims.EmailVerified,
+			FirstName:     claims.GivenName,
+			LastName:      claims.FamilyName,
+		},
+	)
+	if errors.Is(err, models.ErrEmailInUse) {
+		middleware.RespondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
+		log.Println("error linking user account: ", err)
 		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve user profile")
 		return
 	}
 
-	// If user doesn't exist, create a new one
-	if user == nil {
-		user = &models.User{
-			ID: userID,
-			// TODO: check for email address - now that we're down to google and apple, there should
-			// be an email ALWAYS
-		}
-
-		// Create the user in the database
-		err = models.CreateUser(ctx, h.DB, user)
-		if err != nil {
-			middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to create user profile")
-			return
-		}
-	} else {
-		// Update user information if it has changed
-		// if user.Email != claims.Email || user.Name != claims.Name || user.Picture != claims.Picture {
-		//         user.Email = claims.Email
-		//         user.Name = claims.Name
-		//         user.Picture = claims.Picture
-		//
-		//         err = models.UpdateUser(h.DB, user)
-		//         if err != nil {
-		//                 middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to update user profile")
-		//                 return
-		//         }
-		// }
-		// TODO: update this
+	if link.Merged != nil {
+		recordAudit(
+			r, h.DB, userID, models.AuditActionMerge, models.AuditEntityUser, link.Merged.ID, link.Merged, link.User,
+		)
 	}
 
-	middleware.RespondWithJSON(w, http.StatusOK, user)
+	middleware.RespondWithJSON(w, http.StatusOK, link.User)
 }
 
 // GetUserRegistrations returns all registrations for the authenticated user
@@ -136,11 +145,21 @@ func (h *UserHandler) UpdateUserProfile(w http.ResponseWriter, r *http.Request)
 	// Ensure the user can only update their own profile
 	user.ID = userID
 
+	before, err := models.GetUserByID(ctx, h.DB, userID)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve user profile")
+		return
+	}
+
 	if err = models.UpdateUser(ctx, h.DB, &user); err != nil {
 		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to update user profile")
 		return
 	}
 
+	recordAudit(
+		r, h.DB, auditActor(r, user.Email), models.AuditActionUpdate, models.AuditEntityUser, userID, before, user,
+	)
+
 	middleware.RespondWithJSON(w, http.StatusOK, user)
 }
 
@@ -169,7 +188,39 @@ func (h *UserHandler) UpdateRegistrationGuestCount(w http.ResponseWriter, r *htt
 		return
 	}
 
-	query := `UPDATE registrations SET guest_count = $1 WHERE id = $2`
+	before, err := models.GetRegistrationByID(r.Context(), h.DB, regID)
+	if err != nil {
+		log.Println("failed to retrieve registration for updating guest count: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve registration")
+		return
+	}
+
+	if before == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Registration not found")
+		return
+	}
+
+	// adding guests is registering them, dropping guests is cancelling
+	if input.GuestCount != before.GuestCount {
+		project, err := models.GetProjectByID(r.Context(), h.DB, before.ProjectID)
+		if err != nil || project == nil {
+			log.Println("failed to retrieve project for updating guest count: ", err)
+			middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve project")
+			return
+		}
+		check := models.Deadlines.CheckRegistration
+		if input.GuestCount < before.GuestCount {
+			check = models.Deadlines.CheckCancellation
+		}
+		if !deadlineAllows(w, r, h.DB, h.Config, project, check) {
+			return
+		}
+	}
+
+	query := `
+		UPDATE registrations SET guest_count = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND deleted_at IS NULL
+	`
 	result, err := h.DB.Exec(query, input.GuestCount, regID)
 	if err != nil {
 		log.Println("failed to update guest count: ", err)
@@ -184,5 +235,11 @@ func (h *UserHandler) UpdateRegistrationGuestCount(w http.ResponseWriter, r *htt
 		return
 	}
 
+	after := *before
+	after.GuestCount = input.GuestCount
+	recordAudit(
+		r, h.DB, auditActor(r, ""), models.AuditActionUpdate, models.AuditEntityRegistration, regID, before, after,
+	)
+
 	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Registration updated successfully"})
 }
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"serve/config"
+	"serve/middleware"
+	"serve/models"
+)
+
+// GetRegistrationWindows returns when each audience may register for the upcoming events. Access codes
+// are left out; group leaders get theirs from the church.
+func (h *ProjectHandler) GetRegistrationWindows(w http.ResponseWriter, r *http.Request) {
+	loc, err := h.Config.Location()
+	if err != nil {
+		log.Println("error loading timezone: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve registration windows")
+		return
+	}
+
+	windows, err := models.GetRegistrationWindows(r.Context(), h.DB, models.EventDate(time.Now(), loc))
+	if err != nil {
+		log.Println("error retrieving registration windows: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve registration windows")
+		return
+	}
+	for i := range windows {
+		windows[i].AccessCode = ""
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, windows)
+}
+
+// GetRegistrationWindows returns every registration window of the events on or after the from date,
+// today by default, with access codes
+func (h *AdminHandler) GetRegistrationWindows(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	if from == "" {
+		from = time.Now().Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", from); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "from must be a date like 2025-07-12")
+		return
+	}
+
+	windows, err := models.GetRegistrationWindows(r.Context(), h.DB, from)
+	if err != nil {
+		log.Println("error retrieving registration windows: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve registration windows")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, windows)
+}
+
+// SaveRegistrationWindow sets when an audience may register for an event
+func (h *AdminHandler) SaveRegistrationWindow(w http.ResponseWriter, r *http.Request) {
+	var window models.RegistrationWindow
+	if err := middleware.ParseJSON(r, &window); err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	existing, err := models.GetRegistrationWindow(r.Context(), h.DB, window.EventDate, window.Audience)
+	if err != nil {
+		log.Println("error retrieving registration window: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to save registration window")
+		return
+	}
+	action, before := models.AuditActionCreate, any(nil)
+	if existing != nil {
+		action, before = models.AuditActionUpdate, *existing
+	}
+
+	if err = models.SaveRegistrationWindow(r.Context(), h.DB, &window); err != nil {
+		if errors.Is(err, models.ErrInvalidWindow) {
+			middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Println("error saving registration window: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to save registration window")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, auditActor(r, ""), action, models.AuditEntityRegistrationWindow, window.ID, before, window,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusOK, window)
+}
+
+// DeleteRegistrationWindow removes a registration window
+func (h *AdminHandler) DeleteRegistrationWindow(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "Invalid registration window ID")
+		return
+	}
+
+	window, err := models.DeleteRegistrationWindow(r.Context(), h.DB, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			middleware.RespondWithError(w, http.StatusNotFound, "Registration window not found")
+			return
+		}
+		log.Println("error deleting registration window: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to delete registration window")
+		return
+	}
+
+	recordAudit(
+		r, h.DB, auditActor(r, ""), models.AuditActionDelete, models.AuditEntityRegistrationWindow, id, window, nil,
+	)
+
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Registration window deleted"})
+}
+
+// friendlyTimeLayout is how registration and deadline times are written in messages to volunteers
+const friendlyTimeLayout = "Monday, January 2 at 3:04 PM MST"
+
+// registrationOpen checks that the volunteer may register for the project now, responding with when
+// registration opens for them if not
+func (h *ProjectHandler) registrationOpen(
+	w http.ResponseWriter, r *http.Request, projectID int, accessCode string, invited bool,
+) bool {
+	project, err := models.GetProjectByID(r.Context(), h.DB, projectID)
+	if err != nil {
+		log.Println("error retrieving project for registration: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve project")
+		return false
+	}
+	if project == nil {
+		middleware.RespondWithError(w, http.StatusNotFound, "Project not found")
+		return false
+	}
+
+	access, err := registrationAccess(r, h.DB, h.Config, project, accessCode, invited)
+	if err != nil {
+		log.Println("error checking registration windows: ", err)
+		middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to check registration windows")
+		return false
+	}
+	if !access.Open {
+		// admins can register volunteers outside the windows and after the deadline
+		admin, err := requestCan(r, h.DB, middleware.PermManageProjects)
+		if err != nil {
+			log.Println("error checking permissions for registration: ", err)
+			middleware.RespondWithError(w, http.StatusInternalServerError, "Failed to check registration windows")
+			return false
+		}
+		if admin {
+			return true
+		}
+		middleware.RespondWithJSON(w, http.StatusForbidden, map[string]any{
+			"error":        access.Message,
+			"registration": access,
+		})
+		return false
+	}
+	return true
+}
+
+// registrationAccess works out whether the volunteer may register for the project now. Group leaders,
+// known by their role or the access code of their window, and members registering through a group's
+// invite get early access. Registration closes for everyone at the project's registration deadline.
+func registrationAccess(
+	r *http.Request, db *sql.DB, cfg *config.Config, project *models.Project, accessCode string, invited bool,
+) (models.RegistrationAccess, error) {
+	loc, err := cfg.Location()
+	if err != nil {
+		return models.RegistrationAccess{}, err
+	}
+
+	windows, err := models.GetEventWindows(r.Context(), db, models.EventDate(project.ProjectDate, loc))
+	if err != nil {
+		return models.RegistrationAccess{}, err
+	}
+
+	early := invited || accessCodeMatches(windows, accessCode)
+	if !early {
+		if early, err = requestCan(r, db, middleware.PermLeadGroups); err != nil {
+			return models.RegistrationAccess{}, err
+		}
+	}
+
+	deadlines, err := projectDeadlines(r.Context(), db, cfg, project)
+	if err != nil {
+		return models.RegistrationAccess{}, err
+	}
+
+	now := time.Now()
+	access := models.CheckRegistrationAccess(windows, project.EarlyAccess, early, now)
+	if access.Open && deadlines.CheckRegistration(now) != nil {
+		access.Open, access.Audience, access.ClosesAt = false, "", &deadlines.RegistrationClosesAt
+		access.Message = deadlineMessage(models.ErrRegistrationDeadline, deadlines, loc)
+	} else if access.Open && (access.ClosesAt == nil || deadlines.RegistrationClosesAt.Before(*access.ClosesAt)) {
+		access.ClosesAt = &deadlines.RegistrationClosesAt
+	}
+	if !access.Open && access.OpensAt != nil && access.OpensAt.After(now) {
+		access.Message = "Registration opens " + access.OpensAt.In(loc).Format(friendlyTimeLayout)
+	}
+	return access, nil
+}
+
+// accessCodeMatches reports whether the code is the access code of the event's group leaders window
+func accessCodeMatches(windows []models.RegistrationWindow, code string) bool {
+	if code == "" {
+		return false
+	}
+	for _, w := range windows {
+		if w.Audience == models.AudienceGroupLeaders && w.AccessCode != "" &&
+			subtle.ConstantTimeCompare([]byte(w.AccessCode), []byte(code)) == 1 {
+			return true
+		}
+	}
+	return false
+}
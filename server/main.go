@@ -16,8 +16,8 @@ import (
 	"golang.org/x/time/rate"
 	"serve/config"
 	"serve/database"
-	"serve/handlers"
 	"serve/middleware"
+	"serve/routes"
 	"serve/services"
 )
 
@@ -45,11 +45,11 @@ func main() {
 	emailService := services.NewEmailService(cfg)
 	textService := services.NewTextService(cfg)
 
-	// Initialize maps service
-	mapsService := services.NewMapsService()
+	// Initialize geocoder
+	geocoder := services.NewGeocoder(cfg, db)
 
 	// Initialize scheduler service
-	scheduler := services.NewScheduler(db, emailService, textService)
+	scheduler := services.NewScheduler(db, cfg, emailService, textService)
 	go scheduler.Start()
 	defer scheduler.Stop()
 
@@ -66,28 +66,27 @@ func main() {
 	r.Use(middleware.LoggerMiddleware)
 	r.Use(middleware.RateLimitMiddleware(rateLimiter))
 
-	// API routes (with auth)
-	api := r.PathPrefix("/api").Subrouter()
-
-	// User routes
-	userRouter := api.PathPrefix("/users").Subrouter()
-	handlers.RegisterUserRoutes(userRouter, db, emailService)
-
-	// Project routes
-	projectRouter := api.PathPrefix("/projects").Subrouter()
-	handlers.RegisterProjectRoutes(projectRouter, db, cfg, emailService, textService)
-
-	// Admin routes
-	adminRouter := api.PathPrefix("/admin").Subrouter()
-	adminRouter.Use(middleware.AuthMiddleware(cfg))
-	adminRouter.Use(middleware.AdminMiddleware)
-	handlers.RegisterAdminRoutes(adminRouter, db, emailService)
-
-	// Geocoding routes
-	geocodingHandler := &handlers.GeocodingHandler{
-		MapsService: mapsService,
+	// Set up token validation, signing tokens locally when running without Auth0
+	validateToken, localIssuer, err := routes.NewTokenValidator(cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up token validation: %v", err)
+	}
+	if localIssuer != nil {
+		log.Println("Using local auth: POST /api/auth/token mints development tokens")
 	}
-	api.HandleFunc("/geocode", geocodingHandler.GeocodeAddress).Methods("POST")
+
+	// API routes
+	routes.Register(
+		r, routes.Dependencies{
+			DB:            db,
+			Config:        cfg,
+			EmailService:  emailService,
+			TextService:   textService,
+			Geocoder:      geocoder,
+			ValidateToken: validateToken,
+			LocalIssuer:   localIssuer,
+		},
+	)
 
 	origin := "http://localhost:" + cfg.ServerPort
 	corsHandler := gorhandler.CORS(
@@ -96,6 +95,7 @@ func main() {
 		), // Allowed origins
 		gorhandler.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),           // Allowed methods
 		gorhandler.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"}), // Allowed headers
+		gorhandler.ExposedHeaders([]string{"X-Total-Count", "X-Next-Cursor"}),                    // Pagination headers
 		gorhandler.AllowCredentials(), // Allow credentials
 	)(r)
 
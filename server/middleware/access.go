@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/auth0/go-jwt-middleware/v2"
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/gorilla/mux"
+)
+
+// Permissions checked by admin and group leader routes. Roles grant them; see the roles and
+// role_permissions tables.
+const (
+	PermManageProjects = "projects:manage"
+	PermSendBroadcasts = "broadcasts:send"
+	PermReadReports    = "reports:read"
+	PermManageUsers    = "users:manage"
+	PermManageRoles    = "roles:manage"
+	PermLeadGroups     = "groups:lead"
+)
+
+// Grant is a permission given to a user through one of their roles. A grant with a project ID only
+// applies to that project.
+type Grant struct {
+	Role       string
+	Permission string
+	ProjectID  *int
+}
+
+// Access is what the signed-in user may do, worked out once per request from their grants
+type Access struct {
+	Subject     string
+	roles       map[string]bool
+	permissions map[string]bool
+	projects    map[int]map[string]bool
+}
+
+// NewAccess builds the access for a user from their grants
+func NewAccess(subject string, grants []Grant) *Access {
+	a := &Access{
+		Subject:     subject,
+		roles:       map[string]bool{},
+		permissions: map[string]bool{},
+		projects:    map[int]map[string]bool{},
+	}
+	for _, g := range grants {
+		a.roles[g.Role] = true
+		if g.ProjectID == nil {
+			a.permissions[g.Permission] = true
+			continue
+		}
+		if a.projects[*g.ProjectID] == nil {
+			a.projects[*g.ProjectID] = map[string]bool{}
+		}
+		a.projects[*g.ProjectID][g.Permission] = true
+	}
+	return a
+}
+
+// Can reports whether the user holds the permission everywhere
+func (a *Access) Can(permission string) bool {
+	return a != nil && a.permissions[permission]
+}
+
+// CanOnProject reports whether the user holds the permission for the project, either everywhere or
+// through a role given for that project
+func (a *Access) CanOnProject(permission string, projectID int) bool {
+	return a.Can(permission) || (a != nil && a.projects[projectID][permission])
+}
+
+// Empty reports whether the user holds no permissions at all
+func (a *Access) Empty() bool {
+	return a == nil || (len(a.permissions) == 0 && len(a.projects) == 0)
+}
+
+// Roles returns the names of the user's roles, sorted
+func (a *Access) Roles() []string {
+	return sortedKeys(a.roles)
+}
+
+// Permissions returns the permissions the user holds everywhere, sorted
+func (a *Access) Permissions() []string {
+	return sortedKeys(a.permissions)
+}
+
+// ProjectPermissions returns the permissions the user holds only for particular projects, sorted
+func (a *Access) ProjectPermissions() map[int][]string {
+	perms := make(map[int][]string, len(a.projects))
+	for id, p := range a.projects {
+		perms[id] = sortedKeys(p)
+	}
+	return perms
+}
+
+// sortedKeys returns the members of a set in order
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// accessKey is the context key the request's Access is stored under
+type accessKey struct{}
+
+// WithAccess returns a copy of ctx carrying the access
+func WithAccess(ctx context.Context, access *Access) context.Context {
+	return context.WithValue(ctx, accessKey{}, access)
+}
+
+// AccessFromContext returns the access stored by AccessMiddleware, or nil outside admin routes
+func AccessFromContext(ctx context.Context) *Access {
+	access, _ := ctx.Value(accessKey{}).(*Access)
+	return access
+}
+
+// AccessLoader looks up the grants of a user from their subject and the permissions on their token
+type AccessLoader func(ctx context.Context, subject string, permissions []string) ([]Grant, error)
+
+// AccessMiddleware works out what the signed-in user may do and stores it on the request context.
+// Users without any permission are turned away here; each route then checks for the permission it
+// needs with RequirePermission or RequireProjectPermission.
+func AccessMiddleware(load AccessLoader) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				claims, ok := r.Context().Value(jwtmiddleware.ContextKey{}).(*validator.ValidatedClaims)
+				if !ok {
+					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					return
+				}
+
+				var permissions []string
+				if customClaims, ok := claims.CustomClaims.(*CustomClaims); ok {
+					permissions = customClaims.Permissions
+				}
+
+				subject := claims.RegisteredClaims.Subject
+				grants, err := load(r.Context(), subject, permissions)
+				if err != nil {
+					log.Println("error loading access: ", err)
+					RespondWithError(w, http.StatusInternalServerError, "Failed to check permissions")
+					return
+				}
+
+				access := NewAccess(subject, grants)
+				if access.Empty() {
+					http.Error(w, "Forbidden: admin role required", http.StatusForbidden)
+					return
+				}
+
+				next.ServeHTTP(w, r.WithContext(WithAccess(r.Context(), access)))
+			},
+		)
+	}
+}
+
+// RequirePermission only lets the request through if the user holds the permission everywhere
+func RequirePermission(permission string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !AccessFromContext(r.Context()).Can(permission) {
+			RespondWithError(w, http.StatusForbidden, "Forbidden: "+permission+" permission required")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// RequireProjectPermission only lets the request through if the user holds the permission for the
+// project in the route's id variable
+func RequireProjectPermission(permission string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil || !AccessFromContext(r.Context()).CanOnProject(permission, projectID) {
+			RespondWithError(w, http.StatusForbidden, "Forbidden: "+permission+" permission required")
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,76 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"serve/middleware"
+)
+
+func TestAccess(t *testing.T) {
+	project := 7
+	access := middleware.NewAccess(
+		"auth0|lead", []middleware.Grant{
+			{Role: "communications", Permission: middleware.PermSendBroadcasts},
+			{Role: "project-lead", Permission: middleware.PermReadReports, ProjectID: &project},
+		},
+	)
+
+	assert.True(t, access.Can(middleware.PermSendBroadcasts))
+	assert.False(t, access.Can(middleware.PermReadReports))
+	assert.True(t, access.CanOnProject(middleware.PermReadReports, 7))
+	assert.False(t, access.CanOnProject(middleware.PermReadReports, 8))
+	assert.True(t, access.CanOnProject(middleware.PermSendBroadcasts, 8))
+	assert.Equal(t, []string{"communications", "project-lead"}, access.Roles())
+	assert.Equal(t, map[int][]string{7: {middleware.PermReadReports}}, access.ProjectPermissions())
+	assert.False(t, access.Empty())
+
+	var none *middleware.Access
+	assert.False(t, none.Can(middleware.PermReadReports))
+	assert.True(t, none.Empty())
+}
+
+func TestRequireProjectPermission(t *testing.T) {
+	project := 7
+	access := middleware.NewAccess(
+		"auth0|lead", []middleware.Grant{
+			{Role: "project-lead", Permission: middleware.PermReadReports, ProjectID: &project},
+		},
+	)
+
+	router := mux.NewRouter()
+	router.Use(
+		func(next http.Handler) http.Handler {
+			return http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					next.ServeHTTP(w, r.WithContext(middleware.WithAccess(r.Context(), access)))
+				},
+			)
+		},
+	)
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	router.HandleFunc("/projects/{id:[0-9]+}", middleware.RequireProjectPermission(middleware.PermReadReports, ok))
+	router.HandleFunc("/stats", middleware.RequirePermission(middleware.PermReadReports, ok))
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/projects/7", http.StatusOK},
+		{"/projects/8", http.StatusForbidden},
+		{"/stats", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.path, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+				assert.Equal(t, tt.want, rec.Code)
+			},
+		)
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"log"
 	"net/http"
 	"net/url"
-	"slices"
 	"strings"
 
 	"github.com/auth0/go-jwt-middleware/v2"
@@ -17,13 +16,15 @@ import (
 	"serve/config"
 )
 
-const (
-	adminProjects = "edit:projects"
-)
-
 // CustomClaims contains custom claims extended from the standard JWT claims
 type CustomClaims struct {
 	Permissions []string `json:"permissions"`
+	// Profile claims, added to access tokens by an Auth0 Action under our namespace and used to link the
+	// signed-in user to the volunteer who registered with the same email
+	Email         string `json:"https://serve.journeycolorado.com/email,omitempty"`
+	EmailVerified bool   `json:"https://serve.journeycolorado.com/email_verified,omitempty"`
+	GivenName     string `json:"https://serve.journeycolorado.com/given_name,omitempty"`
+	FamilyName    string `json:"https://serve.journeycolorado.com/family_name,omitempty"`
 }
 
 // Validate does custom validation for the token
@@ -31,14 +32,13 @@ func (c *CustomClaims) Validate(ctx context.Context) error {
 	return nil
 }
 
-// AuthMiddleware returns a middleware function that validates JWT tokens
-func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
+// NewAuth0Validator returns a token validator that checks tokens against the Auth0 tenant's keys
+func NewAuth0Validator(cfg *config.Config) (jwtmiddleware.ValidateToken, error) {
 	issuerURL := fmt.Sprintf("https://%s/", cfg.Auth0Domain)
-	audience := cfg.Auth0Audience
 
 	issuer, err := url.Parse(issuerURL)
 	if err != nil {
-		log.Printf("Failed to parse the issuer URL: %v", err)
+		return nil, fmt.Errorf("failed to parse the issuer URL: %w", err)
 	}
 
 	provider := jwks.NewCachingProvider(issuer, 5*60)
@@ -47,7 +47,7 @@ func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 		provider.KeyFunc,
 		validator.RS256,
 		issuerURL,
-		[]string{audience},
+		[]string{cfg.Auth0Audience},
 		validator.WithCustomClaims(
 			func() validator.CustomClaims {
 				return &CustomClaims{}
@@ -56,12 +56,17 @@ func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 		validator.WithAllowedClockSkew(30),
 	)
 	if err != nil {
-		log.Printf("Failed to set up JWT validator: %v", err)
+		return nil, fmt.Errorf("failed to set up JWT validator: %w", err)
 	}
 
-	middleware := jwtmiddleware.New(
-		jwtValidator.ValidateToken,
-	)
+	return jwtValidator.ValidateToken, nil
+}
+
+// AuthMiddleware returns a middleware function that validates JWT tokens with the given validator
+func AuthMiddleware(
+	validateToken jwtmiddleware.ValidateToken, options ...jwtmiddleware.Option,
+) func(http.Handler) http.Handler {
+	middleware := jwtmiddleware.New(validateToken, options...)
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(
@@ -84,44 +89,10 @@ func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 	}
 }
 
-// AdminMiddleware checks if the user has admin role
-func AdminMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(
-		func(w http.ResponseWriter, r *http.Request) {
-			ctx := r.Context()
-			token := r.Context().Value(jwtmiddleware.ContextKey{})
-			if token == nil {
-				log.Println("token is nil for request")
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
-				return
-			}
-
-			claims, ok := token.(*validator.ValidatedClaims)
-			if !ok {
-				http.Error(w, "Invalid token claims", http.StatusUnauthorized)
-				return
-			}
-
-			customClaims, ok := claims.CustomClaims.(*CustomClaims)
-			if !ok {
-				http.Error(w, "Invalid custom claims", http.StatusUnauthorized)
-				return
-			}
-
-			// Check if user has admin role
-			isAdmin := slices.Contains(customClaims.Permissions, adminProjects)
-			if isAdmin {
-				ctx = context.WithValue(ctx, "isAdmin", true)
-			}
-
-			if !isAdmin {
-				http.Error(w, "Forbidden: admin role required", http.StatusForbidden)
-				return
-			}
-
-			next.ServeHTTP(w, r.WithContext(ctx))
-		},
-	)
+// OptionalAuthMiddleware validates the token of requests that carry one and lets requests without one
+// through anonymously, for public routes that do more for signed-in users
+func OptionalAuthMiddleware(validateToken jwtmiddleware.ValidateToken) func(http.Handler) http.Handler {
+	return AuthMiddleware(validateToken, jwtmiddleware.WithCredentialsOptional(true))
 }
 
 // GetUserIDFromRequest extracts the user ID from the JWT token
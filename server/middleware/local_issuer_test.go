@@ -0,0 +1,67 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"serve/middleware"
+)
+
+func TestLocalIssuer(t *testing.T) {
+	issuer, err := middleware.NewLocalIssuer("https://api.test")
+	require.NoError(t, err)
+	other, err := middleware.NewLocalIssuer("https://api.test")
+	require.NoError(t, err)
+
+	valid, err := issuer.Mint("local|admin", middleware.CustomClaims{Permissions: []string{"edit:projects"}}, time.Hour)
+	require.NoError(t, err)
+	expired, err := issuer.Mint("local|admin", middleware.CustomClaims{}, -time.Hour)
+	require.NoError(t, err)
+	foreign, err := other.Mint("local|admin", middleware.CustomClaims{}, time.Hour)
+	require.NoError(t, err)
+
+	var gotSubject string
+	var gotPermissions []string
+	handler := middleware.AuthMiddleware(issuer.ValidateToken)(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				gotSubject, _ = middleware.GetUserIDFromRequest(r)
+				if claims, err := middleware.GetUserFromRequest(r); err == nil {
+					gotPermissions = claims.Permissions
+				}
+			},
+		),
+	)
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"valid", valid, http.StatusOK},
+		{"missing", "", http.StatusBadRequest},
+		{"expired", expired, http.StatusUnauthorized},
+		{"signed by another key", foreign, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				if tt.token != "" {
+					req.Header.Set("Authorization", "Bearer "+tt.token)
+				}
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				assert.Equal(t, tt.want, rec.Code)
+			},
+		)
+	}
+
+	assert.Equal(t, "local|admin", gotSubject)
+	assert.Equal(t, []string{"edit:projects"}, gotPermissions)
+}
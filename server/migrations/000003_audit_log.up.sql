@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+                                         id BIGSERIAL PRIMARY KEY,
+                                         actor TEXT NOT NULL,
+                                         action TEXT NOT NULL,
+                                         entity_type TEXT NOT NULL,
+                                         entity_id TEXT NOT NULL,
+                                         before JSONB,
+                                         after JSONB,
+                                         ip TEXT NOT NULL DEFAULT '',
+                                         created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON audit_log (created_at DESC);
+CREATE INDEX IF NOT EXISTS audit_log_entity_idx ON audit_log (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON audit_log (actor);
+
+-- The audit log is append-only: reject any attempt to change or remove history
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_modify
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS registrations_deleted_at_idx;
+DROP INDEX IF EXISTS projects_deleted_at_idx;
+DROP INDEX IF EXISTS users_deleted_at_idx;
+
+DELETE FROM registrations WHERE deleted_at IS NOT NULL;
+DELETE FROM projects WHERE deleted_at IS NOT NULL;
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS users_email_active_idx;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+DROP INDEX IF EXISTS registrations_user_id_active_idx;
+ALTER TABLE registrations ADD CONSTRAINT registrations_user_id_key UNIQUE (user_id);
+
+ALTER TABLE registrations DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE projects DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
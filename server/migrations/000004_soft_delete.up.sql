@@ -0,0 +1,25 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE registrations ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- registrations declared both ON DELETE CASCADE and ON DELETE RESTRICT foreign keys on the same
+-- columns. Rows are now soft deleted, so only the retention purge deletes for real and it should
+-- take dependent registrations with it.
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_project_id_fkey;
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_project_id_fkey1;
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_user_id_fkey;
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_user_id_fkey1;
+ALTER TABLE registrations
+    ADD CONSTRAINT registrations_project_id_fkey FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE;
+ALTER TABLE registrations
+    ADD CONSTRAINT registrations_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+-- Uniqueness only applies to rows that have not been deleted
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_user_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS registrations_user_id_active_idx ON registrations (user_id) WHERE deleted_at IS NULL;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_active_idx ON users (email) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS users_deleted_at_idx ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS projects_deleted_at_idx ON projects (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS registrations_deleted_at_idx ON registrations (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS status_reason TEXT NOT NULL DEFAULT '';
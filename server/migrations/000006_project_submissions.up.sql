@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS project_submissions (
+                                                   id SERIAL PRIMARY KEY,
+                                                   project_id INTEGER NOT NULL UNIQUE REFERENCES projects(id) ON DELETE CASCADE,
+                                                   token TEXT NOT NULL UNIQUE,
+                                                   organization TEXT NOT NULL,
+                                                   contact_name TEXT NOT NULL,
+                                                   contact_email TEXT NOT NULL,
+                                                   contact_phone TEXT NOT NULL DEFAULT '',
+                                                   needs TEXT NOT NULL DEFAULT '',
+                                                   created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                   updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
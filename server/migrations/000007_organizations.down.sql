@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS projects_organization_id_idx;
+ALTER TABLE projects DROP COLUMN IF EXISTS organization_id;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS organizations (
+                                             id SERIAL PRIMARY KEY,
+                                             name TEXT NOT NULL,
+                                             website TEXT NOT NULL DEFAULT '',
+                                             contact_name TEXT NOT NULL DEFAULT '',
+                                             contact_email TEXT NOT NULL DEFAULT '',
+                                             contact_phone TEXT NOT NULL DEFAULT '',
+                                             logo_url TEXT NOT NULL DEFAULT '',
+                                             address TEXT NOT NULL DEFAULT '',
+                                             notes TEXT NOT NULL DEFAULT '',
+                                             created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                             updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS organizations_name_idx ON organizations (lower(name));
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS projects_organization_id_idx ON projects (organization_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS project_template_types;
+DROP TABLE IF EXISTS project_templates;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS project_templates (
+                                                 id SERIAL PRIMARY KEY,
+                                                 name TEXT NOT NULL,
+                                                 organization_id INTEGER REFERENCES organizations(id) ON DELETE SET NULL,
+                                                 title TEXT NOT NULL,
+                                                 description TEXT NOT NULL,
+                                                 website TEXT NOT NULL DEFAULT '',
+                                                 time TEXT NOT NULL DEFAULT 'TBD',
+                                                 max_capacity INTEGER NOT NULL,
+                                                 area TEXT NOT NULL DEFAULT '',
+                                                 location_address TEXT NOT NULL DEFAULT '',
+                                                 latitude DOUBLE PRECISION NOT NULL DEFAULT 0,
+                                                 longitude DOUBLE PRECISION NOT NULL DEFAULT 0,
+                                                 ages TEXT NOT NULL DEFAULT 'All Ages',
+                                                 serve_lead_name TEXT NOT NULL DEFAULT '',
+                                                 serve_lead_email TEXT NOT NULL DEFAULT '',
+                                                 leads JSONB NOT NULL DEFAULT '[]'::jsonb,
+                                                 created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                 updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS project_templates_name_idx ON project_templates (lower(name));
+
+CREATE TABLE IF NOT EXISTS project_template_types (
+                                                      template_id INTEGER REFERENCES project_templates(id) ON DELETE CASCADE,
+                                                      type_id INTEGER REFERENCES types(id) ON DELETE CASCADE,
+                                                      PRIMARY KEY (template_id, type_id)
+);
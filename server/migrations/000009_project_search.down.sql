@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS registrations_project_id_idx;
+DROP INDEX IF EXISTS project_types_type_id_idx;
+DROP INDEX IF EXISTS projects_status_idx;
+DROP INDEX IF EXISTS projects_area_idx;
+DROP INDEX IF EXISTS projects_title_idx;
+DROP INDEX IF EXISTS projects_date_idx;
+DROP INDEX IF EXISTS projects_search_idx;
+ALTER TABLE projects DROP COLUMN IF EXISTS search;
@@ -0,0 +1,14 @@
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS search tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS projects_search_idx ON projects USING GIN (search);
+CREATE INDEX IF NOT EXISTS projects_date_idx ON projects (project_date, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS projects_title_idx ON projects (title, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS projects_area_idx ON projects (lower(area)) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS projects_status_idx ON projects (status) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS project_types_type_id_idx ON project_types (type_id);
+CREATE INDEX IF NOT EXISTS registrations_project_id_idx ON registrations (project_id)
+    WHERE status = 'registered' AND deleted_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS projects_location_idx;
+DROP EXTENSION IF EXISTS earthdistance;
+DROP EXTENSION IF EXISTS cube;
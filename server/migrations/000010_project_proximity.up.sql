@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
+
+CREATE INDEX IF NOT EXISTS projects_location_idx ON projects USING GIST (ll_to_earth(latitude, longitude))
+    WHERE deleted_at IS NULL AND latitude IS NOT NULL AND longitude IS NOT NULL;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS geocode_cache (
+                                             address TEXT PRIMARY KEY,
+                                             latitude DOUBLE PRECISION NOT NULL,
+                                             longitude DOUBLE PRECISION NOT NULL,
+                                             formatted_address TEXT NOT NULL DEFAULT '',
+                                             provider TEXT NOT NULL,
+                                             created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
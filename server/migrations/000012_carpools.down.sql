@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS carpool_matches;
+DROP TABLE IF EXISTS carpools;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS carpools (
+                                        registration_id INTEGER PRIMARY KEY REFERENCES registrations(id) ON DELETE CASCADE,
+                                        role TEXT NOT NULL CHECK (role IN ('driver', 'rider')),
+                                        seats INTEGER NOT NULL DEFAULT 0 CHECK (seats >= 0),
+                                        departure_area TEXT NOT NULL DEFAULT '',
+                                        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                        updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS carpool_matches (
+                                               id SERIAL PRIMARY KEY,
+                                               driver_registration_id INTEGER NOT NULL REFERENCES carpools(registration_id) ON DELETE CASCADE,
+                                               rider_registration_id INTEGER NOT NULL UNIQUE REFERENCES carpools(registration_id) ON DELETE CASCADE,
+                                               created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_carpool_matches_driver ON carpool_matches (driver_registration_id);
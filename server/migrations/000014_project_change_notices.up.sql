@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS project_change_notices (
+                                                      project_id INTEGER PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+                                                      changes JSONB NOT NULL,
+                                                      first_changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                      last_changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
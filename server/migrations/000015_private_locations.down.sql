@@ -0,0 +1,4 @@
+ALTER TABLE projects DROP COLUMN IF EXISTS location_reveal_at;
+ALTER TABLE projects DROP COLUMN IF EXISTS exact_longitude;
+ALTER TABLE projects DROP COLUMN IF EXISTS exact_latitude;
+ALTER TABLE projects DROP COLUMN IF EXISTS exact_address;
@@ -0,0 +1,4 @@
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS exact_address TEXT NOT NULL DEFAULT '';
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS exact_latitude DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS exact_longitude DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS location_reveal_at TIMESTAMP WITH TIME ZONE;
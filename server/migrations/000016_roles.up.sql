@@ -0,0 +1,59 @@
+CREATE TABLE IF NOT EXISTS roles (
+                                     name TEXT PRIMARY KEY,
+                                     description TEXT NOT NULL DEFAULT '',
+                                     created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+                                                role TEXT NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+                                                permission TEXT NOT NULL,
+                                                PRIMARY KEY (role, permission)
+);
+
+-- Roles held by an Auth0 user. A role given for a project only applies to that project.
+CREATE TABLE IF NOT EXISTS user_roles (
+                                          id SERIAL PRIMARY KEY,
+                                          subject TEXT NOT NULL,
+                                          role TEXT NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+                                          project_id INTEGER REFERENCES projects(id) ON DELETE CASCADE,
+                                          granted_by TEXT NOT NULL DEFAULT '',
+                                          created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_roles_subject_role_idx ON user_roles (subject, role, COALESCE(project_id, 0));
+
+-- Auth0 permissions that carry a role, so roles can also be handed out from the Auth0 dashboard
+CREATE TABLE IF NOT EXISTS auth0_permission_roles (
+                                                      permission TEXT PRIMARY KEY,
+                                                      role TEXT NOT NULL REFERENCES roles(name) ON DELETE CASCADE
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('super-admin', 'Everything, including managing users and roles'),
+    ('coordinator', 'Manage projects, organizations, templates and registrations'),
+    ('communications', 'Send broadcasts to volunteers'),
+    ('viewer', 'Read-only access to reports'),
+    ('project-lead', 'Read-only access to the projects they lead')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('super-admin', 'projects:manage'),
+    ('super-admin', 'broadcasts:send'),
+    ('super-admin', 'reports:read'),
+    ('super-admin', 'users:manage'),
+    ('super-admin', 'roles:manage'),
+    ('coordinator', 'projects:manage'),
+    ('coordinator', 'reports:read'),
+    ('communications', 'broadcasts:send'),
+    ('communications', 'reports:read'),
+    ('viewer', 'reports:read'),
+    ('project-lead', 'reports:read')
+ON CONFLICT DO NOTHING;
+
+-- edit:projects was the only admin permission before roles, so its holders keep full access
+INSERT INTO auth0_permission_roles (permission, role) VALUES
+    ('edit:projects', 'super-admin'),
+    ('role:coordinator', 'coordinator'),
+    ('role:communications', 'communications'),
+    ('role:viewer', 'viewer')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS households (
+                                          id SERIAL PRIMARY KEY,
+                                          name TEXT NOT NULL DEFAULT '',
+                                          primary_user_id TEXT NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+                                          created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                          updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS household_members (
+                                                 id SERIAL PRIMARY KEY,
+                                                 household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+                                                 first_name TEXT NOT NULL,
+                                                 last_name TEXT NOT NULL DEFAULT '',
+                                                 relationship TEXT NOT NULL DEFAULT '',
+                                                 minor BOOLEAN NOT NULL DEFAULT FALSE,
+                                                 birth_year INTEGER,
+                                                 created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                 updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                 deleted_at TIMESTAMP WITH TIME ZONE,
+                                                 CHECK (NOT minor OR birth_year IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS household_members_household_id_idx ON household_members (household_id);
+
+-- The household members coming along on a registration, kept after they leave the household as history
+CREATE TABLE IF NOT EXISTS registration_members (
+                                                    registration_id INTEGER NOT NULL REFERENCES registrations(id) ON DELETE CASCADE,
+                                                    member_id INTEGER NOT NULL REFERENCES household_members(id) ON DELETE CASCADE,
+                                                    PRIMARY KEY (registration_id, member_id)
+);
+
+CREATE INDEX IF NOT EXISTS registration_members_member_id_idx ON registration_members (member_id);
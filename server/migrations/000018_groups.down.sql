@@ -0,0 +1,8 @@
+DELETE FROM role_permissions WHERE permission = 'groups:lead';
+DELETE FROM roles WHERE name = 'group-leader';
+
+DROP INDEX IF EXISTS registrations_group_reservation_id_idx;
+ALTER TABLE registrations DROP COLUMN IF EXISTS group_reservation_id;
+
+DROP TABLE IF EXISTS group_reservations;
+DROP TABLE IF EXISTS groups;
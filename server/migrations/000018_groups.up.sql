@@ -0,0 +1,48 @@
+-- Small groups, youth groups and companies that volunteer together under a leader
+CREATE TABLE IF NOT EXISTS groups (
+                                      id SERIAL PRIMARY KEY,
+                                      name TEXT NOT NULL,
+                                      kind TEXT NOT NULL DEFAULT 'small-group'
+                                          CHECK (kind IN ('small-group', 'youth-group', 'company', 'other')),
+                                      leader_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                      updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS groups_leader_id_idx ON groups (leader_id);
+
+-- A block of seats a group holds on a project. Seats nobody has claimed go back to everyone at release_at,
+-- or as soon as the leader releases them.
+CREATE TABLE IF NOT EXISTS group_reservations (
+                                                  id SERIAL PRIMARY KEY,
+                                                  group_id INTEGER NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+                                                  project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+                                                  seats INTEGER NOT NULL CHECK (seats > 0),
+                                                  invite_code TEXT NOT NULL UNIQUE,
+                                                  release_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                                  released_at TIMESTAMP WITH TIME ZONE,
+                                                  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                  UNIQUE (group_id, project_id)
+);
+
+CREATE INDEX IF NOT EXISTS group_reservations_project_id_idx ON group_reservations (project_id);
+
+-- Registrations made through a group's invite link claim its seats
+ALTER TABLE registrations ADD COLUMN IF NOT EXISTS group_reservation_id INTEGER
+    REFERENCES group_reservations(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS registrations_group_reservation_id_idx ON registrations (group_reservation_id);
+
+INSERT INTO roles (name, description) VALUES
+    ('group-leader', 'Reserve seats on projects for a group and invite its members')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('super-admin', 'groups:lead'),
+    ('group-leader', 'groups:lead')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO auth0_permission_roles (permission, role) VALUES
+    ('role:group-leader', 'group-leader')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+ALTER TABLE projects DROP COLUMN IF EXISTS early_access;
+
+DROP TABLE IF EXISTS registration_windows;
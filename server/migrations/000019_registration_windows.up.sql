@@ -0,0 +1,17 @@
+-- When each audience may register for an event's projects. Events with no windows are always open.
+CREATE TABLE IF NOT EXISTS registration_windows (
+                                                    id SERIAL PRIMARY KEY,
+                                                    event_date DATE NOT NULL,
+                                                    audience TEXT NOT NULL
+                                                        CHECK (audience IN ('group-leaders', 'public')),
+                                                    opens_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                                    closes_at TIMESTAMP WITH TIME ZONE,
+                                                    access_code TEXT NOT NULL DEFAULT '',
+                                                    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                    UNIQUE (event_date, audience),
+                                                    CHECK (closes_at IS NULL OR closes_at > opens_at)
+);
+
+-- Early access projects only take registrations from group leaders and the groups they invite
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS early_access BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS event_deadlines;
+
+ALTER TABLE projects DROP COLUMN IF EXISTS cancellation_close_hours;
+ALTER TABLE projects DROP COLUMN IF EXISTS registration_close_hours;
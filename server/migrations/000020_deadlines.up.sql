@@ -0,0 +1,14 @@
+-- How many hours before a project volunteers stop being able to register or cancel. A project's own
+-- deadlines win over its event's, which win over the server defaults.
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS registration_close_hours INTEGER
+    CHECK (registration_close_hours >= 0);
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS cancellation_close_hours INTEGER
+    CHECK (cancellation_close_hours >= 0);
+
+CREATE TABLE IF NOT EXISTS event_deadlines (
+                                               event_date DATE PRIMARY KEY,
+                                               registration_close_hours INTEGER CHECK (registration_close_hours >= 0),
+                                               cancellation_close_hours INTEGER CHECK (cancellation_close_hours >= 0),
+                                               created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                               updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,20 @@
+-- Invitations for lead-interested volunteers to lead a project. The volunteer answers through the link
+-- with the token; accepting adds them to the project's leads.
+CREATE TABLE IF NOT EXISTS lead_invitations (
+                                                id SERIAL PRIMARY KEY,
+                                                project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+                                                user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                                token TEXT NOT NULL UNIQUE,
+                                                status TEXT NOT NULL DEFAULT 'pending'
+                                                    CHECK (status IN ('pending', 'accepted', 'declined', 'withdrawn')),
+                                                message TEXT NOT NULL DEFAULT '',
+                                                invited_by TEXT NOT NULL DEFAULT '',
+                                                responded_at TIMESTAMP WITH TIME ZONE,
+                                                created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                                                updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- A volunteer has at most one open invitation per project
+CREATE UNIQUE INDEX IF NOT EXISTS lead_invitations_pending_idx ON lead_invitations (project_id, user_id)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS lead_invitations_user_id_idx ON lead_invitations (user_id);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS projects_revision ON projects;
+DROP FUNCTION IF EXISTS projects_bump_revision();
+
+ALTER TABLE projects DROP COLUMN IF EXISTS revision;
@@ -0,0 +1,14 @@
+-- A counter bumped on every change to a project, used as the SEQUENCE of its calendar event so calendar
+-- apps replace their copy with the newer one
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS revision INTEGER NOT NULL DEFAULT 0;
+
+CREATE OR REPLACE FUNCTION projects_bump_revision() RETURNS trigger AS $$
+BEGIN
+    NEW.revision := OLD.revision + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER projects_revision
+    BEFORE UPDATE ON projects
+    FOR EACH ROW EXECUTE FUNCTION projects_bump_revision();
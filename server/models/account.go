@@ -0,0 +1,239 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// ErrEmailInUse is returned when a token's verified email already belongs to another signed-in account
+var ErrEmailInUse = errors.New("this email already belongs to another account")
+
+// AccountClaims are the profile claims on a signed-in user's token
+type AccountClaims struct {
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+}
+
+// AccountLink is the result of linking a token's subject to a user
+type AccountLink struct {
+	User *User `json:"user"`
+	// Merged is the email-only user folded into User, if there was one
+	Merged *User `json:"merged,omitempty"`
+}
+
+// accountUser is a user row along with the columns that move when accounts are merged
+type accountUser struct {
+	User
+	calendarToken sql.NullString
+}
+
+// LinkAccount finds or creates the user for an Auth0 subject and syncs their profile from the token.
+// When the token carries a verified email that belongs to a user who only ever registered by email,
+// that user is merged into the subject's: their registrations and projects move over, blank profile
+// fields are filled from theirs, and the email-only record is removed.
+func LinkAccount(ctx context.Context, db *sql.DB, subject string, claims AccountClaims) (*AccountLink, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	user, err := lockAccountUser(ctx, tx, `id = $1`, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(claims.Email)
+	var merged *accountUser
+	if claims.EmailVerified && email != "" {
+		// Auth0 subjects look like "google-oauth2|123"; users created by registering by email have UUIDs
+		merged, err = lockAccountUser(
+			ctx, tx, `lower(email) = lower($1) AND id <> $2 AND position('|' in id) = 0`, email, subject,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	created := user == nil
+	if created {
+		user = &accountUser{User: User{ID: subject}}
+	}
+
+	if merged != nil {
+		// the email-only user steps aside first, so their email and calendar feed are free to take over
+		if _, err = tx.ExecContext(
+			ctx, `UPDATE users SET deleted_at = NOW(), calendar_token = NULL WHERE id = $1`, merged.ID,
+		); err != nil {
+			return nil, err
+		}
+		user.absorb(merged)
+	}
+
+	if claims.EmailVerified && email != "" && user.Email != email {
+		if err = checkEmailFree(ctx, tx, email, subject); err != nil {
+			return nil, err
+		}
+		user.Email = email
+	}
+	if first := strings.TrimSpace(claims.FirstName); first != "" {
+		user.FirstName = first
+	}
+	if last := strings.TrimSpace(claims.LastName); last != "" {
+		user.LastName = last
+	}
+
+	if err = saveAccountUser(ctx, tx, user, created); err != nil {
+		return nil, err
+	}
+
+	if merged != nil {
+		if err = moveAccountRecords(ctx, tx, merged.ID, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	link := &AccountLink{User: &user.User}
+	if merged != nil {
+		link.Merged = &merged.User
+	}
+	return link, nil
+}
+
+// absorb fills the user's blank profile fields from a user being merged into them
+func (u *accountUser) absorb(other *accountUser) {
+	if u.Email == "" {
+		u.Email = other.Email
+	}
+	if u.FirstName == "" {
+		u.FirstName = other.FirstName
+	}
+	if u.LastName == "" {
+		u.LastName = other.LastName
+	}
+	if u.Phone == "" {
+		u.Phone = other.Phone
+	}
+	u.TextPermission = u.TextPermission || other.TextPermission
+	if !u.calendarToken.Valid {
+		u.calendarToken = other.calendarToken
+	}
+}
+
+// checkEmailFree returns ErrEmailInUse if an active user other than the subject holds the email
+func checkEmailFree(ctx context.Context, tx *sql.Tx, email, subject string) error {
+	var taken bool
+	err := tx.QueryRowContext(
+		ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE email = $1 AND id <> $2 AND deleted_at IS NULL)`, email, subject,
+	).Scan(&taken)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrEmailInUse
+	}
+	return nil
+}
+
+// lockAccountUser loads and locks the active user matching the condition, or returns nil if none does
+func lockAccountUser(ctx context.Context, tx *sql.Tx, condition string, args ...any) (*accountUser, error) {
+	query := `
+		SELECT id, email, first_name, last_name, COALESCE(phone, ''), COALESCE(text_permission, FALSE),
+		calendar_token, created_at, updated_at
+		FROM users
+		WHERE ` + condition + ` AND deleted_at IS NULL
+		ORDER BY created_at
+		LIMIT 1
+		FOR UPDATE
+	`
+
+	var u accountUser
+	err := tx.QueryRowContext(ctx, query, args...).Scan(
+		&u.ID, &u.Email, &u.FirstName, &u.LastName, &u.Phone, &u.TextPermission, &u.calendarToken,
+		&u.CreatedAt, &u.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// saveAccountUser inserts or updates a linked user
+func saveAccountUser(ctx context.Context, tx *sql.Tx, u *accountUser, create bool) error {
+	if create {
+		query := `
+			INSERT INTO users (id, email, first_name, last_name, phone, text_permission, calendar_token)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING created_at, updated_at
+		`
+		return tx.QueryRowContext(
+			ctx, query, u.ID, u.Email, u.FirstName, u.LastName, u.Phone, u.TextPermission, u.calendarToken,
+		).Scan(&u.CreatedAt, &u.UpdatedAt)
+	}
+
+	query := `
+		UPDATE users
+		SET email = $2, first_name = $3, last_name = $4, phone = $5, text_permission = $6, calendar_token = $7,
+		updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+	return tx.QueryRowContext(
+		ctx, query, u.ID, u.Email, u.FirstName, u.LastName, u.Phone, u.TextPermission, u.calendarToken,
+	).Scan(&u.UpdatedAt)
+}
+
+// moveAccountRecords moves everything that belongs to one user over to another, then removes the first.
+// Users may only hold one active registration, so if both have one the older is cancelled.
+func moveAccountRecords(ctx context.Context, tx *sql.Tx, fromID, toID string) error {
+	cancel := `
+		UPDATE registrations SET status = 'cancelled', deleted_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM registrations
+			WHERE user_id IN ($1, $2) AND deleted_at IS NULL
+			ORDER BY created_at
+			LIMIT 1
+		)
+		AND (SELECT COUNT(*) FROM registrations WHERE user_id IN ($1, $2) AND deleted_at IS NULL) > 1
+	`
+	// roles and open lead invitations both users hold stay with the one the records move to
+	withdrawDuplicateInvitations := `
+		UPDATE lead_invitations SET status = 'withdrawn', updated_at = NOW()
+		WHERE user_id = $1 AND status = 'pending'
+		AND project_id IN (SELECT project_id FROM lead_invitations WHERE user_id = $2 AND status = 'pending')
+	`
+	moveRoles := `
+		UPDATE user_roles ur SET subject = $2
+		WHERE subject = $1 AND NOT EXISTS (
+			SELECT 1 FROM user_roles o
+			WHERE o.subject = $2 AND o.role = ur.role AND COALESCE(o.project_id, 0) = COALESCE(ur.project_id, 0)
+		)
+	`
+	statements := []string{
+		cancel,
+		`UPDATE registrations SET user_id = $2 WHERE user_id = $1`,
+		`UPDATE projects SET serve_lead_id = $2 WHERE serve_lead_id = $1`,
+		withdrawDuplicateInvitations,
+		`UPDATE lead_invitations SET user_id = $2 WHERE user_id = $1`,
+		moveRoles,
+		`DELETE FROM user_roles WHERE subject = $1 AND subject <> $2`,
+		`DELETE FROM users WHERE id = $1 AND id <> $2`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement, fromID, toID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditActionCreate       = "create"
+	AuditActionUpdate       = "update"
+	AuditActionDelete       = "delete"
+	AuditActionCancel       = "cancel"
+	AuditActionRestore      = "restore"
+	AuditActionStatusChange = "status_change"
+	AuditActionMerge        = "merge"
+)
+
+// Audit entity types
+const (
+	AuditEntityProject            = "project"
+	AuditEntityRegistration       = "registration"
+	AuditEntityUser               = "user"
+	AuditEntityOrganization       = "organization"
+	AuditEntityTemplate           = "template"
+	AuditEntityUserRole           = "user_role"
+	AuditEntityHousehold          = "household"
+	AuditEntityGroup              = "group"
+	AuditEntityGroupReservation   = "group_reservation"
+	AuditEntityRegistrationWindow = "registration_window"
+	AuditEntityEventDeadlines     = "event_deadlines"
+	AuditEntityLeadInvitation     = "lead_invitation"
+)
+
+// AuditEntry represents a single append-only record of a data mutation
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	IP         string          `json:"ip"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter holds the optional filters and pagination for querying the audit log
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+// NewAuditEntry builds an audit entry, marshaling the before and after states to JSON. A nil state
+// is stored as NULL.
+func NewAuditEntry(actor, action, entityType string, entityID any, before, after any) (*AuditEntry, error) {
+	e := &AuditEntry{
+		Actor:      actor,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   fmt.Sprint(entityID),
+	}
+
+	var err error
+	if before != nil {
+		if e.Before, err = json.Marshal(before); err != nil {
+			return nil, fmt.Errorf("error marshaling audit before state: %w", err)
+		}
+	}
+	if after != nil {
+		if e.After, err = json.Marshal(after); err != nil {
+			return nil, fmt.Errorf("error marshaling audit after state: %w", err)
+		}
+	}
+
+	return e, nil
+}
+
+// CreateAuditEntry appends an entry to the audit log
+func CreateAuditEntry(ctx context.Context, db *sql.DB, e *AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actor, action, entity_type, entity_id, before, after, ip)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	return db.QueryRowContext(
+		ctx,
+		query,
+		e.Actor,
+		e.Action,
+		e.EntityType,
+		e.EntityID,
+		nullableJSON(e.Before),
+		nullableJSON(e.After),
+		e.IP,
+	).Scan(&e.ID, &e.CreatedAt)
+}
+
+// GetAuditEntries returns the audit entries matching the filter, newest first, along with the total
+// number of matching entries
+func GetAuditEntries(ctx context.Context, db *sql.DB, f AuditFilter) ([]AuditEntry, int, error) {
+	var conditions []string
+	var args []any
+
+	addCondition := func(cond string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.Actor != "" {
+		addCondition("actor = $%d", f.Actor)
+	}
+	if f.Action != "" {
+		addCondition("action = $%d", f.Action)
+	}
+	if f.EntityType != "" {
+		addCondition("entity_type = $%d", f.EntityType)
+	}
+	if f.EntityID != "" {
+		addCondition("entity_id = $%d", f.EntityID)
+	}
+	if f.From != nil {
+		addCondition("created_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		addCondition("created_at < $%d", *f.To)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_log "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(
+		`
+		SELECT id, actor, action, entity_type, entity_id, before, after, ip, created_at
+		FROM audit_log
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2,
+	)
+	args = append(args, f.Limit, f.Offset)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		var before, after []byte
+		if err = rows.Scan(
+			&e.ID, &e.Actor, &e.Action, &e.EntityType, &e.EntityID, &before, &after, &e.IP, &e.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		e.Before = before
+		e.After = after
+		entries = append(entries, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// nullableJSON converts an empty JSON value to a NULL database value
+func nullableJSON(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return []byte(raw)
+}
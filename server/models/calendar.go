@@ -0,0 +1,91 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// EnsureCalendarToken gives the user the calendar feed token if they do not have one yet, and returns
+// the token they end up with
+func EnsureCalendarToken(ctx context.Context, db *sql.DB, userID, token string) (string, error) {
+	query := `
+		UPDATE users SET calendar_token = COALESCE(calendar_token, $2)
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING calendar_token
+	`
+
+	var current string
+	if err := db.QueryRowContext(ctx, query, userID, token).Scan(&current); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+
+	return current, nil
+}
+
+// ResetCalendarToken replaces the user's calendar feed token, so the old feed URL stops working
+func ResetCalendarToken(ctx context.Context, db *sql.DB, userID, token string) error {
+	return execExpectingRow(
+		ctx, db, `UPDATE users SET calendar_token = $2 WHERE id = $1 AND deleted_at IS NULL`, userID, token,
+	)
+}
+
+// GetUserByCalendarToken retrieves the user a calendar feed token belongs to
+func GetUserByCalendarToken(ctx context.Context, db *sql.DB, token string) (*User, error) {
+	query := `
+		SELECT id, email, first_name, last_name, phone, text_permission, created_at, updated_at
+		FROM users
+		WHERE calendar_token = $1 AND deleted_at IS NULL
+	`
+
+	var user User
+	err := db.QueryRowContext(ctx, query, token).Scan(
+		&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.Phone, &user.TextPermission,
+		&user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // User not found
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// GetUserRegisteredProjects gets the projects a user is registered for, in date order
+func GetUserRegisteredProjects(ctx context.Context, db *sql.DB, userID string) ([]Project, error) {
+	query := `
+		SELECT p.id, p.title, p.description, p.time, p.project_date, p.area, p.location_address,
+		p.latitude, p.longitude, p.status, p.created_at, p.updated_at,
+		p.exact_address, p.exact_latitude, p.exact_longitude, p.location_reveal_at, p.revision
+		FROM registrations r
+		JOIN projects p ON p.id = r.project_id
+		WHERE r.user_id = $1 AND r.status = 'registered' AND r.deleted_at IS NULL AND p.deleted_at IS NULL
+		ORDER BY p.project_date, p.id
+	`
+
+	rows, err := db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []Project{}
+	for rows.Next() {
+		var p Project
+		if err = rows.Scan(
+			&p.ID, &p.Title, &p.Description, &p.Time, &p.ProjectDate, &p.Area, &p.LocationAddress,
+			&p.Latitude, &p.Longitude, &p.Status, &p.CreatedAt, &p.UpdatedAt,
+			&p.ExactAddress, &p.ExactLatitude, &p.ExactLongitude, &p.LocationRevealAt, &p.Revision,
+		); err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+
+	return projects, rows.Err()
+}
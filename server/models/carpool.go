@@ -0,0 +1,297 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Carpool roles
+const (
+	CarpoolDriver = "driver"
+	CarpoolRider  = "rider"
+)
+
+// ErrInvalidCarpool is returned when a carpool has an unknown role or no seats
+var ErrInvalidCarpool = errors.New("carpool role must be driver or rider, with at least one seat")
+
+// Carpool is a registration's offer of, or request for, a ride to its project. Drivers set the seats
+// they have free; riders the seats their party needs.
+type Carpool struct {
+	RegistrationID int       `json:"registration_id"`
+	Role           string    `json:"role"`
+	Seats          int       `json:"seats"`
+	DepartureArea  string    `json:"departure_area"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	User           *User     `json:"user,omitempty"`
+}
+
+// CarpoolMatch pairs a rider with a driver registered for the same project
+type CarpoolMatch struct {
+	ID        int       `json:"id"`
+	Driver    Carpool   `json:"driver"`
+	Rider     Carpool   `json:"rider"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate checks the carpool's role and seats
+func (c *Carpool) Validate() error {
+	if c.Role != CarpoolDriver && c.Role != CarpoolRider {
+		return ErrInvalidCarpool
+	}
+	if c.Seats < 1 {
+		return ErrInvalidCarpool
+	}
+	return nil
+}
+
+// SaveCarpool creates or replaces the carpool for a registration. Existing matches are kept unless the
+// role changes or a driver offers fewer seats, in which case they are released to be matched again.
+func SaveCarpool(ctx context.Context, db *sql.DB, c *Carpool) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	var oldRole string
+	var oldSeats int
+	err = tx.QueryRowContext(
+		ctx, `SELECT role, seats FROM carpools WHERE registration_id = $1 FOR UPDATE`, c.RegistrationID,
+	).Scan(&oldRole, &oldSeats)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if err == nil && (oldRole != c.Role || c.Seats < oldSeats) {
+		if _, err = tx.ExecContext(
+			ctx, `DELETE FROM carpool_matches WHERE driver_registration_id = $1 OR rider_registration_id = $1`,
+			c.RegistrationID,
+		); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO carpools (registration_id, role, seats, departure_area)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (registration_id) DO UPDATE
+		SET role = EXCLUDED.role, seats = EXCLUDED.seats, departure_area = EXCLUDED.departure_area,
+		updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+	if err = tx.QueryRowContext(
+		ctx, query, c.RegistrationID, c.Role, c.Seats, strings.TrimSpace(c.DepartureArea),
+	).Scan(&c.CreatedAt, &c.UpdatedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteCarpool withdraws a registration's carpool along with any matches it was part of
+func DeleteCarpool(ctx context.Context, db *sql.DB, registrationID int) error {
+	return execExpectingRow(ctx, db, `DELETE FROM carpools WHERE registration_id = $1`, registrationID)
+}
+
+// GetProjectCarpools gets the carpools of a project's active registrations, in the order they were offered
+func GetProjectCarpools(ctx context.Context, db *sql.DB, projectID int) ([]Carpool, error) {
+	return queryCarpools(ctx, db, projectID)
+}
+
+// GetCarpoolMatches gets the matches between a project's drivers and riders
+func GetCarpoolMatches(ctx context.Context, db *sql.DB, projectID int) ([]CarpoolMatch, error) {
+	carpools, err := queryCarpools(ctx, db, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return queryCarpoolMatches(ctx, db, carpools)
+}
+
+// MatchCarpools pairs a project's unmatched riders with drivers that have seats to spare, releasing
+// any matches whose driver or rider is no longer registered first. It returns only the new matches,
+// so each party is introduced once.
+func MatchCarpools(ctx context.Context, db *sql.DB, projectID int) ([]CarpoolMatch, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	// one matching run per project at a time, so two runs cannot fill the same seats
+	if _, err = tx.ExecContext(ctx, `SELECT id FROM projects WHERE id = $1 FOR UPDATE`, projectID); err != nil {
+		return nil, err
+	}
+
+	release := `
+		DELETE FROM carpool_matches m
+		USING registrations r
+		WHERE r.id IN (m.driver_registration_id, m.rider_registration_id) AND r.project_id = $1
+		AND (r.status <> 'registered' OR r.deleted_at IS NOT NULL)
+	`
+	if _, err = tx.ExecContext(ctx, release, projectID); err != nil {
+		return nil, err
+	}
+
+	carpools, err := queryCarpools(ctx, tx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := queryCarpoolMatches(ctx, tx, carpools)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := PairCarpools(carpools, existing)
+	for i := range matches {
+		err = tx.QueryRowContext(
+			ctx,
+			`INSERT INTO carpool_matches (driver_registration_id, rider_registration_id) VALUES ($1, $2)
+			RETURNING id, created_at`,
+			matches[i].Driver.RegistrationID, matches[i].Rider.RegistrationID,
+		).Scan(&matches[i].ID, &matches[i].CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// PairCarpools matches each unmatched rider, in the order they asked, with a driver that has enough
+// seats left for the rider's party. Drivers leaving from the rider's departure area are preferred.
+func PairCarpools(carpools []Carpool, existing []CarpoolMatch) []CarpoolMatch {
+	seatsLeft := map[int]int{}
+	matched := map[int]bool{}
+	var drivers []Carpool
+	for _, c := range carpools {
+		if c.Role == CarpoolDriver {
+			drivers = append(drivers, c)
+			seatsLeft[c.RegistrationID] = c.Seats
+		}
+	}
+	for _, m := range existing {
+		seatsLeft[m.Driver.RegistrationID] -= m.Rider.Seats
+		matched[m.Rider.RegistrationID] = true
+	}
+
+	var matches []CarpoolMatch
+	for _, rider := range carpools {
+		if rider.Role != CarpoolRider || matched[rider.RegistrationID] {
+			continue
+		}
+
+		best := -1
+		for i, driver := range drivers {
+			if seatsLeft[driver.RegistrationID] < rider.Seats {
+				continue
+			}
+			if sameArea(driver.DepartureArea, rider.DepartureArea) {
+				best = i
+				break
+			}
+			if best < 0 {
+				best = i
+			}
+		}
+		if best < 0 {
+			continue
+		}
+
+		seatsLeft[drivers[best].RegistrationID] -= rider.Seats
+		matches = append(matches, CarpoolMatch{Driver: drivers[best], Rider: rider})
+	}
+
+	return matches
+}
+
+// sameArea reports whether two departure areas name the same place, ignoring case and spacing
+func sameArea(a, b string) bool {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	return a != "" && strings.EqualFold(a, b)
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// queryCarpools loads the carpools of a project's active registrations with their users
+func queryCarpools(ctx context.Context, q queryer, projectID int) ([]Carpool, error) {
+	query := `
+		SELECT c.registration_id, c.role, c.seats, c.departure_area, c.created_at, c.updated_at,
+		u.id, u.email, u.first_name, u.last_name, u.phone
+		FROM carpools c
+		JOIN registrations r ON r.id = c.registration_id
+		JOIN users u ON u.id = r.user_id
+		WHERE r.project_id = $1 AND r.status = 'registered' AND r.deleted_at IS NULL AND u.deleted_at IS NULL
+		ORDER BY c.created_at, c.registration_id
+	`
+
+	rows, err := q.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	carpools := []Carpool{}
+	for rows.Next() {
+		c := Carpool{User: &User{}}
+		if err = rows.Scan(
+			&c.RegistrationID, &c.Role, &c.Seats, &c.DepartureArea, &c.CreatedAt, &c.UpdatedAt,
+			&c.User.ID, &c.User.Email, &c.User.FirstName, &c.User.LastName, &c.User.Phone,
+		); err != nil {
+			return nil, err
+		}
+		carpools = append(carpools, c)
+	}
+
+	return carpools, rows.Err()
+}
+
+// queryCarpoolMatches loads the matches between the given carpools
+func queryCarpoolMatches(ctx context.Context, q queryer, carpools []Carpool) ([]CarpoolMatch, error) {
+	byRegistration := map[int]Carpool{}
+	ids := make([]int64, 0, len(carpools))
+	for _, c := range carpools {
+		byRegistration[c.RegistrationID] = c
+		ids = append(ids, int64(c.RegistrationID))
+	}
+
+	query := `
+		SELECT id, driver_registration_id, rider_registration_id, created_at
+		FROM carpool_matches
+		WHERE driver_registration_id = ANY($1) AND rider_registration_id = ANY($1)
+		ORDER BY created_at, id
+	`
+
+	rows, err := q.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []CarpoolMatch{}
+	for rows.Next() {
+		var m CarpoolMatch
+		var driverID, riderID int
+		if err = rows.Scan(&m.ID, &driverID, &riderID, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		m.Driver, m.Rider = byRegistration[driverID], byRegistration[riderID]
+		matches = append(matches, m)
+	}
+
+	return matches, rows.Err()
+}
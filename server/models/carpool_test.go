@@ -0,0 +1,32 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"serve/models"
+)
+
+func TestPairCarpools(t *testing.T) {
+	carpools := []models.Carpool{
+		{RegistrationID: 1, Role: models.CarpoolDriver, Seats: 3, DepartureArea: "Castle Rock"},
+		{RegistrationID: 2, Role: models.CarpoolDriver, Seats: 2, DepartureArea: "Parker"},
+		{RegistrationID: 3, Role: models.CarpoolRider, Seats: 2, DepartureArea: " parker "},
+		{RegistrationID: 4, Role: models.CarpoolRider, Seats: 2, DepartureArea: "Parker"},
+		{RegistrationID: 5, Role: models.CarpoolRider, Seats: 1},
+		{RegistrationID: 6, Role: models.CarpoolRider, Seats: 4},
+		{RegistrationID: 7, Role: models.CarpoolRider, Seats: 1},
+	}
+	existing := []models.CarpoolMatch{{Driver: carpools[0], Rider: carpools[6]}}
+
+	matches := models.PairCarpools(carpools, existing)
+
+	pairs := map[int]int{}
+	for _, m := range matches {
+		pairs[m.Rider.RegistrationID] = m.Driver.RegistrationID
+	}
+	assert.Equal(t, map[int]int{
+		3: 2, // same departure area
+		4: 1, // the Parker driver is full, so any driver with room
+	}, pairs, "rider 5 finds no seat left, rider 6 needs more than any driver has and rider 7 is already matched")
+}
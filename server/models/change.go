@@ -0,0 +1,234 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ProjectChange is a change to a project detail that registrants need to know about
+type ProjectChange struct {
+	Field  string `json:"field"`
+	Label  string `json:"label"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// ProjectChangeNotice is the set of changes to a project waiting to be sent to its registrants
+type ProjectChangeNotice struct {
+	ProjectID      int             `json:"project_id"`
+	Changes        []ProjectChange `json:"changes"`
+	FirstChangedAt time.Time       `json:"first_changed_at"`
+	LastChangedAt  time.Time       `json:"last_changed_at"`
+}
+
+// DiffProjects lists the material differences between two versions of a project: its date, time,
+// address, private exact address and description. Other edits do not change what registrants need to know.
+func DiffProjects(before, after *Project) []ProjectChange {
+	fields := []struct {
+		field, label  string
+		before, after string
+	}{
+		{"date", "Date", formatProjectDate(before.ProjectDate), formatProjectDate(after.ProjectDate)},
+		{"time", "Time", before.Time, after.Time},
+		{"address", "Address", before.LocationAddress, after.LocationAddress},
+		{"exact_address", "Address", before.ExactAddress, after.ExactAddress},
+		{"description", "Description", before.Description, after.Description},
+	}
+
+	var changes []ProjectChange
+	for _, f := range fields {
+		if f.before != f.after {
+			changes = append(changes, ProjectChange{Field: f.field, Label: f.label, Before: f.before, After: f.after})
+		}
+	}
+	return changes
+}
+
+// formatProjectDate formats a project date the way it is shown to volunteers. Project dates are stored
+// as the day at 8:00 UTC, so the UTC date is the project's date.
+func formatProjectDate(date time.Time) string {
+	return date.UTC().Format("Monday, January 2, 2006")
+}
+
+// MergeProjectChanges folds newer changes into pending ones. Each field keeps the value registrants
+// last heard about as its before, and fields changed back to that value are dropped.
+func MergeProjectChanges(pending, changes []ProjectChange) []ProjectChange {
+	merged := append([]ProjectChange(nil), pending...)
+	for _, c := range changes {
+		found := false
+		for i := range merged {
+			if merged[i].Field == c.Field {
+				merged[i].After = c.After
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, c)
+		}
+	}
+
+	result := merged[:0]
+	for _, c := range merged {
+		if c.Before != c.After {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// QueueProjectChanges adds changes to the project's pending notice, restarting its quiet period
+func QueueProjectChanges(ctx context.Context, db *sql.DB, projectID int, changes []ProjectChange) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	var pending []ProjectChange
+	var raw []byte
+	err = tx.QueryRowContext(
+		ctx, `SELECT changes FROM project_change_notices WHERE project_id = $1 FOR UPDATE`, projectID,
+	).Scan(&raw)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return err
+	default:
+		if err = json.Unmarshal(raw, &pending); err != nil {
+			return err
+		}
+	}
+
+	merged := MergeProjectChanges(pending, changes)
+	if len(merged) == 0 {
+		// everything was changed back, so there is nothing left to tell anyone
+		_, err = tx.ExecContext(ctx, `DELETE FROM project_change_notices WHERE project_id = $1`, projectID)
+		if err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	if raw, err = json.Marshal(merged); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO project_change_notices (project_id, changes)
+		VALUES ($1, $2)
+		ON CONFLICT (project_id) DO UPDATE
+		SET changes = EXCLUDED.changes, last_changed_at = NOW()
+	`
+	if _, err = tx.ExecContext(ctx, query, projectID, raw); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetDueProjectChanges returns the notices that are ready to send: those whose project has not changed
+// since quietSince, or that have waited since before maxWaitSince. They stay queued until cleared once sent.
+func GetDueProjectChanges(ctx context.Context, db *sql.DB, quietSince, maxWaitSince time.Time) (
+	[]ProjectChangeNotice, error,
+) {
+	query := `
+		SELECT project_id, changes, first_changed_at, last_changed_at
+		FROM project_change_notices
+		WHERE last_changed_at <= $1 OR first_changed_at <= $2
+		ORDER BY first_changed_at
+	`
+
+	rows, err := db.QueryContext(ctx, query, quietSince, maxWaitSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notices []ProjectChangeNotice
+	for rows.Next() {
+		var n ProjectChangeNotice
+		var raw []byte
+		if err = rows.Scan(&n.ProjectID, &raw, &n.FirstChangedAt, &n.LastChangedAt); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(raw, &n.Changes); err != nil {
+			return nil, err
+		}
+		notices = append(notices, n)
+	}
+
+	return notices, rows.Err()
+}
+
+// ClearProjectChangeNotice removes a notice once it has been sent. Changes queued while it was being sent
+// stay pending, with registrants now having heard about the sent values.
+func ClearProjectChangeNotice(ctx context.Context, db *sql.DB, sent ProjectChangeNotice) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	var pending []ProjectChange
+	var raw []byte
+	var lastChangedAt time.Time
+	err = tx.QueryRowContext(
+		ctx, `SELECT changes, last_changed_at FROM project_change_notices WHERE project_id = $1 FOR UPDATE`,
+		sent.ProjectID,
+	).Scan(&raw, &lastChangedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(raw, &pending); err != nil {
+		return err
+	}
+
+	if lastChangedAt.Equal(sent.LastChangedAt) {
+		pending = nil
+	} else {
+		pending = rebaseProjectChanges(pending, sent.Changes)
+	}
+	if len(pending) == 0 {
+		_, err = tx.ExecContext(ctx, `DELETE FROM project_change_notices WHERE project_id = $1`, sent.ProjectID)
+		if err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	if raw, err = json.Marshal(pending); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(
+		ctx, `UPDATE project_change_notices SET changes = $2, first_changed_at = last_changed_at WHERE project_id = $1`,
+		sent.ProjectID, raw,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// rebaseProjectChanges starts pending changes from the values registrants were just sent, dropping the
+// fields that have not changed since
+func rebaseProjectChanges(pending, sent []ProjectChange) []ProjectChange {
+	var rebased []ProjectChange
+	for _, c := range pending {
+		for _, s := range sent {
+			if s.Field == c.Field {
+				c.Before = s.After
+				break
+			}
+		}
+		if c.Before != c.After {
+			rebased = append(rebased, c)
+		}
+	}
+	return rebased
+}
@@ -0,0 +1,54 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"serve/models"
+)
+
+func TestDiffProjects(t *testing.T) {
+	before := &models.Project{
+		Title:           "Yard Work",
+		Description:     "Raking and mulching",
+		Time:            "9am - 12pm",
+		ProjectDate:     time.Date(2025, time.July, 12, 8, 0, 0, 0, time.UTC),
+		LocationAddress: "TBD. Communicated week prior to project.",
+		MaxCapacity:     10,
+	}
+	after := *before
+	after.Title = "Yard Work (Home 2)"
+	after.MaxCapacity = 12
+	after.ProjectDate = time.Date(2025, time.July, 12, 16, 0, 0, 0, time.UTC)
+
+	assert.Empty(t, models.DiffProjects(before, &after), "title, capacity and time of day are not material")
+
+	after.LocationAddress = "100 Third St, Castle Rock, CO"
+	after.Time = "8am - 11am"
+	changes := models.DiffProjects(before, &after)
+	assert.Equal(t, []models.ProjectChange{
+		{Field: "time", Label: "Time", Before: "9am - 12pm", After: "8am - 11am"},
+		{
+			Field: "address", Label: "Address",
+			Before: "TBD. Communicated week prior to project.", After: "100 Third St, Castle Rock, CO",
+		},
+	}, changes)
+}
+
+func TestMergeProjectChanges(t *testing.T) {
+	pending := []models.ProjectChange{
+		{Field: "time", Label: "Time", Before: "9am - 12pm", After: "8am - 11am"},
+		{Field: "address", Label: "Address", Before: "TBD", After: "100 Third St"},
+	}
+	changes := []models.ProjectChange{
+		{Field: "time", Label: "Time", Before: "8am - 11am", After: "9am - 12pm"},
+		{Field: "address", Label: "Address", Before: "100 Third St", After: "100 Third Street"},
+		{Field: "description", Label: "Description", Before: "Raking", After: "Raking and mulching"},
+	}
+
+	assert.Equal(t, []models.ProjectChange{
+		{Field: "address", Label: "Address", Before: "TBD", After: "100 Third Street"},
+		{Field: "description", Label: "Description", Before: "Raking", After: "Raking and mulching"},
+	}, models.MergeProjectChanges(pending, changes), "a time changed back should not be announced")
+}
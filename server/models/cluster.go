@@ -0,0 +1,113 @@
+package models
+
+import (
+	"math"
+	"strings"
+)
+
+// DefaultClusterPrecision is the number of decimal places coordinates are rounded to when clustering,
+// roughly 11 meters
+const DefaultClusterPrecision = 4
+
+// MaxClusterPrecision is the finest clustering precision, roughly 11 centimeters
+const MaxClusterPrecision = 6
+
+// FeatureCollection is a GeoJSON feature collection of project clusters
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON point feature for a cluster of co-located projects
+type Feature struct {
+	Type       string            `json:"type"`
+	Geometry   Point             `json:"geometry"`
+	Properties ClusterProperties `json:"properties"`
+}
+
+// Point is a GeoJSON point. Coordinates are longitude then latitude.
+type Point struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// ClusterProperties summarizes the projects in a cluster
+type ClusterProperties struct {
+	Count       int              `json:"count"`
+	OpenSpots   int              `json:"open_spots"`
+	MaxCapacity int              `json:"max_capacity"`
+	Approximate bool             `json:"approximate"`
+	Projects    []ClusterProject `json:"projects"`
+}
+
+// ClusterProject is a project within a cluster
+type ClusterProject struct {
+	ID              int    `json:"id"`
+	Title           string `json:"title"`
+	LocationAddress string `json:"location_address"`
+	OpenSpots       int    `json:"open_spots"`
+	MaxCapacity     int    `json:"max_capacity"`
+	Approximate     bool   `json:"approximate"`
+}
+
+// IsApproximateLocation reports whether a project's address is still to be determined, in which case
+// its coordinates only roughly mark where it will be
+func IsApproximateLocation(address string) bool {
+	return strings.Contains(strings.ToUpper(address), "TBD")
+}
+
+// ClusterProjects groups projects whose coordinates match when rounded to the given number of decimal
+// places. Each cluster is placed at the average position of its projects. Projects without
+// coordinates are left out.
+func ClusterProjects(projects []Project, precision int) FeatureCollection {
+	scale := math.Pow10(precision)
+	type cell struct{ lat, lng int64 }
+
+	collection := FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+	index := map[cell]int{}
+	for _, p := range projects {
+		if p.Latitude == 0 && p.Longitude == 0 {
+			continue
+		}
+
+		key := cell{int64(math.Round(p.Latitude * scale)), int64(math.Round(p.Longitude * scale))}
+		i, ok := index[key]
+		if !ok {
+			i = len(collection.Features)
+			index[key] = i
+			collection.Features = append(
+				collection.Features, Feature{Type: "Feature", Geometry: Point{Type: "Point"}},
+			)
+		}
+
+		f := &collection.Features[i]
+		openSpots := max(p.MaxCapacity-p.CurrentReg-p.ReservedSeats, 0)
+		approximate := IsApproximateLocation(p.LocationAddress)
+
+		// keep a running sum of coordinates, averaged once every project is placed
+		f.Geometry.Coordinates[0] += p.Longitude
+		f.Geometry.Coordinates[1] += p.Latitude
+		f.Properties.Count++
+		f.Properties.OpenSpots += openSpots
+		f.Properties.MaxCapacity += p.MaxCapacity
+		f.Properties.Approximate = f.Properties.Approximate || approximate
+		f.Properties.Projects = append(
+			f.Properties.Projects, ClusterProject{
+				ID:              p.ID,
+				Title:           p.Title,
+				LocationAddress: p.LocationAddress,
+				OpenSpots:       openSpots,
+				MaxCapacity:     p.MaxCapacity,
+				Approximate:     approximate,
+			},
+		)
+	}
+
+	for i := range collection.Features {
+		f := &collection.Features[i]
+		f.Geometry.Coordinates[0] /= float64(f.Properties.Count)
+		f.Geometry.Coordinates[1] /= float64(f.Properties.Count)
+	}
+
+	return collection
+}
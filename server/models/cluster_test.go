@@ -0,0 +1,36 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"serve/models"
+)
+
+func TestClusterProjects(t *testing.T) {
+	projects := []models.Project{
+		{ID: 1, Latitude: 39.37221, Longitude: -104.85609, MaxCapacity: 10, CurrentReg: 4},
+		{ID: 2, Latitude: 39.37223, Longitude: -104.85611, MaxCapacity: 10, CurrentReg: 12},
+		{ID: 3, Latitude: 39.40000, Longitude: -104.90000, MaxCapacity: 5, ReservedSeats: 2, LocationAddress: "TBD"},
+		{ID: 4, MaxCapacity: 8}, // no coordinates
+	}
+
+	collection := models.ClusterProjects(projects, models.DefaultClusterPrecision)
+
+	assert.Equal(t, "FeatureCollection", collection.Type)
+	assert.Len(t, collection.Features, 2)
+
+	shared := collection.Features[0].Properties
+	assert.Equal(t, 2, shared.Count)
+	assert.Equal(t, 6, shared.OpenSpots, "over-full projects should not reduce the cluster's open spots")
+	assert.Equal(t, 20, shared.MaxCapacity)
+	assert.False(t, shared.Approximate)
+	assert.InDelta(t, -104.8561, collection.Features[0].Geometry.Coordinates[0], 1e-9)
+	assert.InDelta(t, 39.37222, collection.Features[0].Geometry.Coordinates[1], 1e-9)
+
+	assert.True(t, collection.Features[1].Properties.Approximate)
+	assert.Equal(t, 3, collection.Features[1].Properties.OpenSpots, "seats groups hold are not open")
+
+	// at a coarser precision every located project falls in one cluster
+	assert.Len(t, models.ClusterProjects(projects, 1).Features, 1)
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidDeadlines is returned when deadlines are set a negative number of hours before the project
+	ErrInvalidDeadlines = errors.New("deadlines must be zero or more hours before the project")
+	// ErrRegistrationDeadline is returned when a volunteer registers or adds guests after registration closed
+	ErrRegistrationDeadline = errors.New("registration for this project has closed")
+	// ErrCancellationDeadline is returned when a volunteer cancels or drops guests after cancellations closed
+	ErrCancellationDeadline = errors.New("cancellations for this project have closed")
+)
+
+// CloseHours is how many hours before a project registration and cancellation close. Unset hours fall
+// back to the next level: a project's to its event's, and an event's to the server defaults.
+type CloseHours struct {
+	Registration *int `json:"registration_close_hours"`
+	Cancellation *int `json:"cancellation_close_hours"`
+}
+
+// EventDeadlines are the close hours of every project on an event date
+type EventDeadlines struct {
+	EventDate string `json:"event_date"`
+	CloseHours
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Deadlines are when volunteers stop being able to register for a project and cancel their registration
+type Deadlines struct {
+	RegistrationClosesAt time.Time `json:"registration_closes_at"`
+	CancellationClosesAt time.Time `json:"cancellation_closes_at"`
+}
+
+// Validate checks that no hours are negative
+func (c CloseHours) Validate() error {
+	if (c.Registration != nil && *c.Registration < 0) || (c.Cancellation != nil && *c.Cancellation < 0) {
+		return ErrInvalidDeadlines
+	}
+	return nil
+}
+
+// ResolveDeadlines works out a project's deadlines from its own close hours, its event's and the defaults,
+// using the first that is set
+func ResolveDeadlines(projectDate time.Time, project, event CloseHours, defaults CloseHours) Deadlines {
+	first := func(hours ...*int) time.Duration {
+		for _, h := range hours {
+			if h != nil {
+				return time.Duration(*h) * time.Hour
+			}
+		}
+		return 0
+	}
+
+	return Deadlines{
+		RegistrationClosesAt: projectDate.Add(-first(project.Registration, event.Registration, defaults.Registration)),
+		CancellationClosesAt: projectDate.Add(-first(project.Cancellation, event.Cancellation, defaults.Cancellation)),
+	}
+}
+
+// CheckRegistration returns ErrRegistrationDeadline once registration has closed
+func (d Deadlines) CheckRegistration(now time.Time) error {
+	if !now.Before(d.RegistrationClosesAt) {
+		return ErrRegistrationDeadline
+	}
+	return nil
+}
+
+// CheckCancellation returns ErrCancellationDeadline once cancellations have closed
+func (d Deadlines) CheckCancellation(now time.Time) error {
+	if !now.Before(d.CancellationClosesAt) {
+		return ErrCancellationDeadline
+	}
+	return nil
+}
+
+// GetEventDeadlines gets the close hours of an event. Events without their own have empty hours.
+func GetEventDeadlines(ctx context.Context, db *sql.DB, eventDate string) (*EventDeadlines, error) {
+	deadlines, err := queryEventDeadlines(ctx, db, `event_date = $1`, eventDate)
+	if err != nil {
+		return nil, err
+	}
+	if len(deadlines) == 0 {
+		return &EventDeadlines{EventDate: eventDate}, nil
+	}
+	return &deadlines[0], nil
+}
+
+// GetAllEventDeadlines gets the close hours of the events on or after a date
+func GetAllEventDeadlines(ctx context.Context, db *sql.DB, from string) ([]EventDeadlines, error) {
+	return queryEventDeadlines(ctx, db, `event_date >= $1`, from)
+}
+
+// SaveEventDeadlines sets the close hours of an event
+func SaveEventDeadlines(ctx context.Context, db *sql.DB, d *EventDeadlines) error {
+	if _, err := time.Parse(eventDateLayout, d.EventDate); err != nil {
+		return fmt.Errorf("%w: event_date must look like 2025-07-12", ErrInvalidDeadlines)
+	}
+	if err := d.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO event_deadlines (event_date, registration_close_hours, cancellation_close_hours)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (event_date) DO UPDATE
+		SET registration_close_hours = EXCLUDED.registration_close_hours,
+		cancellation_close_hours = EXCLUDED.cancellation_close_hours, updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+	return db.QueryRowContext(
+		ctx, query, d.EventDate, d.Registration, d.Cancellation,
+	).Scan(&d.CreatedAt, &d.UpdatedAt)
+}
+
+// DeleteEventDeadlines removes an event's close hours, so its projects use the defaults
+func DeleteEventDeadlines(ctx context.Context, db *sql.DB, eventDate string) error {
+	return execExpectingRow(ctx, db, `DELETE FROM event_deadlines WHERE event_date = $1`, eventDate)
+}
+
+// queryEventDeadlines loads the event deadlines matching the condition
+func queryEventDeadlines(ctx context.Context, db *sql.DB, condition string, args ...any) ([]EventDeadlines, error) {
+	query := `
+		SELECT to_char(event_date, 'YYYY-MM-DD'), registration_close_hours, cancellation_close_hours, created_at,
+		updated_at
+		FROM event_deadlines
+		WHERE ` + condition + `
+		ORDER BY event_date
+	`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deadlines := []EventDeadlines{}
+	for rows.Next() {
+		var d EventDeadlines
+		var registration, cancellation sql.NullInt64
+		if err = rows.Scan(&d.EventDate, &registration, &cancellation, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, err
+		}
+		d.Registration, d.Cancellation = nullableInt(registration), nullableInt(cancellation)
+		deadlines = append(deadlines, d)
+	}
+
+	return deadlines, rows.Err()
+}
+
+// nullableInt converts a nullable integer column to a pointer
+func nullableInt(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
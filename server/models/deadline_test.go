@@ -0,0 +1,63 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"serve/models"
+)
+
+func TestResolveDeadlines(t *testing.T) {
+	projectDate := time.Date(2026, 7, 11, 14, 0, 0, 0, time.UTC)
+	hours := func(h int) *int { return &h }
+	defaults := models.CloseHours{Registration: hours(24), Cancellation: hours(12)}
+
+	tests := []struct {
+		name         string
+		project      models.CloseHours
+		event        models.CloseHours
+		defaults     models.CloseHours
+		registration time.Duration
+		cancellation time.Duration
+	}{
+		{name: "no deadlines", registration: 0, cancellation: 0},
+		{name: "server defaults", defaults: defaults, registration: 24 * time.Hour, cancellation: 12 * time.Hour},
+		{
+			name:         "event overrides the defaults",
+			event:        models.CloseHours{Registration: hours(48)},
+			defaults:     defaults,
+			registration: 48 * time.Hour,
+			cancellation: 12 * time.Hour,
+		},
+		{
+			name:         "project overrides its event",
+			project:      models.CloseHours{Cancellation: hours(0)},
+			event:        models.CloseHours{Registration: hours(48), Cancellation: hours(72)},
+			defaults:     defaults,
+			registration: 48 * time.Hour,
+			cancellation: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deadlines := models.ResolveDeadlines(projectDate, tt.project, tt.event, tt.defaults)
+			assert.Equal(t, projectDate.Add(-tt.registration), deadlines.RegistrationClosesAt)
+			assert.Equal(t, projectDate.Add(-tt.cancellation), deadlines.CancellationClosesAt)
+		})
+	}
+}
+
+func TestDeadlinesCheck(t *testing.T) {
+	closes := time.Date(2026, 7, 9, 14, 0, 0, 0, time.UTC)
+	deadlines := models.Deadlines{RegistrationClosesAt: closes, CancellationClosesAt: closes}
+
+	assert.NoError(t, deadlines.CheckRegistration(closes.Add(-time.Minute)))
+	assert.ErrorIs(t, deadlines.CheckRegistration(closes), models.ErrRegistrationDeadline)
+	assert.NoError(t, deadlines.CheckCancellation(closes.Add(-time.Minute)))
+	assert.ErrorIs(t, deadlines.CheckCancellation(closes.Add(time.Hour)), models.ErrCancellationDeadline)
+
+	negative := -1
+	assert.ErrorIs(t, models.CloseHours{Registration: &negative}.Validate(), models.ErrInvalidDeadlines)
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// GeocodeCacheEntry is a previously geocoded address, keyed by its normalized form
+type GeocodeCacheEntry struct {
+	Address          string    `json:"address"`
+	Latitude         float64   `json:"latitude"`
+	Longitude        float64   `json:"longitude"`
+	FormattedAddress string    `json:"formatted_address"`
+	Provider         string    `json:"provider"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// GetGeocodeCacheEntry retrieves the cached geocoding result for a normalized address
+func GetGeocodeCacheEntry(ctx context.Context, db *sql.DB, address string) (*GeocodeCacheEntry, error) {
+	query := `
+		SELECT address, latitude, longitude, formatted_address, provider, created_at
+		FROM geocode_cache
+		WHERE address = $1
+	`
+
+	var e GeocodeCacheEntry
+	err := db.QueryRowContext(ctx, query, address).Scan(
+		&e.Address, &e.Latitude, &e.Longitude, &e.FormattedAddress, &e.Provider, &e.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Address not cached
+		}
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// SaveGeocodeCacheEntry stores the geocoding result for a normalized address, replacing any earlier one
+func SaveGeocodeCacheEntry(ctx context.Context, db *sql.DB, e *GeocodeCacheEntry) error {
+	query := `
+		INSERT INTO geocode_cache (address, latitude, longitude, formatted_address, provider)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (address) DO UPDATE
+		SET latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude,
+		formatted_address = EXCLUDED.formatted_address, provider = EXCLUDED.provider, created_at = NOW()
+		RETURNING created_at
+	`
+
+	return db.QueryRowContext(
+		ctx, query, e.Address, e.Latitude, e.Longitude, e.FormattedAddress, e.Provider,
+	).Scan(&e.CreatedAt)
+}
@@ -0,0 +1,469 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Kinds of group
+const (
+	GroupSmallGroup = "small-group"
+	GroupYouthGroup = "youth-group"
+	GroupCompany    = "company"
+	GroupOther      = "other"
+)
+
+var (
+	// ErrInvalidGroup is returned when a group is missing its name or has an unknown kind
+	ErrInvalidGroup = errors.New("groups need a name and a kind of small-group, youth-group, company or other")
+	// ErrInvalidReservation is returned when a reservation has no seats or releases them after the project
+	ErrInvalidReservation = errors.New("reservations need at least one seat and must be released by the project date")
+	// ErrReservationExists is returned when a group reserves seats on a project it already holds seats on
+	ErrReservationExists = errors.New("the group already has seats reserved on this project")
+	// ErrNotEnoughSeats is returned when a project does not have enough open spots for a reservation
+	ErrNotEnoughSeats = errors.New("the project does not have that many open spots")
+	// ErrSeatsClaimed is returned when a reservation is cut below the seats its members have claimed
+	ErrSeatsClaimed = errors.New("members have already claimed more seats than that")
+)
+
+// heldSeatsSQL lists the seats each active reservation still holds for members who have not claimed them.
+// Seats stop being held once the reservation is released or its release time passes.
+const heldSeatsSQL = `
+	SELECT gr.id, gr.project_id, GREATEST(gr.seats - COALESCE(SUM(1 + r.guest_count), 0), 0) AS held
+	FROM group_reservations gr
+	LEFT JOIN registrations r ON r.group_reservation_id = gr.id AND r.status = 'registered'
+		AND r.deleted_at IS NULL
+	WHERE gr.released_at IS NULL AND gr.release_at > NOW()
+	GROUP BY gr.id
+`
+
+// Group is a small group, youth group or company that volunteers together under a leader
+type Group struct {
+	ID           int                `json:"id"`
+	Name         string             `json:"name"`
+	Kind         string             `json:"kind"`
+	LeaderID     string             `json:"leader_id"`
+	Reservations []GroupReservation `json:"reservations"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// GroupReservation is a block of seats a group holds on a project for its members to claim through the
+// reservation's invite link
+type GroupReservation struct {
+	ID           int        `json:"id"`
+	GroupID      int        `json:"group_id"`
+	ProjectID    int        `json:"project_id"`
+	ProjectTitle string     `json:"project_title"`
+	ProjectDate  time.Time  `json:"project_date"`
+	Seats        int        `json:"seats"`
+	Claimed      int        `json:"claimed"`
+	InviteCode   string     `json:"invite_code"`
+	ReleaseAt    time.Time  `json:"release_at"`
+	ReleasedAt   *time.Time `json:"released_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// GroupClaim is a member's registration that claimed seats from a reservation
+type GroupClaim struct {
+	RegistrationID int       `json:"registration_id"`
+	FirstName      string    `json:"first_name"`
+	LastName       string    `json:"last_name"`
+	Email          string    `json:"email"`
+	GuestCount     int       `json:"guest_count"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// GroupInvite is what a member sees when they open a reservation's invite link
+type GroupInvite struct {
+	ReservationID int       `json:"reservation_id"`
+	GroupName     string    `json:"group_name"`
+	GroupKind     string    `json:"group_kind"`
+	ProjectID     int       `json:"project_id"`
+	ProjectTitle  string    `json:"project_title"`
+	ProjectDate   time.Time `json:"project_date"`
+	OpenSeats     int       `json:"open_seats"`
+	ReleaseAt     time.Time `json:"release_at"`
+	Released      bool      `json:"released"`
+}
+
+// Validate checks the group's name and kind
+func (g *Group) Validate() error {
+	g.Name = strings.TrimSpace(g.Name)
+	if g.Kind == "" {
+		g.Kind = GroupSmallGroup
+	}
+	switch g.Kind {
+	case GroupSmallGroup, GroupYouthGroup, GroupCompany, GroupOther:
+	default:
+		return ErrInvalidGroup
+	}
+	if g.Name == "" {
+		return ErrInvalidGroup
+	}
+	return nil
+}
+
+// Held reports whether the reservation still holds its unclaimed seats at the given time
+func (r *GroupReservation) Held(now time.Time) bool {
+	return r.ReleasedAt == nil && now.Before(r.ReleaseAt)
+}
+
+// OpenSeats is how many of the reservation's seats are still held for members at the given time
+func (r *GroupReservation) OpenSeats(now time.Time) int {
+	if !r.Held(now) {
+		return 0
+	}
+	return max(r.Seats-r.Claimed, 0)
+}
+
+// CreateGroup creates a group led by LeaderID
+func CreateGroup(ctx context.Context, db *sql.DB, g *Group) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO groups (name, kind, leader_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+	g.Reservations = []GroupReservation{}
+	return db.QueryRowContext(ctx, query, g.Name, g.Kind, g.LeaderID).Scan(&g.ID, &g.CreatedAt, &g.UpdatedAt)
+}
+
+// UpdateGroup renames a group or changes its kind. Only its leader may update it.
+func UpdateGroup(ctx context.Context, db *sql.DB, g *Group) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE groups SET name = $3, kind = $4, updated_at = NOW()
+		WHERE id = $1 AND leader_id = $2
+		RETURNING created_at, updated_at
+	`
+	err := db.QueryRowContext(ctx, query, g.ID, g.LeaderID, g.Name, g.Kind).Scan(&g.CreatedAt, &g.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// GetGroupsByLeader gets the groups a user leads with their reservations
+func GetGroupsByLeader(ctx context.Context, db *sql.DB, leaderID string) ([]Group, error) {
+	query := `
+		SELECT id, name, kind, leader_id, created_at, updated_at
+		FROM groups
+		WHERE leader_id = $1
+		ORDER BY name, id
+	`
+
+	rows, err := db.QueryContext(ctx, query, leaderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []Group{}
+	index := map[int]int{}
+	for rows.Next() {
+		var g Group
+		if err = rows.Scan(&g.ID, &g.Name, &g.Kind, &g.LeaderID, &g.CreatedAt, &g.UpdatedAt); err != nil {
+			return nil, err
+		}
+		g.Reservations = []GroupReservation{}
+		index[g.ID] = len(groups)
+		groups = append(groups, g)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	reservations, err := queryGroupReservations(ctx, db, `g.leader_id = $1`, leaderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reservations {
+		g := &groups[index[r.GroupID]]
+		g.Reservations = append(g.Reservations, r)
+	}
+	return groups, nil
+}
+
+// GetGroup gets one of a leader's groups with its reservations. It returns nil if they lead no such group.
+func GetGroup(ctx context.Context, db *sql.DB, id int, leaderID string) (*Group, error) {
+	query := `
+		SELECT id, name, kind, leader_id, created_at, updated_at
+		FROM groups
+		WHERE id = $1 AND leader_id = $2
+	`
+
+	var g Group
+	err := db.QueryRowContext(ctx, query, id, leaderID).Scan(
+		&g.ID, &g.Name, &g.Kind, &g.LeaderID, &g.CreatedAt, &g.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	g.Reservations, err = queryGroupReservations(ctx, db, `gr.group_id = $1`, g.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// ReserveSeats reserves a block of seats on a project for a group, as long as the project has that many
+// spots nobody else has taken or is holding
+func ReserveSeats(ctx context.Context, db *sql.DB, r *GroupReservation) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	project, err := lockProjectSeats(ctx, tx, r.ProjectID, 0)
+	if err != nil {
+		return err
+	}
+	if project.status != StatusOpen {
+		return ErrProjectNotOpen
+	}
+	if r.Seats <= 0 || r.ReleaseAt.After(project.date) {
+		return ErrInvalidReservation
+	}
+	if r.Seats > project.open() {
+		return ErrNotEnoughSeats
+	}
+
+	query := `
+		INSERT INTO group_reservations (group_id, project_id, seats, invite_code, release_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+	err = tx.QueryRowContext(
+		ctx, query, r.GroupID, r.ProjectID, r.Seats, r.InviteCode, r.ReleaseAt,
+	).Scan(&r.ID, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return uniqueViolationAs(err, ErrReservationExists)
+	}
+	r.ProjectTitle, r.ProjectDate = project.title, project.date
+
+	return tx.Commit()
+}
+
+// UpdateReservation changes how many seats a group holds on a project and when they are released.
+// Extra seats must be open on the project, and seats members have claimed cannot be given up.
+func UpdateReservation(ctx context.Context, db *sql.DB, r *GroupReservation) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	current, err := queryGroupReservations(ctx, tx, `gr.id = $1 AND gr.group_id = $2`, r.ID, r.GroupID)
+	if err != nil {
+		return err
+	}
+	if len(current) == 0 {
+		return ErrNotFound
+	}
+	existing := current[0]
+
+	project, err := lockProjectSeats(ctx, tx, existing.ProjectID, existing.ID)
+	if err != nil {
+		return err
+	}
+	if r.Seats <= 0 || r.ReleaseAt.After(project.date) {
+		return ErrInvalidReservation
+	}
+	if r.Seats < existing.Claimed {
+		return ErrSeatsClaimed
+	}
+	if r.Seats > existing.Seats && r.Seats-existing.Claimed > project.open() {
+		return ErrNotEnoughSeats
+	}
+
+	query := `
+		UPDATE group_reservations SET seats = $2, release_at = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+	if err = tx.QueryRowContext(ctx, query, r.ID, r.Seats, r.ReleaseAt).Scan(&r.UpdatedAt); err != nil {
+		return err
+	}
+	r.ProjectID, r.ProjectTitle, r.ProjectDate = existing.ProjectID, existing.ProjectTitle, existing.ProjectDate
+	r.Claimed, r.InviteCode, r.ReleasedAt, r.CreatedAt = existing.Claimed, existing.InviteCode, existing.ReleasedAt,
+		existing.CreatedAt
+
+	return tx.Commit()
+}
+
+// ReleaseReservation gives a reservation's unclaimed seats back to everyone before its release time.
+// Members who already claimed seats keep them.
+func ReleaseReservation(ctx context.Context, db *sql.DB, groupID, id int) error {
+	return execExpectingRow(
+		ctx, db,
+		`UPDATE group_reservations SET released_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND group_id = $2 AND released_at IS NULL`,
+		id, groupID,
+	)
+}
+
+// GetReservationClaims gets the registrations that claimed seats from a reservation
+func GetReservationClaims(ctx context.Context, db *sql.DB, reservationID int) ([]GroupClaim, error) {
+	query := `
+		SELECT r.id, u.first_name, u.last_name, u.email, r.guest_count, r.created_at
+		FROM registrations r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.group_reservation_id = $1 AND r.status = 'registered' AND r.deleted_at IS NULL
+		ORDER BY r.created_at
+	`
+
+	rows, err := db.QueryContext(ctx, query, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	claims := []GroupClaim{}
+	for rows.Next() {
+		var c GroupClaim
+		if err = rows.Scan(
+			&c.RegistrationID, &c.FirstName, &c.LastName, &c.Email, &c.GuestCount, &c.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		claims = append(claims, c)
+	}
+
+	return claims, rows.Err()
+}
+
+// GetGroupInvite gets the reservation behind an invite code. It returns nil if there is none.
+func GetGroupInvite(ctx context.Context, db *sql.DB, code string) (*GroupInvite, error) {
+	reservations, err := queryGroupReservations(ctx, db, `gr.invite_code = $1`, code)
+	if err != nil || len(reservations) == 0 {
+		return nil, err
+	}
+	r := reservations[0]
+
+	invite := &GroupInvite{
+		ReservationID: r.ID,
+		ProjectID:     r.ProjectID,
+		ProjectTitle:  r.ProjectTitle,
+		ProjectDate:   r.ProjectDate,
+		OpenSeats:     r.OpenSeats(time.Now()),
+		ReleaseAt:     r.ReleaseAt,
+		Released:      !r.Held(time.Now()),
+	}
+	err = db.QueryRowContext(
+		ctx, `SELECT name, kind FROM groups WHERE id = $1`, r.GroupID,
+	).Scan(&invite.GroupName, &invite.GroupKind)
+	if err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// GetReservedSeats gets how many of a project's spots groups are still holding for their members
+func GetReservedSeats(ctx context.Context, db *sql.DB, projectID int) (int, error) {
+	var held int
+	err := db.QueryRowContext(
+		ctx, `SELECT COALESCE(SUM(held), 0) FROM (`+heldSeatsSQL+`) h WHERE project_id = $1`, projectID,
+	).Scan(&held)
+	return held, err
+}
+
+// projectSeats is a project's capacity and how much of it is taken, read while the project is locked
+type projectSeats struct {
+	title    string
+	date     time.Time
+	status   string
+	capacity int
+	// taken counts registered volunteers and their guests, and seats held by reservations
+	taken int
+}
+
+// open is how many spots are neither registered nor held
+func (p projectSeats) open() int {
+	return p.capacity - p.taken
+}
+
+// lockProjectSeats locks a project against other registrations and reservations and counts its taken spots,
+// leaving out the seats held by the reservation being changed
+func lockProjectSeats(ctx context.Context, tx *sql.Tx, projectID, exceptReservationID int) (projectSeats, error) {
+	var p projectSeats
+	err := tx.QueryRowContext(
+		ctx,
+		`SELECT title, project_date, status, max_capacity FROM projects WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE`,
+		projectID,
+	).Scan(&p.title, &p.date, &p.status, &p.capacity)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return p, ErrNotFound
+		}
+		return p, err
+	}
+
+	query := `
+		SELECT
+			(SELECT COALESCE(SUM(1 + guest_count), 0) FROM registrations
+			WHERE project_id = $1 AND status = 'registered' AND deleted_at IS NULL)
+			+ (SELECT COALESCE(SUM(held), 0) FROM (` + heldSeatsSQL + `) h WHERE project_id = $1 AND id <> $2)
+	`
+	err = tx.QueryRowContext(ctx, query, projectID, exceptReservationID).Scan(&p.taken)
+	return p, err
+}
+
+// queryGroupReservations loads the reservations matching the condition with their claimed seat counts
+func queryGroupReservations(ctx context.Context, q queryer, condition string, args ...any) (
+	[]GroupReservation, error,
+) {
+	query := `
+		SELECT gr.id, gr.group_id, gr.project_id, p.title, p.project_date, gr.seats,
+		COALESCE(SUM(1 + r.guest_count), 0), gr.invite_code, gr.release_at, gr.released_at, gr.created_at,
+		gr.updated_at
+		FROM group_reservations gr
+		JOIN groups g ON g.id = gr.group_id
+		JOIN projects p ON p.id = gr.project_id
+		LEFT JOIN registrations r ON r.group_reservation_id = gr.id AND r.status = 'registered'
+			AND r.deleted_at IS NULL
+		WHERE ` + condition + `
+		GROUP BY gr.id, p.id
+		ORDER BY p.project_date, gr.id
+	`
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservations := []GroupReservation{}
+	for rows.Next() {
+		var r GroupReservation
+		var releasedAt sql.NullTime
+		if err = rows.Scan(
+			&r.ID, &r.GroupID, &r.ProjectID, &r.ProjectTitle, &r.ProjectDate, &r.Seats, &r.Claimed,
+			&r.InviteCode, &r.ReleaseAt, &releasedAt, &r.CreatedAt, &r.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if releasedAt.Valid {
+			r.ReleasedAt = &releasedAt.Time
+		}
+		reservations = append(reservations, r)
+	}
+
+	return reservations, rows.Err()
+}
@@ -0,0 +1,45 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"serve/models"
+)
+
+func TestGroupReservationOpenSeats(t *testing.T) {
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		reservation models.GroupReservation
+		open        int
+	}{
+		{"unclaimed seats are held", models.GroupReservation{Seats: 10, Claimed: 4, ReleaseAt: now.Add(time.Hour)}, 6},
+		{"fully claimed", models.GroupReservation{Seats: 4, Claimed: 5, ReleaseAt: now.Add(time.Hour)}, 0},
+		{"past the release time", models.GroupReservation{Seats: 10, Claimed: 4, ReleaseAt: now}, 0},
+		{
+			"released by the leader",
+			models.GroupReservation{Seats: 10, Claimed: 4, ReleaseAt: now.Add(time.Hour), ReleasedAt: &earlier},
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.open, tt.reservation.OpenSeats(now))
+		})
+	}
+}
+
+func TestGroupValidate(t *testing.T) {
+	group := models.Group{Name: "  Tuesday Men's Group "}
+	assert.NoError(t, group.Validate())
+	assert.Equal(t, "Tuesday Men's Group", group.Name)
+	assert.Equal(t, models.GroupSmallGroup, group.Kind, "groups are small groups unless they say otherwise")
+
+	assert.ErrorIs(t, (&models.Group{Name: "Acme", Kind: "club"}).Validate(), models.ErrInvalidGroup)
+	assert.ErrorIs(t, (&models.Group{Kind: models.GroupCompany}).Validate(), models.ErrInvalidGroup)
+}
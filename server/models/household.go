@@ -0,0 +1,270 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// adultAge is the age at which a household member stops being a minor
+const adultAge = 18
+
+var (
+	// ErrInvalidMember is returned when a household member is missing a name or has an impossible birth year
+	ErrInvalidMember = errors.New("household members need a first name, and minors a birth year under 18 years ago")
+	// ErrNotHouseholdMember is returned when registering someone who is not in the registrant's household
+	ErrNotHouseholdMember = errors.New("only members of your household can be registered with you")
+)
+
+// Household is a volunteer's family. The primary contact registers and manages everyone else.
+type Household struct {
+	ID            int               `json:"id"`
+	Name          string            `json:"name"`
+	PrimaryUserID string            `json:"primary_user_id"`
+	Members       []HouseholdMember `json:"members"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// HouseholdMember is a spouse, child or other family member who volunteers with the primary contact
+type HouseholdMember struct {
+	ID           int       `json:"id"`
+	HouseholdID  int       `json:"household_id"`
+	FirstName    string    `json:"first_name"`
+	LastName     string    `json:"last_name"`
+	Relationship string    `json:"relationship"`
+	Minor        bool      `json:"minor"`
+	BirthYear    *int      `json:"birth_year"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// MemberHistory is every project a household member has been registered for, newest first
+type MemberHistory struct {
+	MemberID  int             `json:"member_id"`
+	FirstName string          `json:"first_name"`
+	LastName  string          `json:"last_name"`
+	Projects  []Participation `json:"projects"`
+}
+
+// Participation is one project a volunteer was registered for
+type Participation struct {
+	RegistrationID int       `json:"registration_id"`
+	ProjectID      int       `json:"project_id"`
+	Title          string    `json:"title"`
+	ProjectDate    time.Time `json:"project_date"`
+	Status         string    `json:"status"`
+}
+
+// Validate checks the member's name and, for minors, that the birth year makes them under 18
+func (m *HouseholdMember) Validate(now time.Time) error {
+	m.FirstName, m.LastName = strings.TrimSpace(m.FirstName), strings.TrimSpace(m.LastName)
+	if m.FirstName == "" {
+		return ErrInvalidMember
+	}
+	if m.BirthYear != nil && (*m.BirthYear < now.Year()-120 || *m.BirthYear > now.Year()) {
+		return ErrInvalidMember
+	}
+	if m.Minor && (m.BirthYear == nil || now.Year()-*m.BirthYear > adultAge) {
+		return ErrInvalidMember
+	}
+	return nil
+}
+
+// GetHouseholdByUser gets the household a user is the primary contact for, with its current members.
+// It returns nil if they have none.
+func GetHouseholdByUser(ctx context.Context, db *sql.DB, userID string) (*Household, error) {
+	query := `
+		SELECT id, name, primary_user_id, created_at, updated_at
+		FROM households
+		WHERE primary_user_id = $1
+	`
+
+	var h Household
+	err := db.QueryRowContext(ctx, query, userID).Scan(&h.ID, &h.Name, &h.PrimaryUserID, &h.CreatedAt, &h.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	h.Members, err = queryHouseholdMembers(ctx, db, `household_id = $1 AND deleted_at IS NULL`, h.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// SaveHousehold creates the user's household, or renames it if they already have one
+func SaveHousehold(ctx context.Context, db *sql.DB, h *Household) error {
+	query := `
+		INSERT INTO households (primary_user_id, name)
+		VALUES ($1, $2)
+		ON CONFLICT (primary_user_id) DO UPDATE SET name = EXCLUDED.name, updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+	h.Name = strings.TrimSpace(h.Name)
+	return db.QueryRowContext(ctx, query, h.PrimaryUserID, h.Name).Scan(&h.ID, &h.CreatedAt, &h.UpdatedAt)
+}
+
+// CreateHouseholdMember adds a member to a household
+func CreateHouseholdMember(ctx context.Context, db *sql.DB, m *HouseholdMember) error {
+	if err := m.Validate(time.Now()); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO household_members (household_id, first_name, last_name, relationship, minor, birth_year)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+	return db.QueryRowContext(
+		ctx, query, m.HouseholdID, m.FirstName, m.LastName, strings.TrimSpace(m.Relationship), m.Minor, m.BirthYear,
+	).Scan(&m.ID, &m.CreatedAt, &m.UpdatedAt)
+}
+
+// UpdateHouseholdMember updates a current member of a household
+func UpdateHouseholdMember(ctx context.Context, db *sql.DB, m *HouseholdMember) error {
+	if err := m.Validate(time.Now()); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE household_members
+		SET first_name = $3, last_name = $4, relationship = $5, minor = $6, birth_year = $7, updated_at = NOW()
+		WHERE id = $1 AND household_id = $2 AND deleted_at IS NULL
+		RETURNING created_at, updated_at
+	`
+	err := db.QueryRowContext(
+		ctx, query, m.ID, m.HouseholdID, m.FirstName, m.LastName, strings.TrimSpace(m.Relationship), m.Minor,
+		m.BirthYear,
+	).Scan(&m.CreatedAt, &m.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// DeleteHouseholdMember removes a member from a household. Their past registrations stay in the history.
+func DeleteHouseholdMember(ctx context.Context, db *sql.DB, householdID, memberID int) error {
+	return execExpectingRow(
+		ctx, db,
+		`UPDATE household_members SET deleted_at = NOW() WHERE id = $1 AND household_id = $2 AND deleted_at IS NULL`,
+		memberID, householdID,
+	)
+}
+
+// SelectHouseholdMembers picks out the household's current members with the given IDs, returning
+// ErrNotHouseholdMember if any of them is not one
+func SelectHouseholdMembers(household *Household, memberIDs []int) ([]HouseholdMember, error) {
+	if len(memberIDs) == 0 {
+		return nil, nil
+	}
+	if household == nil {
+		return nil, ErrNotHouseholdMember
+	}
+
+	byID := make(map[int]HouseholdMember, len(household.Members))
+	for _, m := range household.Members {
+		byID[m.ID] = m
+	}
+
+	selected := make([]HouseholdMember, 0, len(memberIDs))
+	seen := map[int]bool{}
+	for _, id := range memberIDs {
+		m, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: member %d", ErrNotHouseholdMember, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			selected = append(selected, m)
+		}
+	}
+	return selected, nil
+}
+
+// GetRegistrationMembers gets the household members coming along on a registration
+func GetRegistrationMembers(ctx context.Context, db *sql.DB, registrationID int) ([]HouseholdMember, error) {
+	return queryHouseholdMembers(
+		ctx, db, `id IN (SELECT member_id FROM registration_members WHERE registration_id = $1)`, registrationID,
+	)
+}
+
+// GetHouseholdHistory gets the projects each of the household's members, past and present, has been
+// registered for
+func GetHouseholdHistory(ctx context.Context, db *sql.DB, householdID int) ([]MemberHistory, error) {
+	query := `
+		SELECT m.id, m.first_name, m.last_name, r.id, p.id, p.title, p.project_date, r.status
+		FROM household_members m
+		JOIN registration_members rm ON rm.member_id = m.id
+		JOIN registrations r ON r.id = rm.registration_id
+		JOIN projects p ON p.id = r.project_id
+		WHERE m.household_id = $1
+		ORDER BY m.id, p.project_date DESC
+	`
+
+	rows, err := db.QueryContext(ctx, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []MemberHistory{}
+	for rows.Next() {
+		var memberID int
+		var first, last string
+		var p Participation
+		if err = rows.Scan(
+			&memberID, &first, &last, &p.RegistrationID, &p.ProjectID, &p.Title, &p.ProjectDate, &p.Status,
+		); err != nil {
+			return nil, err
+		}
+		if len(history) == 0 || history[len(history)-1].MemberID != memberID {
+			history = append(history, MemberHistory{MemberID: memberID, FirstName: first, LastName: last})
+		}
+		current := &history[len(history)-1]
+		current.Projects = append(current.Projects, p)
+	}
+
+	return history, rows.Err()
+}
+
+// queryHouseholdMembers loads the household members matching the condition
+func queryHouseholdMembers(ctx context.Context, db *sql.DB, condition string, args ...any) ([]HouseholdMember, error) {
+	query := `
+		SELECT id, household_id, first_name, last_name, relationship, minor, birth_year, created_at, updated_at
+		FROM household_members
+		WHERE ` + condition + `
+		ORDER BY created_at, id
+	`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []HouseholdMember{}
+	for rows.Next() {
+		var m HouseholdMember
+		var birthYear sql.NullInt64
+		if err = rows.Scan(
+			&m.ID, &m.HouseholdID, &m.FirstName, &m.LastName, &m.Relationship, &m.Minor, &birthYear,
+			&m.CreatedAt, &m.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if birthYear.Valid {
+			year := int(birthYear.Int64)
+			m.BirthYear = &year
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
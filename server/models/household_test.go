@@ -0,0 +1,70 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"serve/models"
+)
+
+func TestHouseholdMemberValidate(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	year := func(y int) *int { return &y }
+
+	tests := []struct {
+		name   string
+		member models.HouseholdMember
+		valid  bool
+	}{
+		{"adult without birth year", models.HouseholdMember{FirstName: "Ana"}, true},
+		{"minor with birth year", models.HouseholdMember{FirstName: "Ben", Minor: true, BirthYear: year(2015)}, true},
+		{
+			"minor turning 18 this year",
+			models.HouseholdMember{FirstName: "Cy", Minor: true, BirthYear: year(2008)},
+			true,
+		},
+		{"blank first name", models.HouseholdMember{FirstName: "  ", LastName: "Diaz"}, false},
+		{"minor without birth year", models.HouseholdMember{FirstName: "Eli", Minor: true}, false},
+		{
+			"minor born too long ago",
+			models.HouseholdMember{FirstName: "Fay", Minor: true, BirthYear: year(2000)},
+			false,
+		},
+		{"birth year in the future", models.HouseholdMember{FirstName: "Gus", BirthYear: year(2030)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.member.Validate(now)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, models.ErrInvalidMember)
+			}
+		})
+	}
+}
+
+func TestSelectHouseholdMembers(t *testing.T) {
+	household := &models.Household{
+		ID:      1,
+		Members: []models.HouseholdMember{{ID: 10, FirstName: "Ana"}, {ID: 11, FirstName: "Ben"}},
+	}
+
+	selected, err := models.SelectHouseholdMembers(household, []int{11, 11})
+	require.NoError(t, err)
+	require.Len(t, selected, 1, "duplicates are only registered once")
+	assert.Equal(t, "Ben", selected[0].FirstName)
+
+	_, err = models.SelectHouseholdMembers(household, []int{10, 12})
+	assert.ErrorIs(t, err, models.ErrNotHouseholdMember)
+
+	_, err = models.SelectHouseholdMembers(nil, []int{10})
+	assert.ErrorIs(t, err, models.ErrNotHouseholdMember, "volunteers without a household can only register themselves")
+
+	selected, err = models.SelectHouseholdMembers(nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, selected)
+}
@@ -0,0 +1,370 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Lead invitation statuses
+const (
+	LeadInvitationPending   = "pending"
+	LeadInvitationAccepted  = "accepted"
+	LeadInvitationDeclined  = "declined"
+	LeadInvitationWithdrawn = "withdrawn"
+)
+
+var (
+	// ErrInvitationExists is returned when the volunteer already has an open invitation to lead the project
+	ErrInvitationExists = errors.New("the volunteer already has an open invitation to lead this project")
+	// ErrInvitationAnswered is returned when an invitation has already been accepted, declined or withdrawn
+	ErrInvitationAnswered = errors.New("this invitation is no longer open")
+	// ErrProjectNotLeadable is returned when accepting an invitation to lead a deleted or cancelled project
+	ErrProjectNotLeadable = errors.New("this project has been cancelled or removed")
+)
+
+// LeadCandidate is a volunteer who said they are willing to lead, with the project they registered for
+type LeadCandidate struct {
+	UserID       string `json:"user_id"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	ProjectID    int    `json:"project_id"`
+	ProjectTitle string `json:"project_title"`
+	// PendingInvitations is how many invitations to lead the volunteer has not answered yet
+	PendingInvitations int `json:"pending_invitations"`
+}
+
+// UnledProject is an upcoming project without an active lead
+type UnledProject struct {
+	ID                 int       `json:"id"`
+	Title              string    `json:"title"`
+	ProjectDate        time.Time `json:"project_date"`
+	Area               string    `json:"area"`
+	Status             string    `json:"status"`
+	PendingInvitations int       `json:"pending_invitations"`
+}
+
+// LeadInvitation asks a volunteer to lead a project. The volunteer answers through a link with the token.
+type LeadInvitation struct {
+	ID           int        `json:"id"`
+	ProjectID    int        `json:"project_id"`
+	ProjectTitle string     `json:"project_title"`
+	ProjectDate  time.Time  `json:"project_date"`
+	UserID       string     `json:"user_id"`
+	FirstName    string     `json:"first_name"`
+	LastName     string     `json:"last_name"`
+	Email        string     `json:"email"`
+	Phone        string     `json:"phone"`
+	Token        string     `json:"-"`
+	Status       string     `json:"status"`
+	Message      string     `json:"message"`
+	InvitedBy    string     `json:"invited_by"`
+	RespondedAt  *time.Time `json:"responded_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// GetLeadCandidates gets the volunteers registered for a project on or after a date who are willing to lead,
+// leaving out those who already accepted an invitation to lead one of those projects
+func GetLeadCandidates(ctx context.Context, db *sql.DB, from time.Time) ([]LeadCandidate, error) {
+	query := `
+		SELECT u.id, u.first_name, u.last_name, u.email, u.phone, p.id, p.title,
+		(SELECT COUNT(*) FROM lead_invitations li WHERE li.user_id = u.id AND li.status = 'pending')
+		FROM registrations r
+		JOIN users u ON u.id = r.user_id AND u.deleted_at IS NULL
+		JOIN projects p ON p.id = r.project_id AND p.deleted_at IS NULL
+		WHERE r.lead_interest AND r.status = 'registered' AND r.deleted_at IS NULL AND p.project_date >= $1
+		AND NOT EXISTS (
+			SELECT 1 FROM lead_invitations li
+			JOIN projects led ON led.id = li.project_id
+			WHERE li.user_id = u.id AND li.status = 'accepted' AND led.project_date >= $1
+		)
+		ORDER BY u.last_name, u.first_name
+	`
+
+	rows, err := db.QueryContext(ctx, query, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := []LeadCandidate{}
+	for rows.Next() {
+		var c LeadCandidate
+		if err = rows.Scan(
+			&c.UserID, &c.FirstName, &c.LastName, &c.Email, &c.Phone, &c.ProjectID, &c.ProjectTitle,
+			&c.PendingInvitations,
+		); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
+
+// GetUnledProjects gets the projects on or after a date that have no active lead and have not been cancelled
+func GetUnledProjects(ctx context.Context, db *sql.DB, from time.Time) ([]UnledProject, error) {
+	query := `
+		SELECT p.id, p.title, p.project_date, p.area, p.status,
+		(SELECT COUNT(*) FROM lead_invitations li WHERE li.project_id = p.id AND li.status = 'pending')
+		FROM projects p
+		WHERE p.deleted_at IS NULL AND p.project_date >= $1 AND p.status NOT IN ($2, $3)
+		AND NOT (COALESCE(p.leads, '[]'::jsonb) @> '[{"active": true}]')
+		ORDER BY p.project_date, p.title
+	`
+
+	rows, err := db.QueryContext(ctx, query, from, StatusNotApproved, StatusDidNotOccur)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []UnledProject{}
+	for rows.Next() {
+		var p UnledProject
+		if err = rows.Scan(&p.ID, &p.Title, &p.ProjectDate, &p.Area, &p.Status, &p.PendingInvitations); err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+
+	return projects, rows.Err()
+}
+
+// CreateLeadInvitation invites a volunteer to lead a project, filling in the rest of the invitation
+func CreateLeadInvitation(ctx context.Context, db *sql.DB, inv *LeadInvitation) error {
+	query := `
+		INSERT INTO lead_invitations (project_id, user_id, token, message, invited_by)
+		SELECT p.id, u.id, $3, $4, $5
+		FROM projects p, users u
+		WHERE p.id = $1 AND p.deleted_at IS NULL AND u.id = $2 AND u.deleted_at IS NULL
+		RETURNING id
+	`
+	err := db.QueryRowContext(
+		ctx, query, inv.ProjectID, inv.UserID, inv.Token, strings.TrimSpace(inv.Message), inv.InvitedBy,
+	).Scan(&inv.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err = uniqueViolationAs(err, ErrInvitationExists); err != nil {
+		return err
+	}
+
+	created, err := GetLeadInvitation(ctx, db, inv.ID)
+	if err != nil {
+		return err
+	}
+	*inv = *created
+	return nil
+}
+
+// GetLeadInvitations gets the invitations to lead, newest first, optionally only those with a status
+func GetLeadInvitations(ctx context.Context, db *sql.DB, status string) ([]LeadInvitation, error) {
+	return queryLeadInvitations(ctx, db, `$1 = '' OR li.status = $1`, status)
+}
+
+// GetLeadInvitation gets an invitation by its ID
+func GetLeadInvitation(ctx context.Context, db *sql.DB, id int) (*LeadInvitation, error) {
+	return getLeadInvitation(ctx, db, `li.id = $1`, id)
+}
+
+// GetLeadInvitationByToken gets the invitation a volunteer's link points to
+func GetLeadInvitationByToken(ctx context.Context, db *sql.DB, token string) (*LeadInvitation, error) {
+	return getLeadInvitation(ctx, db, `li.token = $1`, token)
+}
+
+// WithdrawLeadInvitation takes back an invitation the volunteer has not answered, returning it
+func WithdrawLeadInvitation(ctx context.Context, db *sql.DB, id int) (*LeadInvitation, error) {
+	query := `
+		UPDATE lead_invitations SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3
+		RETURNING updated_at
+	`
+
+	inv, err := GetLeadInvitation(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil {
+		return nil, ErrNotFound
+	}
+
+	err = db.QueryRowContext(ctx, query, LeadInvitationWithdrawn, id, LeadInvitationPending).Scan(&inv.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvitationAnswered
+	}
+	if err != nil {
+		return nil, err
+	}
+	inv.Status = LeadInvitationWithdrawn
+	return inv, nil
+}
+
+// RespondToLeadInvitation records the volunteer's answer to an invitation. Accepting adds them to the
+// project's leads and gives them the project-lead role for the project, granted by whoever invited them.
+func RespondToLeadInvitation(ctx context.Context, db *sql.DB, token string, accept bool) (*LeadInvitation, error) {
+	inv, err := GetLeadInvitationByToken(ctx, db, token)
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil {
+		return nil, ErrNotFound
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	status := LeadInvitationDeclined
+	if accept {
+		status = LeadInvitationAccepted
+	}
+
+	var respondedAt time.Time
+	err = tx.QueryRowContext(ctx, `
+		UPDATE lead_invitations SET status = $1, responded_at = NOW(), updated_at = NOW()
+		WHERE id = $2 AND status = $3
+		RETURNING responded_at
+	`, status, inv.ID, LeadInvitationPending).Scan(&respondedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvitationAnswered
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if accept {
+		if err = addProjectLead(ctx, tx, inv); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	inv.Status, inv.RespondedAt, inv.UpdatedAt = status, &respondedAt, respondedAt
+	return inv, nil
+}
+
+// AddLead adds a lead to a project's leads, or makes them active again if they are already listed
+func AddLead(leads json.RawMessage, lead Lead) (json.RawMessage, error) {
+	var list []Lead
+	switch strings.TrimSpace(string(leads)) {
+	case "", "{}", "[]", "null":
+		list = []Lead{}
+	default:
+		if err := json.Unmarshal(leads, &list); err != nil {
+			return nil, err
+		}
+	}
+
+	found := false
+	email := strings.TrimSpace(lead.Email)
+	for i := range list {
+		if email != "" && strings.EqualFold(strings.TrimSpace(list[i].Email), email) {
+			list[i].Active, found = true, true
+		}
+	}
+	if !found {
+		lead.Active = true
+		list = append(list, lead)
+	}
+
+	return json.Marshal(list)
+}
+
+// addProjectLead adds the invited volunteer to the project's leads and gives them its project-lead role,
+// as long as the project has not been deleted or cancelled
+func addProjectLead(ctx context.Context, tx *sql.Tx, inv *LeadInvitation) error {
+	var leads []byte
+	var status string
+	var deleted bool
+	if err := tx.QueryRowContext(
+		ctx, `SELECT leads, status, deleted_at IS NOT NULL FROM projects WHERE id = $1 FOR UPDATE`, inv.ProjectID,
+	).Scan(&leads, &status, &deleted); err != nil {
+		return err
+	}
+	if deleted || IsCancelledStatus(status) {
+		return ErrProjectNotLeadable
+	}
+
+	updated, err := AddLead(leads, Lead{
+		Name:  strings.TrimSpace(inv.FirstName + " " + inv.LastName),
+		Email: inv.Email,
+		Phone: inv.Phone,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(
+		ctx, `UPDATE projects SET leads = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, updated, inv.ProjectID,
+	); err != nil {
+		return err
+	}
+
+	// volunteers who only registered by email hold the role under their user ID until they sign in and
+	// their account is linked
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO user_roles (subject, role, project_id, granted_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`, inv.UserID, RoleProjectLead, inv.ProjectID, inv.InvitedBy)
+	return err
+}
+
+// getLeadInvitation loads the single invitation matching the condition, or nil if there is none
+func getLeadInvitation(ctx context.Context, db *sql.DB, condition string, arg any) (*LeadInvitation, error) {
+	invitations, err := queryLeadInvitations(ctx, db, condition, arg)
+	if err != nil || len(invitations) == 0 {
+		return nil, err
+	}
+	return &invitations[0], nil
+}
+
+// queryLeadInvitations loads the invitations matching the condition with their project and volunteer
+func queryLeadInvitations(ctx context.Context, db *sql.DB, condition string, args ...any) ([]LeadInvitation, error) {
+	query := `
+		SELECT li.id, li.project_id, p.title, p.project_date, li.user_id, u.first_name, u.last_name, u.email,
+		u.phone, li.token, li.status, li.message, li.invited_by, li.responded_at, li.created_at, li.updated_at
+		FROM lead_invitations li
+		JOIN projects p ON p.id = li.project_id
+		JOIN users u ON u.id = li.user_id
+		WHERE ` + condition + `
+		ORDER BY li.created_at DESC
+	`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []LeadInvitation{}
+	for rows.Next() {
+		var inv LeadInvitation
+		var respondedAt sql.NullTime
+		if err = rows.Scan(
+			&inv.ID, &inv.ProjectID, &inv.ProjectTitle, &inv.ProjectDate, &inv.UserID, &inv.FirstName,
+			&inv.LastName, &inv.Email, &inv.Phone, &inv.Token, &inv.Status, &inv.Message, &inv.InvitedBy,
+			&respondedAt, &inv.CreatedAt, &inv.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if respondedAt.Valid {
+			inv.RespondedAt = &respondedAt.Time
+		}
+		invitations = append(invitations, inv)
+	}
+
+	return invitations, rows.Err()
+}
@@ -0,0 +1,54 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"serve/models"
+)
+
+func TestAddLead(t *testing.T) {
+	jane := models.Lead{Name: "Jane Doe", Email: "jane@example.com", Phone: "555-0100"}
+
+	tests := []struct {
+		name  string
+		leads string
+		want  []models.Lead
+	}{
+		{name: "no leads yet", leads: `[]`, want: []models.Lead{{
+			Name: "Jane Doe", Email: "jane@example.com", Phone: "555-0100", Active: true,
+		}}},
+		{name: "leads left as an empty object", leads: `{}`, want: []models.Lead{{
+			Name: "Jane Doe", Email: "jane@example.com", Phone: "555-0100", Active: true,
+		}}},
+		{
+			name:  "alongside another lead",
+			leads: `[{"name": "Sam Roe", "email": "sam@example.com", "phone": "", "active": false}]`,
+			want: []models.Lead{
+				{Name: "Sam Roe", Email: "sam@example.com"},
+				{Name: "Jane Doe", Email: "jane@example.com", Phone: "555-0100", Active: true},
+			},
+		},
+		{
+			name:  "already listed but inactive",
+			leads: `[{"name": "Jane", "email": "JANE@example.com", "phone": "555-0199", "active": false}]`,
+			want:  []models.Lead{{Name: "Jane", Email: "JANE@example.com", Phone: "555-0199", Active: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := models.AddLead(json.RawMessage(tt.leads), jane)
+			require.NoError(t, err)
+
+			var got []models.Lead
+			require.NoError(t, json.Unmarshal(updated, &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := models.AddLead(json.RawMessage(`"not leads"`), jane)
+	assert.Error(t, err)
+}
@@ -0,0 +1,215 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrOrganizationExists is returned when an organization with the same name already exists
+var ErrOrganizationExists = errors.New("an organization with this name already exists")
+
+// Organization represents a partner organization that hosts projects
+type Organization struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Website      string    `json:"website"`
+	ContactName  string    `json:"contact_name"`
+	ContactEmail string    `json:"contact_email"`
+	ContactPhone string    `json:"contact_phone"`
+	LogoURL      string    `json:"logo_url"`
+	Address      string    `json:"address"`
+	Notes        string    `json:"notes"`
+	ProjectCount int       `json:"project_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// OrganizationYear summarizes what an organization hosted in a single year
+type OrganizationYear struct {
+	Year       int                   `json:"year"`
+	Projects   []OrganizationProject `json:"projects"`
+	Capacity   int                   `json:"capacity"`
+	Volunteers int                   `json:"volunteers"`
+}
+
+// OrganizationProject is a project hosted by an organization, with its volunteer count
+type OrganizationProject struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	Status      string    `json:"status"`
+	ProjectDate time.Time `json:"project_date"`
+	MaxCapacity int       `json:"max_capacity"`
+	Volunteers  int       `json:"volunteers"`
+}
+
+const organizationColumns = `
+		o.id, o.name, o.website, o.contact_name, o.contact_email, o.contact_phone, o.logo_url, o.address, o.notes,
+		o.created_at, o.updated_at,
+		(SELECT COUNT(*) FROM projects p WHERE p.organization_id = o.id AND p.deleted_at IS NULL)
+`
+
+// scanOrganization scans a row selected with organizationColumns
+func scanOrganization(row interface{ Scan(...any) error }, o *Organization) error {
+	return row.Scan(
+		&o.ID, &o.Name, &o.Website, &o.ContactName, &o.ContactEmail, &o.ContactPhone, &o.LogoURL, &o.Address,
+		&o.Notes, &o.CreatedAt, &o.UpdatedAt, &o.ProjectCount,
+	)
+}
+
+// GetAllOrganizations retrieves all organizations ordered by name
+func GetAllOrganizations(ctx context.Context, db *sql.DB) ([]Organization, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+organizationColumns+` FROM organizations o ORDER BY lower(o.name)`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	organizations := []Organization{}
+	for rows.Next() {
+		var o Organization
+		if err = scanOrganization(rows, &o); err != nil {
+			return nil, err
+		}
+		organizations = append(organizations, o)
+	}
+
+	return organizations, rows.Err()
+}
+
+// GetOrganizationByID retrieves an organization by its ID
+func GetOrganizationByID(ctx context.Context, db *sql.DB, id int) (*Organization, error) {
+	var o Organization
+	row := db.QueryRowContext(ctx, `SELECT `+organizationColumns+` FROM organizations o WHERE o.id = $1`, id)
+	if err := scanOrganization(row, &o); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Organization not found
+		}
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+// CreateOrganization creates a new organization
+func CreateOrganization(ctx context.Context, db *sql.DB, o *Organization) error {
+	query := `
+		INSERT INTO organizations (name, website, contact_name, contact_email, contact_phone, logo_url, address, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := db.QueryRowContext(
+		ctx, query, o.Name, o.Website, o.ContactName, o.ContactEmail, o.ContactPhone, o.LogoURL, o.Address, o.Notes,
+	).Scan(&o.ID, &o.CreatedAt, &o.UpdatedAt)
+	return uniqueViolationAs(err, ErrOrganizationExists)
+}
+
+// UpdateOrganization updates an existing organization
+func UpdateOrganization(ctx context.Context, db *sql.DB, o *Organization) error {
+	query := `
+		UPDATE organizations
+		SET name = $1, website = $2, contact_name = $3, contact_email = $4, contact_phone = $5, logo_url = $6,
+		address = $7, notes = $8, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $9
+		RETURNING updated_at
+	`
+
+	err := db.QueryRowContext(
+		ctx, query, o.Name, o.Website, o.ContactName, o.ContactEmail, o.ContactPhone, o.LogoURL, o.Address, o.Notes,
+		o.ID,
+	).Scan(&o.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return uniqueViolationAs(err, ErrOrganizationExists)
+}
+
+// DeleteOrganization deletes an organization. Its projects are kept and simply unlinked.
+func DeleteOrganization(ctx context.Context, db *sql.DB, id int) error {
+	return execExpectingRow(ctx, db, `DELETE FROM organizations WHERE id = $1`, id)
+}
+
+// GetOrganizationRoster gets the active registrations across all of an organization's projects
+func GetOrganizationRoster(ctx context.Context, db *sql.DB, organizationID int) ([]Registration, error) {
+	query := `
+		SELECT r.id, r.user_id, r.project_id, r.status, r.guest_count, r.lead_interest,
+		r.created_at, r.updated_at,
+		u.email, u.first_name, u.last_name, u.phone, u.text_permission,
+		p.title, p.time, p.project_date
+		FROM registrations r
+		JOIN users u ON r.user_id = u.id
+		JOIN projects p ON r.project_id = p.id
+		WHERE p.organization_id = $1
+		AND r.status = 'registered'
+		AND r.deleted_at IS NULL AND u.deleted_at IS NULL AND p.deleted_at IS NULL
+		ORDER BY p.project_date, p.title, u.last_name, u.first_name
+	`
+
+	rows, err := db.QueryContext(ctx, query, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	registrations := []Registration{}
+	for rows.Next() {
+		r := Registration{User: &User{}, Project: &Project{}}
+		if err = rows.Scan(
+			&r.ID, &r.UserID, &r.ProjectID, &r.Status, &r.GuestCount, &r.LeadInterest,
+			&r.CreatedAt, &r.UpdatedAt,
+			&r.User.Email, &r.User.FirstName, &r.User.LastName, &r.User.Phone, &r.User.TextPermission,
+			&r.Project.Title, &r.Project.Time, &r.Project.ProjectDate,
+		); err != nil {
+			return nil, err
+		}
+		r.User.ID = r.UserID
+		r.Project.ID = r.ProjectID
+		registrations = append(registrations, r)
+	}
+
+	return registrations, rows.Err()
+}
+
+// GetOrganizationHistory summarizes the projects an organization hosted per year, newest year first
+func GetOrganizationHistory(ctx context.Context, db *sql.DB, organizationID int) ([]OrganizationYear, error) {
+	query := `
+		SELECT EXTRACT(YEAR FROM p.project_date)::int, p.id, p.title, p.status::text, p.project_date, p.max_capacity,
+		COALESCE((
+			SELECT SUM(1 + r.guest_count) FROM registrations r
+			WHERE r.project_id = p.id AND r.status <> 'cancelled' AND r.deleted_at IS NULL
+		), 0)
+		FROM projects p
+		WHERE p.organization_id = $1
+		AND p.deleted_at IS NULL
+		AND p.status NOT IN ('pending', 'not_approved')
+		ORDER BY 1 DESC, p.project_date, p.title
+	`
+
+	rows, err := db.QueryContext(ctx, query, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	years := []OrganizationYear{}
+	for rows.Next() {
+		var year int
+		var p OrganizationProject
+		err = rows.Scan(&year, &p.ID, &p.Title, &p.Status, &p.ProjectDate, &p.MaxCapacity, &p.Volunteers)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(years) == 0 || years[len(years)-1].Year != year {
+			years = append(years, OrganizationYear{Year: year})
+		}
+		y := &years[len(years)-1]
+		y.Projects = append(y.Projects, p)
+		y.Capacity += p.MaxCapacity
+		y.Volunteers += p.Volunteers
+	}
+
+	return years, rows.Err()
+}
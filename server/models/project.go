@@ -7,7 +7,6 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -16,6 +15,7 @@ import (
 type Project struct {
 	ID              int                `json:"id"`
 	GoogleID        *int               `json:"google_id"`
+	OrganizationID  *int               `json:"organization_id"`
 	Title           string             `json:"title"`
 	Description     string             `json:"description"`
 	Website         string             `json:"website"`
@@ -23,6 +23,7 @@ type Project struct {
 	ProjectDate     time.Time          `json:"project_date"`
 	MaxCapacity     int                `json:"max_capacity"`
 	CurrentReg      int                `json:"current_registrations"`
+	ReservedSeats   int                `json:"reserved_seats"`
 	Area            string             `json:"area"`
 	LocationAddress string             `json:"location_address"`
 	Latitude        float64            `json:"latitude"`
@@ -35,9 +36,22 @@ type Project struct {
 	Ages            string             `json:"ages,omitempty"`
 	Leads           json.RawMessage    `json:"leads,omitempty"`
 	Status          string             `json:"status"`
+	StatusReason    string             `json:"status_reason"`
 	CreatedAt       time.Time          `json:"created_at"`
 	UpdatedAt       time.Time          `json:"updated_at"`
+	Revision        int                `json:"revision"`
 	LeadsData       []Lead             `json:"leads_data"`
+	DistanceKm      *float64           `json:"distance_km,omitempty"`
+	// ExactAddress is the private address of a project whose LocationAddress is only a public area. It
+	// is shown to registered volunteers and leads from the reveal time on.
+	ExactAddress     string     `json:"exact_address"`
+	ExactLatitude    float64    `json:"exact_latitude"`
+	ExactLongitude   float64    `json:"exact_longitude"`
+	LocationRevealAt *time.Time `json:"location_reveal_at"`
+	// EarlyAccess projects only take registrations from group leaders and the groups they invite
+	EarlyAccess bool `json:"early_access"`
+	// CloseHours override the event's registration and cancellation deadlines for this project
+	CloseHours
 }
 
 type Lead struct {
@@ -57,76 +71,32 @@ type ProjectAccessory struct {
 	Name string `json:"name"`
 }
 
-// GetAllProjects retrieves all projects from the database
-func GetAllProjects(ctx context.Context, db *sql.DB) ([]Project, error) {
-	query := `
-                SELECT p.id, p.google_id, p.title, p.description, p.website, p.time, 
-                p.max_capacity, p.area, p.location_address, p.latitude, p.longitude,
-                p.created_at, p.updated_at, p.ages, p.serve_lead_name, p.serve_lead_email, p.project_date, p.leads, p.status,
-                COALESCE(COUNT(CASE WHEN r.status = 'registered' THEN 1 END) + SUM(CASE WHEN r.status = 'registered' THEN r.guest_count ELSE 0 END), 0) as current_registrations,
-                COALESCE(pt.type_ids, '') as type_ids
-                FROM projects p
-                LEFT JOIN registrations r ON p.id = r.project_id 
-                LEFT JOIN (
-                    SELECT project_id, array_to_string(array_agg(type_id), ',') as type_ids
-                    FROM project_types
-                    GROUP BY project_id
-                ) pt ON p.id = pt.project_id
-                GROUP BY p.id, pt.type_ids
-        `
-
-	rows, err := db.QueryContext(ctx, query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var projects []Project
-	for rows.Next() {
-		var p Project
-		var typeIDsStr string
-		if err = rows.Scan(
-			&p.ID, &p.GoogleID, &p.Title, &p.Description, &p.Website, &p.Time,
-			&p.MaxCapacity, &p.Area, &p.LocationAddress, &p.Latitude, &p.Longitude,
-			&p.CreatedAt, &p.UpdatedAt, &p.Ages, &p.ServeLeadName,
-			&p.ServeLeadEmail, &p.ProjectDate, &p.Leads, &p.Status, &p.CurrentReg, &typeIDsStr,
-		); err != nil {
-			return nil, err
-		}
-
-		// Convert comma-separated string to Types
-		if typeIDsStr != "" {
-			for _, idStr := range strings.Split(typeIDsStr, ",") {
-				if id, err := strconv.Atoi(idStr); err == nil {
-					p.Types = append(p.Types, ProjectAccessory{ID: id})
-				}
-			}
-		}
-		projects = append(projects, p)
-	}
-
-	return projects, nil
-}
-
 // GetProjectByID retrieves a project by its ID
 func GetProjectByID(ctx context.Context, db *sql.DB, id int) (*Project, error) {
 	query := `
                 SELECT p.id, p.title, p.description, p.website, p.time, p.project_date, 
-                p.max_capacity, p.area, p.location_address, p.latitude, p.longitude, p.serve_lead_id,
+                p.max_capacity, p.area, p.location_address, p.latitude, p.longitude, COALESCE(p.serve_lead_id, ''),
                 p.serve_lead_name, p.serve_lead_email, p.created_at, p.updated_at, p.ages, p.leads, p.status,
+                p.status_reason, p.organization_id, p.exact_address, p.exact_latitude, p.exact_longitude,
+                p.location_reveal_at, p.early_access, p.registration_close_hours, p.cancellation_close_hours,
+                p.revision,
                 COALESCE(COUNT(CASE WHEN r.status = 'registered' THEN 1 END) + SUM(CASE WHEN r.status = 'registered' THEN r.guest_count ELSE 0 END), 0) as current_registrations
                 FROM projects p
-                LEFT JOIN registrations r ON p.id = r.project_id
-                WHERE p.id = $1
+                LEFT JOIN registrations r ON p.id = r.project_id AND r.deleted_at IS NULL
+                WHERE p.id = $1 AND p.deleted_at IS NULL
                 GROUP BY p.id
         `
 
 	var p Project
 	var leadsJSON []byte
+	var registrationCloseHours, cancellationCloseHours sql.NullInt64
 	err := db.QueryRowContext(ctx, query, id).Scan(
 		&p.ID, &p.Title, &p.Description, &p.Website, &p.Time, &p.ProjectDate,
 		&p.MaxCapacity, &p.Area, &p.LocationAddress, &p.Latitude, &p.Longitude, &p.ServeLeadID,
-		&p.ServeLeadName, &p.ServeLeadEmail, &p.CreatedAt, &p.UpdatedAt, &p.Ages, &leadsJSON, &p.Status, &p.CurrentReg,
+		&p.ServeLeadName, &p.ServeLeadEmail, &p.CreatedAt, &p.UpdatedAt, &p.Ages, &leadsJSON, &p.Status,
+		&p.StatusReason, &p.OrganizationID, &p.ExactAddress, &p.ExactLatitude, &p.ExactLongitude,
+		&p.LocationRevealAt, &p.EarlyAccess, &registrationCloseHours, &cancellationCloseHours, &p.Revision,
+		&p.CurrentReg,
 	)
 
 	if err != nil {
@@ -135,6 +105,10 @@ func GetProjectByID(ctx context.Context, db *sql.DB, id int) (*Project, error) {
 		}
 		return nil, err
 	}
+	p.CloseHours = CloseHours{
+		Registration: nullableInt(registrationCloseHours),
+		Cancellation: nullableInt(cancellationCloseHours),
+	}
 
 	// Initialize empty leads slice
 	emptyLeads := json.RawMessage("[]")
@@ -179,14 +153,33 @@ func CreateProject(ctx context.Context, db *sql.DB, project *Project) error {
 		return err
 	}
 
+	if err = insertProject(ctx, tx, project); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertProject inserts a project and its accessories within a transaction. Projects without a
+// status are created open.
+func insertProject(ctx context.Context, tx *sql.Tx, project *Project) error {
+	if project.Status == "" {
+		project.Status = StatusOpen
+	}
+
 	query := `
                 INSERT INTO projects (google_id, title, description, website, time, project_date, max_capacity, 
-                                    area, location_address, latitude, longitude, serve_lead_id, serve_lead_name, serve_lead_email)
-                VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+                                    area, location_address, latitude, longitude, serve_lead_id, serve_lead_name, serve_lead_email,
+                                    status, ages, organization_id, exact_address, exact_latitude, exact_longitude,
+                                    location_reveal_at, early_access, registration_close_hours,
+                                    cancellation_close_hours)
+                VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), $13, $14, $15,
+                        COALESCE(NULLIF($16, ''), 'All Ages'), $17, $18, $19, $20, $21, $22, $23, $24)
                 RETURNING id, created_at, updated_at
         `
 
-	err = tx.QueryRowContext(
+	err := tx.QueryRowContext(
 		ctx,
 		query,
 		project.GoogleID,
@@ -203,20 +196,27 @@ func CreateProject(ctx context.Context, db *sql.DB, project *Project) error {
 		project.ServeLeadID,
 		project.ServeLeadName,
 		project.ServeLeadEmail,
+		project.Status,
+		project.Ages,
+		project.OrganizationID,
+		project.ExactAddress,
+		project.ExactLatitude,
+		project.ExactLongitude,
+		project.LocationRevealAt,
+		project.EarlyAccess,
+		project.CloseHours.Registration,
+		project.CloseHours.Cancellation,
 	).Scan(&project.ID, &project.CreatedAt, &project.UpdatedAt)
 	if err != nil {
 		log.Println("error creating project: ", err)
-		tx.Rollback()
 		return err
 	}
 
 	if err = insertAccessories(ctx, tx, project); err != nil {
 		log.Println("error creating project associations: ", err)
-		tx.Rollback()
 		return err
 	}
 
-	tx.Commit()
 	return nil
 }
 
@@ -244,8 +244,11 @@ func UpdateProject(ctx context.Context, db *sql.DB, project *Project) error {
                 UPDATE projects
                 SET google_id=$13, title = $1, description = $2, website = $3, time = $4, project_date = $5, 
                 max_capacity = $6, area = $7, location_address = $8, latitude = $9, longitude = $10,
-                updated_at = CURRENT_TIMESTAMP, ages = $11, serve_lead_name=$14, serve_lead_email=$15, leads=$16
-                WHERE id = $12
+                updated_at = CURRENT_TIMESTAMP, ages = $11, serve_lead_name=$14, serve_lead_email=$15, leads=$16,
+                organization_id = $17, exact_address = $18, exact_latitude = $19, exact_longitude = $20,
+                location_reveal_at = $21, early_access = $22, registration_close_hours = $23,
+                cancellation_close_hours = $24
+                WHERE id = $12 AND deleted_at IS NULL
                 RETURNING updated_at`
 	err = tx.QueryRowContext(
 		ctx,
@@ -266,6 +269,14 @@ func UpdateProject(ctx context.Context, db *sql.DB, project *Project) error {
 		project.ServeLeadName,
 		project.ServeLeadEmail,
 		leadsJSON,
+		project.OrganizationID,
+		project.ExactAddress,
+		project.ExactLatitude,
+		project.ExactLongitude,
+		project.LocationRevealAt,
+		project.EarlyAccess,
+		project.CloseHours.Registration,
+		project.CloseHours.Cancellation,
 	).Scan(&project.UpdatedAt)
 	if err != nil {
 		tx.Rollback()
@@ -289,18 +300,30 @@ func UpdateProject(ctx context.Context, db *sql.DB, project *Project) error {
 	return nil
 }
 
-// DeleteProject deletes a project by its ID
+// DeleteProject soft deletes a project by its ID, along with its active registrations. The
+// registrations share the project's deleted_at so that restoring the project restores them too.
 func DeleteProject(ctx context.Context, db *sql.DB, id int) error {
-	query := `DELETE FROM projects WHERE id = $1`
-	_, err := db.ExecContext(ctx, query, id)
-	return err
-}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var deletedAt time.Time
+	query := `UPDATE projects SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL RETURNING deleted_at`
+	if err = tx.QueryRowContext(ctx, query, id).Scan(&deletedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	query = `UPDATE registrations SET deleted_at = $1 WHERE project_id = $2 AND deleted_at IS NULL`
+	if _, err = tx.ExecContext(ctx, query, deletedAt, id); err != nil {
+		return err
+	}
 
-// UpdateProjectActiveStatus updates the active status of a project
-func UpdateProjectActiveStatus(ctx context.Context, db *sql.DB, id int, active string) error {
-	query := `UPDATE projects SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
-	_, err := db.ExecContext(ctx, query, active, id)
-	return err
+	return tx.Commit()
 }
 
 func insertAccessories(ctx context.Context, tx *sql.Tx, p *Project) error {
@@ -6,8 +6,13 @@ import (
 	"errors"
 	"math"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+// ErrNoCapacity is returned when a project does not have room for a registration and its guests
+var ErrNoCapacity = errors.New("capacity not available for total # of volunteers requested")
+
 // Registration represents a user's registration for a project
 type Registration struct {
 	ID           int       `json:"id"`
@@ -21,59 +26,40 @@ type Registration struct {
 	User         *User     `json:"user,omitempty"`
 	Project      *Project  `json:"project,omitempty"`
 	Recaptcha    string    `json:"recaptcha"`
+	// Members are the household members coming along, who are counted in GuestCount
+	Members []HouseholdMember `json:"members,omitempty"`
+	// GroupReservationID is the group reservation whose seats the registration claimed
+	GroupReservationID *int `json:"group_reservation_id,omitempty"`
 }
 
-// RegisterForProject registers a user for a project
-func RegisterForProject(db *sql.DB, userID string, projectID int, guestCount int, isLeadInterested bool) (
-	*Registration, error,
-) {
+// RegisterForProject registers a user for a project, along with the household members coming with them.
+// Spots held by group reservations are not open to everyone; a registration made through a group's invite
+// takes its spots from that group's seats first.
+func RegisterForProject(
+	ctx context.Context, db *sql.DB, userID string, projectID int, guestCount int, isLeadInterested bool,
+	groupReservationID *int, members []HouseholdMember,
+) (*Registration, error) {
 	// Begin transaction
-	tx, err := db.Begin()
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
+	defer tx.Rollback() // no-op once committed
 
-	// Check if project exists and has capacity
-	var currentCount int
-	var maxCapacity int
-	err = tx.QueryRow(
-		`
-								SELECT 
-												COALESCE(COUNT(r.id), 0) + COALESCE(SUM(r.guest_count), 0), 
-												p.max_capacity 
-								FROM projects p
-								LEFT JOIN registrations r ON p.id = r.project_id AND r.status = 'registered'
-								WHERE p.id = $1
-								GROUP BY p.id
-				`, projectID,
-	).Scan(&currentCount, &maxCapacity)
-
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+	// Check the project exists, is open and has room for the user and their guests
+	if err = claimProjectSpots(ctx, tx, projectID, 1+guestCount, groupReservationID); err != nil {
+		if errors.Is(err, ErrNotFound) {
 			return nil, errors.New("project not found")
 		}
 		return nil, err
 	}
 
-	// Calculate total spots needed (user + guests)
-	totalSpots := 1 + guestCount
-
-	// Check if there's enough capacity
-	if currentCount+totalSpots > maxCapacity {
-		return nil, errors.New("capacity not available for total # of volunteers requested")
-	}
-
 	// Check if user is already registered for this project
 	var existingID int
 	err = tx.QueryRow(
 		`
 								SELECT id FROM registrations
-								WHERE user_id = $1 AND project_id = $2 AND status = 'registered'
+								WHERE user_id = $1 AND project_id = $2 AND status = 'registered' AND deleted_at IS NULL
 				`, userID, projectID,
 	).Scan(&existingID)
 
@@ -85,25 +71,41 @@ func RegisterForProject(db *sql.DB, userID string, projectID int, guestCount int
 
 	// Create registration
 	reg := &Registration{
-		UserID:       userID,
-		ProjectID:    projectID,
-		Status:       "registered",
-		GuestCount:   guestCount,
-		LeadInterest: isLeadInterested,
+		UserID:             userID,
+		ProjectID:          projectID,
+		Status:             "registered",
+		GuestCount:         guestCount,
+		LeadInterest:       isLeadInterested,
+		GroupReservationID: groupReservationID,
 	}
 
 	err = tx.QueryRow(
 		`
-								INSERT INTO registrations (user_id, project_id, status, guest_count, lead_interest)
-								VALUES ($1, $2, $3, $4, $5)
+								INSERT INTO registrations (user_id, project_id, status, guest_count, lead_interest,
+									group_reservation_id)
+								VALUES ($1, $2, $3, $4, $5, $6)
 								RETURNING id, created_at, updated_at
-				`, reg.UserID, reg.ProjectID, reg.Status, reg.GuestCount, reg.LeadInterest,
+				`, reg.UserID, reg.ProjectID, reg.Status, reg.GuestCount, reg.LeadInterest, reg.GroupReservationID,
 	).Scan(&reg.ID, &reg.CreatedAt, &reg.UpdatedAt)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if len(members) > 0 {
+		ids := make([]int64, len(members))
+		for i, m := range members {
+			ids[i] = int64(m.ID)
+		}
+		if _, err = tx.Exec(
+			`INSERT INTO registration_members (registration_id, member_id) SELECT $1, unnest($2::int[])`,
+			reg.ID, pq.Array(ids),
+		); err != nil {
+			return nil, err
+		}
+		reg.Members = members
+	}
+
 	// Commit transaction
 	err = tx.Commit()
 	if err != nil {
@@ -113,11 +115,40 @@ func RegisterForProject(db *sql.DB, userID string, projectID int, guestCount int
 	return reg, nil
 }
 
-// CancelRegistration deletes a registration
+// claimProjectSpots locks the project so concurrent registrations and reservations cannot oversell it,
+// then checks it is open with room for the spots. Seats the registrant's group reservation is holding
+// count as theirs.
+func claimProjectSpots(ctx context.Context, tx *sql.Tx, projectID, spots int, groupReservationID *int) error {
+	project, err := lockProjectSeats(ctx, tx, projectID, 0)
+	if err != nil {
+		return err
+	}
+	if project.status != StatusOpen {
+		return ErrProjectNotOpen
+	}
+
+	var groupSeats int
+	if groupReservationID != nil {
+		err = tx.QueryRowContext(
+			ctx, `SELECT COALESCE(SUM(held), 0) FROM (`+heldSeatsSQL+`) h WHERE id = $1`, *groupReservationID,
+		).Scan(&groupSeats)
+		if err != nil {
+			return err
+		}
+	}
+
+	if project.taken+max(spots-groupSeats, 0) > project.capacity {
+		return ErrNoCapacity
+	}
+	return nil
+}
+
+// CancelRegistration cancels a registration, keeping it in the trash so it can be restored
 func CancelRegistration(ctx context.Context, db *sql.DB, userID string, projectID int) error {
 	query := `
-								DELETE FROM registrations 
-								WHERE user_id = $1 AND project_id = $2 AND status = 'registered'
+								UPDATE registrations
+								SET status = 'cancelled', deleted_at = NOW(), updated_at = CURRENT_TIMESTAMP
+								WHERE user_id = $1 AND project_id = $2 AND status = 'registered' AND deleted_at IS NULL
 				`
 
 	result, err := db.ExecContext(ctx, query, userID, projectID)
@@ -145,7 +176,7 @@ func GetUserRegistration(ctx context.Context, db *sql.DB, userID string) (Regist
 									r.created_at, r.updated_at
 									FROM registrations r
 									JOIN projects p ON r.project_id = p.id
-									WHERE r.user_id = $1
+									WHERE r.user_id = $1 AND r.deleted_at IS NULL AND p.deleted_at IS NULL
 									ORDER BY p.project_date
 					`
 
@@ -163,6 +194,32 @@ func GetUserRegistration(ctx context.Context, db *sql.DB, userID string) (Regist
 	return r, nil
 }
 
+// GetUserProjectRegistration gets a user's active registration for a project, or nil if they have none
+func GetUserProjectRegistration(ctx context.Context, db *sql.DB, userID string, projectID int) (
+	*Registration, error,
+) {
+	query := `
+		SELECT id, user_id, project_id, status, guest_count, lead_interest, group_reservation_id, created_at,
+		updated_at
+		FROM registrations
+		WHERE user_id = $1 AND project_id = $2 AND status = 'registered' AND deleted_at IS NULL
+	`
+
+	var r Registration
+	err := db.QueryRowContext(ctx, query, userID, projectID).Scan(
+		&r.ID, &r.UserID, &r.ProjectID, &r.Status, &r.GuestCount, &r.LeadInterest, &r.GroupReservationID,
+		&r.CreatedAt, &r.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
+
 // GetProjectRegistrations gets all registrations for a project
 func GetProjectRegistrations(ctx context.Context, db *sql.DB, projectID int) ([]Registration, error) {
 	query := `
@@ -171,7 +228,7 @@ func GetProjectRegistrations(ctx context.Context, db *sql.DB, projectID int) ([]
 									u.email, u.first_name, u.last_name, u.phone, u.text_permission
 									FROM registrations r
 									JOIN users u ON r.user_id = u.id
-									WHERE r.project_id = $1
+									WHERE r.project_id = $1 AND r.deleted_at IS NULL AND u.deleted_at IS NULL
 									ORDER BY r.status, r.created_at
 					`
 
@@ -212,11 +269,14 @@ func GetRegistrationsForReminders(db *sql.DB, days int) ([]Registration, error)
 									r.created_at, r.updated_at,
 									u.email, u.first_name, u.last_name,
 									p.title, p.description, p.time, p.project_date,
-									p.area, p.latitude, p.longitude, p.serve_lead_name, p.serve_lead_email
+									p.area, p.latitude, p.longitude, p.serve_lead_name, p.serve_lead_email,
+									p.location_address, p.exact_address, p.exact_latitude, p.exact_longitude,
+									p.location_reveal_at
 									FROM registrations r
 									JOIN users u ON r.user_id = u.id
 									JOIN projects p ON r.project_id = p.id
-									WHERE r.status = 'registered' 
+									WHERE r.status = 'registered'
+									AND r.deleted_at IS NULL AND u.deleted_at IS NULL AND p.deleted_at IS NULL
 									AND p.project_date >= (CURRENT_DATE + $1::integer)
   									AND p.project_date <  (CURRENT_DATE + $2::integer)
 									ORDER BY p.project_date
@@ -241,6 +301,8 @@ func GetRegistrationsForReminders(db *sql.DB, days int) ([]Registration, error)
 			&r.Project.Title, &r.Project.Description, &r.Project.Time, &r.Project.ProjectDate,
 			&r.Project.Area, &r.Project.Latitude, &r.Project.Longitude, &r.Project.ServeLeadName,
 			&r.Project.ServeLeadEmail,
+			&r.Project.LocationAddress, &r.Project.ExactAddress, &r.Project.ExactLatitude, &r.Project.ExactLongitude,
+			&r.Project.LocationRevealAt,
 		); err != nil {
 			return nil, err
 		}
@@ -263,8 +325,10 @@ func GetUserRegistrationByEmail(ctx context.Context, db *sql.DB, email string) (
 		SELECT r.project_id
 		FROM registrations r
 		JOIN users u ON r.user_id = u.id
+		JOIN projects p ON r.project_id = p.id
 		WHERE u.email = $1
 		AND r.status = 'registered'
+		AND r.deleted_at IS NULL AND u.deleted_at IS NULL AND p.deleted_at IS NULL
 	`
 
 	var projectID int
@@ -278,3 +342,25 @@ func GetUserRegistrationByEmail(ctx context.Context, db *sql.DB, email string) (
 
 	return projectID, nil
 }
+
+// GetRegistrationByID retrieves a registration by its ID
+func GetRegistrationByID(ctx context.Context, db *sql.DB, id int) (*Registration, error) {
+	query := `
+		SELECT id, user_id, project_id, status, guest_count, lead_interest, created_at, updated_at
+		FROM registrations
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	var r Registration
+	err := db.QueryRowContext(ctx, query, id).Scan(
+		&r.ID, &r.UserID, &r.ProjectID, &r.Status, &r.GuestCount, &r.LeadInterest, &r.CreatedAt, &r.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Registration not found
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// HasPrivateLocation reports whether the project keeps an exact address hidden behind its public area,
+// as for projects at private homes
+func (p *Project) HasPrivateLocation() bool {
+	return p.ExactAddress != ""
+}
+
+// LocationRevealTime returns when the exact address is revealed to volunteers: the project's own
+// reveal time, or revealDays before the project date
+func (p *Project) LocationRevealTime(revealDays int) time.Time {
+	if p.LocationRevealAt != nil {
+		return *p.LocationRevealAt
+	}
+	return p.ProjectDate.AddDate(0, 0, -revealDays)
+}
+
+// LocationRevealed reports whether a project's exact address may be shown to its volunteers at the
+// given time. Projects without a private location have nothing to reveal.
+func (p *Project) LocationRevealed(now time.Time, revealDays int) bool {
+	return p.HasPrivateLocation() && !now.Before(p.LocationRevealTime(revealDays))
+}
+
+// ForVolunteers returns a copy of the project as its registered volunteers see it at the given time,
+// with the exact location in place of the public one once it has been revealed
+func (p Project) ForVolunteers(now time.Time, revealDays int) Project {
+	if p.LocationRevealed(now, revealDays) {
+		p.LocationAddress, p.Latitude, p.Longitude = p.ExactAddress, p.ExactLatitude, p.ExactLongitude
+	}
+	return p
+}
+
+// VolunteerChanges picks out the changes volunteers can see at the given time. Until the exact address
+// is revealed changes to it stay private; afterwards the public area no longer matters to them.
+func (p *Project) VolunteerChanges(changes []ProjectChange, now time.Time, revealDays int) []ProjectChange {
+	hidden := "exact_address"
+	if p.LocationRevealed(now, revealDays) {
+		hidden = "address"
+	}
+
+	var visible []ProjectChange
+	for _, c := range changes {
+		if c.Field != hidden {
+			visible = append(visible, c)
+		}
+	}
+	return visible
+}
+
+// IsProjectLead reports whether the email belongs to the project's serve lead or one of its leads
+func (p *Project) IsProjectLead(email string) bool {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return false
+	}
+	if strings.EqualFold(p.ServeLeadEmail, email) {
+		return true
+	}
+
+	var leads []Lead
+	if err := json.Unmarshal(p.Leads, &leads); err != nil {
+		return false
+	}
+	for _, lead := range leads {
+		if strings.EqualFold(lead.Email, email) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"serve/models"
+)
+
+func TestProjectForVolunteers(t *testing.T) {
+	project := models.Project{
+		ProjectDate:     time.Date(2025, time.July, 12, 8, 0, 0, 0, time.UTC),
+		LocationAddress: "Castle Rock area",
+		Latitude:        39.37,
+		Longitude:       -104.86,
+		ExactAddress:    "100 Third St, Castle Rock, CO",
+		ExactLatitude:   39.3722,
+		ExactLongitude:  -104.8561,
+	}
+
+	before := project.ForVolunteers(time.Date(2025, time.July, 4, 0, 0, 0, 0, time.UTC), 7)
+	assert.Equal(t, "Castle Rock area", before.LocationAddress)
+	assert.Equal(t, 39.37, before.Latitude)
+
+	after := project.ForVolunteers(time.Date(2025, time.July, 5, 8, 0, 0, 0, time.UTC), 7)
+	assert.Equal(t, "100 Third St, Castle Rock, CO", after.LocationAddress)
+	assert.Equal(t, 39.3722, after.Latitude)
+
+	revealAt := time.Date(2025, time.July, 10, 0, 0, 0, 0, time.UTC)
+	project.LocationRevealAt = &revealAt
+	assert.False(t, project.LocationRevealed(time.Date(2025, time.July, 9, 0, 0, 0, 0, time.UTC), 7),
+		"a project's own reveal time overrides the default")
+
+	public := models.Project{LocationAddress: "Library", ProjectDate: project.ProjectDate}
+	assert.Equal(t, "Library", public.ForVolunteers(time.Now(), 7).LocationAddress)
+}
+
+func TestProjectVolunteerChanges(t *testing.T) {
+	project := models.Project{
+		ProjectDate:  time.Date(2025, time.July, 12, 8, 0, 0, 0, time.UTC),
+		ExactAddress: "100 Third St",
+	}
+	changes := []models.ProjectChange{
+		{Field: "address", Label: "Address", Before: "Castle Rock area", After: "Parker area"},
+		{Field: "exact_address", Label: "Address", Before: "100 Third St", After: "200 Fourth St"},
+		{Field: "time", Label: "Time", Before: "9am - 12pm", After: "8am - 11am"},
+	}
+
+	hidden := project.VolunteerChanges(changes, time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC), 7)
+	assert.Equal(t, []models.ProjectChange{changes[0], changes[2]}, hidden)
+
+	revealed := project.VolunteerChanges(changes, time.Date(2025, time.July, 11, 0, 0, 0, 0, time.UTC), 7)
+	assert.Equal(t, []models.ProjectChange{changes[1], changes[2]}, revealed)
+}
+
+func TestIsProjectLead(t *testing.T) {
+	leads, _ := json.Marshal([]models.Lead{{Name: "Jenn", Email: "jenn@example.com"}})
+	project := models.Project{ServeLeadEmail: "lead@example.com", Leads: leads}
+
+	assert.True(t, project.IsProjectLead("Lead@Example.com"))
+	assert.True(t, project.IsProjectLead("jenn@example.com"))
+	assert.False(t, project.IsProjectLead("volunteer@example.com"))
+	assert.False(t, project.IsProjectLead(""))
+}
@@ -0,0 +1,190 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"serve/middleware"
+)
+
+// RoleProjectLead is the role given to a project's leads, which must name the project it applies to
+const RoleProjectLead = "project-lead"
+
+// foreignKeyViolation is the postgres error code for a foreign key constraint violation
+const foreignKeyViolation = "23503"
+
+var (
+	// ErrUnknownRole is returned when assigning a role, or a role for a project, that does not exist
+	ErrUnknownRole = errors.New("unknown role or project")
+	// ErrRoleAssigned is returned when the user already holds the role
+	ErrRoleAssigned = errors.New("the user already holds this role")
+	// ErrProjectRequired is returned when a project lead role is assigned without a project
+	ErrProjectRequired = errors.New("a project is required for the project-lead role")
+)
+
+// Role is a named set of permissions
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UserRole is a role held by an Auth0 user, limited to one project when ProjectID is set
+type UserRole struct {
+	ID        int       `json:"id"`
+	Subject   string    `json:"subject"`
+	Role      string    `json:"role"`
+	ProjectID *int      `json:"project_id"`
+	GrantedBy string    `json:"granted_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GetRoles gets every role with its permissions
+func GetRoles(ctx context.Context, db *sql.DB) ([]Role, error) {
+	query := `
+		SELECT r.name, r.description, COALESCE(array_agg(rp.permission ORDER BY rp.permission)
+		FILTER (WHERE rp.permission IS NOT NULL), '{}')
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role = r.name
+		GROUP BY r.name, r.description
+		ORDER BY r.name
+	`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []Role{}
+	for rows.Next() {
+		var role Role
+		if err = rows.Scan(&role.Name, &role.Description, pq.Array(&role.Permissions)); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+// GetUserRoles gets the roles assigned to users, optionally only those of one subject
+func GetUserRoles(ctx context.Context, db *sql.DB, subject string) ([]UserRole, error) {
+	query := `
+		SELECT id, subject, role, project_id, granted_by, created_at
+		FROM user_roles
+		WHERE $1 = '' OR subject = $1
+		ORDER BY subject, role, project_id
+	`
+
+	rows, err := db.QueryContext(ctx, query, subject)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	userRoles := []UserRole{}
+	for rows.Next() {
+		var ur UserRole
+		var projectID sql.NullInt64
+		if err = rows.Scan(&ur.ID, &ur.Subject, &ur.Role, &projectID, &ur.GrantedBy, &ur.CreatedAt); err != nil {
+			return nil, err
+		}
+		if projectID.Valid {
+			id := int(projectID.Int64)
+			ur.ProjectID = &id
+		}
+		userRoles = append(userRoles, ur)
+	}
+
+	return userRoles, rows.Err()
+}
+
+// AssignRole gives a user a role
+func AssignRole(ctx context.Context, db *sql.DB, ur *UserRole) error {
+	ur.Subject = strings.TrimSpace(ur.Subject)
+	if ur.Role == RoleProjectLead && ur.ProjectID == nil {
+		return ErrProjectRequired
+	}
+
+	query := `
+		INSERT INTO user_roles (subject, role, project_id, granted_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	err := db.QueryRowContext(ctx, query, ur.Subject, ur.Role, ur.ProjectID, ur.GrantedBy).Scan(
+		&ur.ID, &ur.CreatedAt,
+	)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+		return ErrUnknownRole
+	}
+	return uniqueViolationAs(err, ErrRoleAssigned)
+}
+
+// RemoveUserRole takes a role assignment away, returning the assignment that was removed
+func RemoveUserRole(ctx context.Context, db *sql.DB, id int) (*UserRole, error) {
+	query := `DELETE FROM user_roles WHERE id = $1 RETURNING id, subject, role, project_id, granted_by, created_at`
+
+	var ur UserRole
+	var projectID sql.NullInt64
+	err := db.QueryRowContext(ctx, query, id).Scan(
+		&ur.ID, &ur.Subject, &ur.Role, &projectID, &ur.GrantedBy, &ur.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if projectID.Valid {
+		pid := int(projectID.Int64)
+		ur.ProjectID = &pid
+	}
+
+	return &ur, nil
+}
+
+// GetRoleGrants gets the permissions a user holds, both through the roles assigned to their subject
+// and through roles carried by the Auth0 permissions on their token
+func GetRoleGrants(ctx context.Context, db *sql.DB, subject string, auth0Permissions []string) (
+	[]middleware.Grant, error,
+) {
+	query := `
+		SELECT ur.role, rp.permission, ur.project_id
+		FROM user_roles ur
+		JOIN role_permissions rp ON rp.role = ur.role
+		WHERE ur.subject = $1
+		UNION
+		SELECT apr.role, rp.permission, NULL
+		FROM auth0_permission_roles apr
+		JOIN role_permissions rp ON rp.role = apr.role
+		WHERE apr.permission = ANY($2)
+	`
+
+	rows, err := db.QueryContext(ctx, query, subject, pq.Array(auth0Permissions))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := []middleware.Grant{}
+	for rows.Next() {
+		var g middleware.Grant
+		var projectID sql.NullInt64
+		if err = rows.Scan(&g.Role, &g.Permission, &projectID); err != nil {
+			return nil, err
+		}
+		if projectID.Valid {
+			id := int(projectID.Int64)
+			g.ProjectID = &id
+		}
+		grants = append(grants, g)
+	}
+
+	return grants, rows.Err()
+}
@@ -0,0 +1,309 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Project sort keys accepted by SearchProjects. Prefix a key with "-" to reverse it.
+const (
+	SortDate      = "date"
+	SortTitle     = "title"
+	SortOpenSpots = "open_spots"
+	SortCreated   = "created"
+	SortRelevance = "relevance"
+	SortDistance  = "distance"
+)
+
+var (
+	// ErrInvalidSort is returned for a sort key SearchProjects does not support
+	ErrInvalidSort = errors.New("invalid sort")
+	// ErrInvalidCursor is returned for a cursor that is malformed or was issued for a different sort
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// ProjectFilter holds the optional filters, sort order and pagination for searching public projects
+type ProjectFilter struct {
+	Query        string
+	Areas        []string
+	TypeIDs      []int
+	Ages         string
+	HasCapacity  bool
+	MinOpenSpots int
+	Statuses     []string
+	Near         *Location
+	RadiusKm     float64 // zero does not limit the distance from Near
+	From         *time.Time
+	To           *time.Time
+	Sort         string
+	Cursor       string
+	Limit        int // zero returns every match
+}
+
+// Location is a point on the map
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// ProjectPage is a page of search results
+type ProjectPage struct {
+	Projects   []Project
+	Total      int
+	NextCursor string
+}
+
+// projectSort describes how to order and page by a sort key
+type projectSort struct {
+	expr string // column of the filtered projects to order by
+	cast string // type to cast the cursor's key back to
+	desc bool   // whether the key sorts descending by default
+}
+
+var projectSorts = map[string]projectSort{
+	SortDate:      {expr: "f.project_date", cast: "timestamptz"},
+	SortTitle:     {expr: "f.title", cast: "text"},
+	SortOpenSpots: {expr: "f.open_spots", cast: "int", desc: true},
+	SortCreated:   {expr: "f.created_at", cast: "timestamptz", desc: true},
+	SortRelevance: {expr: "f.rank", cast: "real", desc: true},
+	SortDistance:  {expr: "f.distance_km", cast: "float8"},
+}
+
+// projectCursor is the position after the last project of a page
+type projectCursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   int    `json:"id"`
+}
+
+// encode returns the cursor as an opaque URL-safe string
+func (c projectCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeProjectCursor parses a cursor returned by encode
+func decodeProjectCursor(s string) (projectCursor, error) {
+	var c projectCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err = json.Unmarshal(b, &c); err != nil || c.ID == 0 {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// resolveSort returns the sort for the filter, defaulting to distance for proximity searches, to
+// relevance for keyword searches and to project date otherwise, and whether it is descending
+func (f ProjectFilter) resolveSort() (string, projectSort, bool, error) {
+	name := f.Sort
+	if name == "" {
+		switch {
+		case f.Near != nil:
+			name = SortDistance
+		case f.Query != "":
+			name = SortRelevance
+		default:
+			name = SortDate
+		}
+	}
+
+	reverse := strings.HasPrefix(name, "-")
+	key := strings.TrimPrefix(name, "-")
+	sort, ok := projectSorts[key]
+	if !ok || (key == SortRelevance && f.Query == "") || (key == SortDistance && f.Near == nil) {
+		return "", sort, false, fmt.Errorf("%w: %q", ErrInvalidSort, f.Sort)
+	}
+
+	return name, sort, sort.desc != reverse, nil
+}
+
+// SearchProjects retrieves the public projects matching the filter, in the requested order, a page at
+// a time. Projects that are pending review or were not approved are never included.
+func SearchProjects(ctx context.Context, db *sql.DB, f ProjectFilter) (*ProjectPage, error) {
+	sortName, sort, desc, err := f.resolveSort()
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor projectCursor
+	if f.Cursor != "" {
+		if cursor, err = decodeProjectCursor(f.Cursor); err != nil || cursor.Sort != sortName {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	rank := "0::real"
+	conditions := []string{"p.deleted_at IS NULL", "p.status NOT IN ('pending', 'not_approved')"}
+	if f.Query != "" {
+		tsQuery := "websearch_to_tsquery('english', " + arg(f.Query) + ")"
+		conditions = append(conditions, "p.search @@ "+tsQuery)
+		rank = "ts_rank(p.search, " + tsQuery + ")"
+	}
+	if len(f.Areas) > 0 {
+		lower := make([]string, len(f.Areas))
+		for i, area := range f.Areas {
+			lower[i] = strings.ToLower(area)
+		}
+		conditions = append(conditions, "lower(p.area) = ANY("+arg(pq.Array(lower))+")")
+	}
+	if len(f.TypeIDs) > 0 {
+		conditions = append(
+			conditions, "EXISTS (SELECT 1 FROM project_types t WHERE t.project_id = p.id AND t.type_id = ANY("+
+				arg(pq.Array(f.TypeIDs))+"))",
+		)
+	}
+	if f.Ages != "" {
+		conditions = append(conditions, "lower(p.ages) = lower("+arg(f.Ages)+")")
+	}
+	if len(f.Statuses) > 0 {
+		conditions = append(conditions, "p.status::text = ANY("+arg(pq.Array(f.Statuses))+")")
+	}
+	if f.From != nil {
+		conditions = append(conditions, "p.project_date >= "+arg(*f.From))
+	}
+	if f.To != nil {
+		conditions = append(conditions, "p.project_date < "+arg(*f.To))
+	}
+	distance := "NULL::float8"
+	if f.Near != nil {
+		origin := "ll_to_earth(" + arg(f.Near.Latitude) + ", " + arg(f.Near.Longitude) + ")"
+		location := "ll_to_earth(p.latitude, p.longitude)"
+		conditions = append(conditions, "p.latitude IS NOT NULL", "p.longitude IS NOT NULL")
+		if f.RadiusKm > 0 {
+			// the box test can use the location index; the distance test trims the box's corners
+			radius := arg(f.RadiusKm * 1000)
+			conditions = append(
+				conditions,
+				"earth_box("+origin+", "+radius+") @> "+location,
+				"earth_distance("+origin+", "+location+") <= "+radius,
+			)
+		}
+		distance = "earth_distance(" + origin + ", " + location + ") / 1000"
+	}
+	// spots groups are holding for their members are not open to everyone
+	openSpots := "p.max_capacity - COALESCE(reg.count, 0) - COALESCE(held.seats, 0)"
+	if f.HasCapacity {
+		conditions = append(conditions, openSpots+" > 0")
+	}
+	if f.MinOpenSpots > 0 {
+		conditions = append(conditions, openSpots+" >= "+arg(f.MinOpenSpots))
+	}
+
+	filtered := `
+		SELECT p.id, p.google_id, p.title, p.description, p.website, p.time, p.max_capacity, p.area,
+		p.location_address, p.latitude, p.longitude, p.created_at, p.updated_at, p.ages, p.serve_lead_name,
+		p.serve_lead_email, p.project_date, p.leads, p.status, p.status_reason, p.organization_id,
+		COALESCE(reg.count, 0) AS current_registrations,
+		COALESCE(held.seats, 0) AS reserved_seats,
+		` + openSpots + ` AS open_spots,
+		COALESCE(pt.type_ids, '') AS type_ids,
+		` + rank + ` AS rank,
+		` + distance + ` AS distance_km
+		FROM projects p
+		LEFT JOIN (
+			SELECT project_id, SUM(1 + guest_count) AS count
+			FROM registrations
+			WHERE status = 'registered' AND deleted_at IS NULL
+			GROUP BY project_id
+		) reg ON p.id = reg.project_id
+		LEFT JOIN (
+			SELECT project_id, SUM(held) AS seats FROM (` + heldSeatsSQL + `) h GROUP BY project_id
+		) held ON p.id = held.project_id
+		LEFT JOIN (
+			SELECT project_id, array_to_string(array_agg(type_id), ',') AS type_ids
+			FROM project_types
+			GROUP BY project_id
+		) pt ON p.id = pt.project_id
+		WHERE ` + strings.Join(conditions, " AND ")
+
+	page := &ProjectPage{Projects: []Project{}}
+	if err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM ("+filtered+") f", args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	dir, cmp := "ASC", ">"
+	if desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	where := ""
+	if f.Cursor != "" {
+		where = fmt.Sprintf(
+			"WHERE (%s, f.id) %s (%s::%s, %s)", sort.expr, cmp, arg(cursor.Key), sort.cast, arg(cursor.ID),
+		)
+	}
+
+	limit := ""
+	if f.Limit > 0 {
+		limit = "LIMIT " + arg(f.Limit+1) // one extra row tells us whether there is another page
+	}
+
+	query := fmt.Sprintf(
+		`
+		SELECT f.id, f.google_id, f.title, f.description, f.website, f.time, f.max_capacity, f.area,
+		f.location_address, f.latitude, f.longitude, f.created_at, f.updated_at, f.ages, f.serve_lead_name,
+		f.serve_lead_email, f.project_date, f.leads, f.status, f.status_reason, f.organization_id,
+		f.current_registrations, f.reserved_seats, f.distance_km, f.type_ids, (%[1]s)::text
+		FROM (%[2]s) f
+		%[3]s
+		ORDER BY %[1]s %[4]s, f.id %[4]s
+		%[5]s
+	`, sort.expr, filtered, where, dir, limit,
+	)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lastKey string
+	for rows.Next() {
+		if f.Limit > 0 && len(page.Projects) == f.Limit {
+			last := page.Projects[len(page.Projects)-1]
+			page.NextCursor = projectCursor{Sort: sortName, Key: lastKey, ID: last.ID}.encode()
+			break
+		}
+
+		var p Project
+		var typeIDsStr string
+		if err = rows.Scan(
+			&p.ID, &p.GoogleID, &p.Title, &p.Description, &p.Website, &p.Time, &p.MaxCapacity, &p.Area,
+			&p.LocationAddress, &p.Latitude, &p.Longitude, &p.CreatedAt, &p.UpdatedAt, &p.Ages, &p.ServeLeadName,
+			&p.ServeLeadEmail, &p.ProjectDate, &p.Leads, &p.Status, &p.StatusReason, &p.OrganizationID,
+			&p.CurrentReg, &p.ReservedSeats, &p.DistanceKm, &typeIDsStr, &lastKey,
+		); err != nil {
+			return nil, err
+		}
+
+		// Convert comma-separated string to Types
+		if typeIDsStr != "" {
+			for _, idStr := range strings.Split(typeIDsStr, ",") {
+				if id, err := strconv.Atoi(idStr); err == nil {
+					p.Types = append(p.Types, ProjectAccessory{ID: id})
+				}
+			}
+		}
+		page.Projects = append(page.Projects, p)
+	}
+
+	return page, rows.Err()
+}
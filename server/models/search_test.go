@@ -0,0 +1,34 @@
+package models_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"serve/models"
+)
+
+func TestSearchProjectsRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter models.ProjectFilter
+		want   error
+	}{
+		{"unknown sort", models.ProjectFilter{Sort: "popularity"}, models.ErrInvalidSort},
+		{"relevance without query", models.ProjectFilter{Sort: models.SortRelevance}, models.ErrInvalidSort},
+		{"distance without location", models.ProjectFilter{Sort: models.SortDistance}, models.ErrInvalidSort},
+		{"malformed cursor", models.ProjectFilter{Cursor: "not a cursor"}, models.ErrInvalidCursor},
+		{"cursor without id", models.ProjectFilter{Cursor: "e30"}, models.ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// invalid input is rejected before the database is queried
+				_, err := models.SearchProjects(context.Background(), nil, tt.filter)
+				assert.True(t, errors.Is(err, tt.want), "expected %v, got %v", tt.want, err)
+			},
+		)
+	}
+}
@@ -0,0 +1,225 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+)
+
+// Stats represents the aggregated admin dashboard statistics
+type Stats struct {
+	TotalVolunteers       int                  `json:"total_volunteers"`
+	TotalRegistrations    int                  `json:"total_registrations"`
+	TotalGuests           int                  `json:"total_guests"`
+	TotalCapacity         int                  `json:"total_capacity"`
+	FillPercentage        float64              `json:"fill_percentage"`
+	Cancellations         int                  `json:"cancellations"`
+	LeadInterest          int                  `json:"lead_interest"`
+	SMSOptIns             int                  `json:"sms_opt_ins"`
+	SMSOptInRate          float64              `json:"sms_opt_in_rate"`
+	RegistrationsOverTime []DailyRegistrations `json:"registrations_over_time"`
+	Projects              []ProjectFill        `json:"projects"`
+	UnderHalfFull         []ProjectFill        `json:"under_half_full"`
+	Areas                 []GroupFill          `json:"areas"`
+	Types                 []GroupFill          `json:"types"`
+	GeneratedAt           time.Time            `json:"generated_at"`
+}
+
+// DailyRegistrations holds the registrations created on a single day
+type DailyRegistrations struct {
+	Date          time.Time `json:"date"`
+	Registrations int       `json:"registrations"`
+	Volunteers    int       `json:"volunteers"`
+}
+
+// ProjectFill holds the fill rate of a single project
+type ProjectFill struct {
+	ID             int     `json:"id"`
+	Title          string  `json:"title"`
+	Area           string  `json:"area"`
+	Status         string  `json:"status"`
+	MaxCapacity    int     `json:"max_capacity"`
+	CurrentReg     int     `json:"current_registrations"`
+	FillPercentage float64 `json:"fill_percentage"`
+}
+
+// GroupFill holds the fill rate of a group of projects, e.g. an area or a type
+type GroupFill struct {
+	Name           string  `json:"name"`
+	Projects       int     `json:"projects"`
+	MaxCapacity    int     `json:"max_capacity"`
+	CurrentReg     int     `json:"current_registrations"`
+	FillPercentage float64 `json:"fill_percentage"`
+}
+
+// projectFillCTE computes the number of volunteers (registrant + guests) per project
+const projectFillCTE = `
+		WITH project_fill AS (
+			SELECT p.id, p.title, COALESCE(p.area, '') AS area, p.status::text AS status, p.max_capacity,
+			COALESCE(SUM(1 + r.guest_count) FILTER (WHERE r.status = 'registered'), 0) AS current_registrations
+			FROM projects p
+			LEFT JOIN registrations r ON p.id = r.project_id AND r.deleted_at IS NULL
+			WHERE p.deleted_at IS NULL
+			GROUP BY p.id
+		)
+`
+
+// GetStats computes the admin dashboard statistics
+func GetStats(ctx context.Context, db *sql.DB) (*Stats, error) {
+	s := &Stats{GeneratedAt: time.Now()}
+
+	totalsQuery := `
+		SELECT
+		COUNT(*) FILTER (WHERE status = 'registered' AND deleted_at IS NULL),
+		COALESCE(SUM(guest_count) FILTER (WHERE status = 'registered' AND deleted_at IS NULL), 0),
+		COUNT(*) FILTER (WHERE status = 'cancelled'),
+		COUNT(*) FILTER (WHERE status = 'registered' AND deleted_at IS NULL AND lead_interest)
+		FROM registrations
+	`
+	if err := db.QueryRowContext(ctx, totalsQuery).Scan(
+		&s.TotalRegistrations, &s.TotalGuests, &s.Cancellations, &s.LeadInterest,
+	); err != nil {
+		return nil, err
+	}
+	s.TotalVolunteers = s.TotalRegistrations + s.TotalGuests
+
+	// guests have no phone of their own, so the rate is out of the registered users
+	var registeredUsers int
+	smsQuery := `
+		SELECT COUNT(DISTINCT u.id) FILTER (WHERE u.text_permission), COUNT(DISTINCT u.id)
+		FROM users u
+		JOIN registrations r ON r.user_id = u.id AND r.status = 'registered' AND r.deleted_at IS NULL
+		WHERE u.deleted_at IS NULL
+	`
+	if err := db.QueryRowContext(ctx, smsQuery).Scan(&s.SMSOptIns, &registeredUsers); err != nil {
+		return nil, err
+	}
+	s.SMSOptInRate = percentage(s.SMSOptIns, registeredUsers)
+
+	var err error
+	if s.RegistrationsOverTime, err = getDailyRegistrations(ctx, db); err != nil {
+		return nil, err
+	}
+
+	if s.Projects, err = getProjectFill(ctx, db); err != nil {
+		return nil, err
+	}
+
+	s.UnderHalfFull = []ProjectFill{}
+	for _, p := range s.Projects {
+		s.TotalCapacity += p.MaxCapacity
+		if p.FillPercentage < 50 {
+			s.UnderHalfFull = append(s.UnderHalfFull, p)
+		}
+	}
+	s.FillPercentage = percentage(s.TotalVolunteers, s.TotalCapacity)
+
+	areaQuery := projectFillCTE + `
+		SELECT area, COUNT(*), SUM(max_capacity), SUM(current_registrations)
+		FROM project_fill
+		GROUP BY area
+		ORDER BY area
+	`
+	if s.Areas, err = getGroupFill(ctx, db, areaQuery); err != nil {
+		return nil, err
+	}
+
+	typeQuery := projectFillCTE + `
+		SELECT t.type, COUNT(pf.id), COALESCE(SUM(pf.max_capacity), 0), COALESCE(SUM(pf.current_registrations), 0)
+		FROM types t
+		LEFT JOIN project_types pt ON pt.type_id = t.id
+		LEFT JOIN project_fill pf ON pf.id = pt.project_id
+		GROUP BY t.id, t.type
+		ORDER BY t.id
+	`
+	if s.Types, err = getGroupFill(ctx, db, typeQuery); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// getDailyRegistrations returns the active registrations grouped by the day they were created
+func getDailyRegistrations(ctx context.Context, db *sql.DB) ([]DailyRegistrations, error) {
+	query := `
+		SELECT date_trunc('day', created_at), COUNT(*), SUM(1 + guest_count)
+		FROM registrations
+		WHERE status = 'registered' AND deleted_at IS NULL
+		GROUP BY 1
+		ORDER BY 1
+	`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := []DailyRegistrations{}
+	for rows.Next() {
+		var d DailyRegistrations
+		if err = rows.Scan(&d.Date, &d.Registrations, &d.Volunteers); err != nil {
+			return nil, err
+		}
+		days = append(days, d)
+	}
+
+	return days, rows.Err()
+}
+
+// getProjectFill returns the fill rate of every project
+func getProjectFill(ctx context.Context, db *sql.DB) ([]ProjectFill, error) {
+	query := projectFillCTE + `
+		SELECT id, title, area, status, max_capacity, current_registrations
+		FROM project_fill
+		ORDER BY id
+	`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []ProjectFill{}
+	for rows.Next() {
+		var p ProjectFill
+		if err = rows.Scan(&p.ID, &p.Title, &p.Area, &p.Status, &p.MaxCapacity, &p.CurrentReg); err != nil {
+			return nil, err
+		}
+		p.FillPercentage = percentage(p.CurrentReg, p.MaxCapacity)
+		projects = append(projects, p)
+	}
+
+	return projects, rows.Err()
+}
+
+// getGroupFill runs a query returning name, project count, capacity and registrations per group
+func getGroupFill(ctx context.Context, db *sql.DB, query string) ([]GroupFill, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []GroupFill{}
+	for rows.Next() {
+		var g GroupFill
+		if err = rows.Scan(&g.Name, &g.Projects, &g.MaxCapacity, &g.CurrentReg); err != nil {
+			return nil, err
+		}
+		g.FillPercentage = percentage(g.CurrentReg, g.MaxCapacity)
+		groups = append(groups, g)
+	}
+
+	return groups, rows.Err()
+}
+
+// percentage returns part/total as a percentage rounded to one decimal place
+func percentage(part, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*1000) / 10
+}
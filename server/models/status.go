@@ -0,0 +1,164 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Project statuses, matching the status enum in the database
+const (
+	StatusPending     = "pending"
+	StatusOpen        = "open"
+	StatusNotApproved = "not_approved"
+	StatusDidNotOccur = "did_not_occur"
+	StatusInReview    = "in_review"
+)
+
+var (
+	// ErrInvalidStatus is returned for a status that is not part of the status enum
+	ErrInvalidStatus = errors.New("invalid project status")
+	// ErrInvalidTransition is returned when a project cannot move from its current status to the requested one
+	ErrInvalidTransition = errors.New("invalid project status transition")
+	// ErrReasonRequired is returned when a status change that requires a reason is made without one
+	ErrReasonRequired = errors.New("a reason is required for this status change")
+	// ErrProjectNotOpen is returned when registering for a project that is not open
+	ErrProjectNotOpen = errors.New("project is not open for registration")
+)
+
+// statusTransitions lists the statuses a project may move to from each status. Did not occur is final:
+// the registrations it releases have been told the project is cancelled and are not brought back.
+var statusTransitions = map[string][]string{
+	StatusPending:     {StatusInReview, StatusOpen, StatusNotApproved},
+	StatusInReview:    {StatusPending, StatusOpen, StatusNotApproved},
+	StatusOpen:        {StatusInReview, StatusDidNotOccur},
+	StatusNotApproved: {StatusPending, StatusInReview},
+	StatusDidNotOccur: {},
+}
+
+// IsValidStatus reports whether the status is part of the status enum
+func IsValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+// AllowedTransitions returns the statuses a project may move to from the given status
+func AllowedTransitions(from string) []string {
+	return statusTransitions[from]
+}
+
+// CanTransition reports whether a project may move from one status to another
+func CanTransition(from, to string) bool {
+	return slices.Contains(statusTransitions[from], to)
+}
+
+// StatusRequiresReason reports whether moving to the status requires a reason
+func StatusRequiresReason(status string) bool {
+	return status == StatusNotApproved || status == StatusDidNotOccur
+}
+
+// IsCancelledStatus reports whether the status means the project will not take place
+func IsCancelledStatus(status string) bool {
+	return status == StatusNotApproved || status == StatusDidNotOccur
+}
+
+// IsPublicStatus reports whether projects with the status are shown to the public. Submissions waiting on
+// review or turned down are only shown to admins.
+func IsPublicStatus(status string) bool {
+	return status != StatusPending && status != StatusNotApproved
+}
+
+// ValidateStatusTransition checks a status change against the transition rules
+func ValidateStatusTransition(from, to, reason string) error {
+	if !IsValidStatus(to) {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, to)
+	}
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+	}
+	if StatusRequiresReason(to) && reason == "" {
+		return fmt.Errorf("%w: %s", ErrReasonRequired, to)
+	}
+	return nil
+}
+
+// UpdateProjectStatus moves a project from one status to another, recording the reason. When the
+// new status cancels the project its active registrations are released, and returned with their
+// users so the registrants can be notified.
+func UpdateProjectStatus(ctx context.Context, db *sql.DB, id int, from, to, reason string) ([]Registration, error) {
+	if err := ValidateStatusTransition(from, to, reason); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(
+		ctx, `
+		UPDATE projects SET status = $1, status_reason = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status = $4 AND deleted_at IS NULL
+	`, to, reason, id, from,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, ErrNotFound // deleted or changed by someone else in the meantime
+	}
+
+	var released []Registration
+	if IsCancelledStatus(to) {
+		if released, err = releaseProjectRegistrations(ctx, tx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return released, nil
+}
+
+// releaseProjectRegistrations cancels the active registrations of a project and returns them
+func releaseProjectRegistrations(ctx context.Context, tx *sql.Tx, projectID int) ([]Registration, error) {
+	query := `
+		UPDATE registrations r
+		SET status = 'cancelled', deleted_at = NOW(), updated_at = CURRENT_TIMESTAMP
+		FROM users u
+		WHERE r.user_id = u.id AND r.project_id = $1 AND r.status = 'registered' AND r.deleted_at IS NULL
+		RETURNING r.id, r.user_id, r.project_id, r.guest_count, r.lead_interest, r.created_at, r.updated_at,
+		u.email, u.first_name, u.last_name, u.phone, u.text_permission
+	`
+
+	rows, err := tx.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var registrations []Registration
+	for rows.Next() {
+		r := Registration{Status: "cancelled", User: &User{}}
+		if err = rows.Scan(
+			&r.ID, &r.UserID, &r.ProjectID, &r.GuestCount, &r.LeadInterest, &r.CreatedAt, &r.UpdatedAt,
+			&r.User.Email, &r.User.FirstName, &r.User.LastName, &r.User.Phone, &r.User.TextPermission,
+		); err != nil {
+			return nil, err
+		}
+		r.User.ID = r.UserID
+		registrations = append(registrations, r)
+	}
+
+	return registrations, rows.Err()
+}
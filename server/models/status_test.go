@@ -0,0 +1,42 @@
+package models_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"serve/models"
+)
+
+func TestValidateStatusTransition(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		to     string
+		reason string
+		want   error
+	}{
+		{"approve pending", models.StatusPending, models.StatusOpen, "", nil},
+		{"review open project", models.StatusOpen, models.StatusInReview, "", nil},
+		{"reject with reason", models.StatusInReview, models.StatusNotApproved, "duplicate", nil},
+		{"reject without reason", models.StatusInReview, models.StatusNotApproved, "", models.ErrReasonRequired},
+		{"cancel without reason", models.StatusOpen, models.StatusDidNotOccur, "", models.ErrReasonRequired},
+		{"reopen rejected directly", models.StatusNotApproved, models.StatusOpen, "", models.ErrInvalidTransition},
+		{"reopen did not occur", models.StatusDidNotOccur, models.StatusOpen, "", models.ErrInvalidTransition},
+		{"same status", models.StatusOpen, models.StatusOpen, "", models.ErrInvalidTransition},
+		{"unknown status", models.StatusOpen, "closed", "", models.ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				err := models.ValidateStatusTransition(tt.from, tt.to, tt.reason)
+				if tt.want == nil {
+					assert.NoError(t, err)
+					return
+				}
+				assert.True(t, errors.Is(err, tt.want), "expected %v, got %v", tt.want, err)
+			},
+		)
+	}
+}
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrSubmissionLocked is returned when a partner edits a submission that is no longer under review
+var ErrSubmissionLocked = errors.New("submission can no longer be edited")
+
+// editableSubmissionStatuses are the project statuses in which a partner may still edit their submission
+var editableSubmissionStatuses = []string{StatusPending, StatusInReview}
+
+// Submission represents a project proposed by a partner organization
+type Submission struct {
+	ID           int       `json:"id"`
+	ProjectID    int       `json:"project_id"`
+	Token        string    `json:"-"`
+	Organization string    `json:"organization"`
+	ContactName  string    `json:"contact_name"`
+	ContactEmail string    `json:"contact_email"`
+	ContactPhone string    `json:"contact_phone"`
+	Needs        string    `json:"needs"`
+	Editable     bool      `json:"editable"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Project      *Project  `json:"project,omitempty"`
+}
+
+// IsSubmissionEditable reports whether a submission with the project status can still be edited
+func IsSubmissionEditable(status string) bool {
+	return slices.Contains(editableSubmissionStatuses, status)
+}
+
+// CreateSubmission creates the submitted project in pending status together with its submission record
+func CreateSubmission(ctx context.Context, db *sql.DB, s *Submission) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	s.Project.Status = StatusPending
+	if err = insertProject(ctx, tx, s.Project); err != nil {
+		return err
+	}
+	s.ProjectID = s.Project.ID
+
+	query := `
+		INSERT INTO project_submissions (
+			project_id, token, organization, contact_name, contact_email, contact_phone, needs
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+	if err = tx.QueryRowContext(
+		ctx, query, s.ProjectID, s.Token, s.Organization, s.ContactName, s.ContactEmail, s.ContactPhone, s.Needs,
+	).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return err
+	}
+	s.Editable = true
+
+	return tx.Commit()
+}
+
+// GetSubmissionByToken retrieves a submission and its project by the partner's tracking token
+func GetSubmissionByToken(ctx context.Context, db *sql.DB, token string) (*Submission, error) {
+	query := `
+		SELECT s.id, s.project_id, s.token, s.organization, s.contact_name, s.contact_email, s.contact_phone,
+		s.needs, s.created_at, s.updated_at
+		FROM project_submissions s
+		JOIN projects p ON p.id = s.project_id
+		WHERE s.token = $1 AND p.deleted_at IS NULL
+	`
+
+	var s Submission
+	err := db.QueryRowContext(ctx, query, token).Scan(
+		&s.ID, &s.ProjectID, &s.Token, &s.Organization, &s.ContactName, &s.ContactEmail, &s.ContactPhone,
+		&s.Needs, &s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Submission not found
+		}
+		return nil, err
+	}
+
+	if s.Project, err = GetProjectByID(ctx, db, s.ProjectID); err != nil {
+		return nil, err
+	}
+	if s.Project == nil {
+		return nil, nil
+	}
+	s.Editable = IsSubmissionEditable(s.Project.Status)
+
+	return &s, nil
+}
+
+// UpdateSubmission saves a partner's changes to their submission and its project, as long as the
+// project is still under review
+func UpdateSubmission(ctx context.Context, db *sql.DB, s *Submission) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	p := s.Project
+	result, err := tx.ExecContext(
+		ctx, `
+		UPDATE projects
+		SET title = $1, description = $2, website = $3, time = $4, max_capacity = $5, area = $6,
+		location_address = $7, latitude = $8, longitude = $9, ages = COALESCE(NULLIF($10, ''), 'All Ages'),
+		updated_at = CURRENT_TIMESTAMP
+		WHERE id = $11 AND deleted_at IS NULL AND status::text = ANY($12)
+	`, p.Title, p.Description, p.Website, p.Time, p.MaxCapacity, p.Area, p.LocationAddress, p.Latitude,
+		p.Longitude, p.Ages, p.ID, pq.Array(editableSubmissionStatuses),
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrSubmissionLocked
+	}
+
+	if err = DeleteProjectAssociations(ctx, tx, p.ID); err != nil {
+		return err
+	}
+	if err = insertAccessories(ctx, tx, p); err != nil {
+		return err
+	}
+
+	if err = tx.QueryRowContext(
+		ctx, `
+		UPDATE project_submissions
+		SET organization = $1, contact_name = $2, contact_email = $3, contact_phone = $4, needs = $5,
+		updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6
+		RETURNING updated_at
+	`, s.Organization, s.ContactName, s.ContactEmail, s.ContactPhone, s.Needs, s.ID,
+	).Scan(&s.UpdatedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetSubmissions retrieves every partner submission with its project status, newest first
+func GetSubmissions(ctx context.Context, db *sql.DB) ([]Submission, error) {
+	query := `
+		SELECT s.id, s.project_id, s.organization, s.contact_name, s.contact_email, s.contact_phone, s.needs,
+		s.created_at, s.updated_at, p.title, p.status, p.status_reason, p.max_capacity, p.project_date
+		FROM project_submissions s
+		JOIN projects p ON p.id = s.project_id
+		WHERE p.deleted_at IS NULL
+		ORDER BY s.created_at DESC
+	`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	submissions := []Submission{}
+	for rows.Next() {
+		s := Submission{Project: &Project{}}
+		if err = rows.Scan(
+			&s.ID, &s.ProjectID, &s.Organization, &s.ContactName, &s.ContactEmail, &s.ContactPhone, &s.Needs,
+			&s.CreatedAt, &s.UpdatedAt, &s.Project.Title, &s.Project.Status, &s.Project.StatusReason,
+			&s.Project.MaxCapacity, &s.Project.ProjectDate,
+		); err != nil {
+			return nil, err
+		}
+		s.Project.ID = s.ProjectID
+		s.Editable = IsSubmissionEditable(s.Project.Status)
+		submissions = append(submissions, s)
+	}
+
+	return submissions, rows.Err()
+}
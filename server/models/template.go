@@ -0,0 +1,312 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTemplateExists is returned when a project template with the same name already exists
+var ErrTemplateExists = errors.New("a project template with this name already exists")
+
+// ProjectTemplate is a reusable blueprint for a recurring project
+type ProjectTemplate struct {
+	ID              int                `json:"id"`
+	Name            string             `json:"name"`
+	OrganizationID  *int               `json:"organization_id"`
+	Title           string             `json:"title"`
+	Description     string             `json:"description"`
+	Website         string             `json:"website"`
+	Time            string             `json:"time"`
+	MaxCapacity     int                `json:"max_capacity"`
+	Area            string             `json:"area"`
+	LocationAddress string             `json:"location_address"`
+	Latitude        float64            `json:"latitude"`
+	Longitude       float64            `json:"longitude"`
+	Ages            string             `json:"ages"`
+	ServeLeadName   string             `json:"serve_lead_name"`
+	ServeLeadEmail  string             `json:"serve_lead_email"`
+	Leads           json.RawMessage    `json:"leads"`
+	Types           []ProjectAccessory `json:"types"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+const templateQuery = `
+		SELECT t.id, t.name, t.organization_id, t.title, t.description, t.website, t.time, t.max_capacity, t.area,
+		t.location_address, t.latitude, t.longitude, t.ages, t.serve_lead_name, t.serve_lead_email, t.leads,
+		t.created_at, t.updated_at,
+		COALESCE(json_agg(json_build_object('id', ty.id, 'name', ty.type) ORDER BY ty.id)
+			FILTER (WHERE ty.id IS NOT NULL), '[]')
+		FROM project_templates t
+		LEFT JOIN project_template_types tt ON tt.template_id = t.id
+		LEFT JOIN types ty ON ty.id = tt.type_id
+`
+
+// scanTemplate scans a row selected with templateQuery
+func scanTemplate(row interface{ Scan(...any) error }, t *ProjectTemplate) error {
+	var leads, types []byte
+	if err := row.Scan(
+		&t.ID, &t.Name, &t.OrganizationID, &t.Title, &t.Description, &t.Website, &t.Time, &t.MaxCapacity, &t.Area,
+		&t.LocationAddress, &t.Latitude, &t.Longitude, &t.Ages, &t.ServeLeadName, &t.ServeLeadEmail, &leads,
+		&t.CreatedAt, &t.UpdatedAt, &types,
+	); err != nil {
+		return err
+	}
+	t.Leads = leads
+	return json.Unmarshal(types, &t.Types)
+}
+
+// GetAllTemplates retrieves all project templates ordered by name
+func GetAllTemplates(ctx context.Context, db *sql.DB) ([]ProjectTemplate, error) {
+	rows, err := db.QueryContext(ctx, templateQuery+` GROUP BY t.id ORDER BY lower(t.name)`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []ProjectTemplate{}
+	for rows.Next() {
+		var t ProjectTemplate
+		if err = scanTemplate(rows, &t); err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, rows.Err()
+}
+
+// GetTemplateByID retrieves a project template by its ID
+func GetTemplateByID(ctx context.Context, db *sql.DB, id int) (*ProjectTemplate, error) {
+	var t ProjectTemplate
+	row := db.QueryRowContext(ctx, templateQuery+` WHERE t.id = $1 GROUP BY t.id`, id)
+	if err := scanTemplate(row, &t); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Template not found
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// CreateTemplate creates a new project template along with its types
+func CreateTemplate(ctx context.Context, db *sql.DB, t *ProjectTemplate) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO project_templates (name, organization_id, title, description, website, time, max_capacity, area,
+		                               location_address, latitude, longitude, ages, serve_lead_name, serve_lead_email,
+		                               leads)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, COALESCE(NULLIF($12, ''), 'All Ages'), $13, $14, $15)
+		RETURNING id, ages, created_at, updated_at
+	`
+
+	if err = tx.QueryRowContext(
+		ctx, query, t.Name, t.OrganizationID, t.Title, t.Description, t.Website, t.Time, t.MaxCapacity, t.Area,
+		t.LocationAddress, t.Latitude, t.Longitude, t.Ages, t.ServeLeadName, t.ServeLeadEmail, templateLeads(t),
+	).Scan(&t.ID, &t.Ages, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return uniqueViolationAs(err, ErrTemplateExists)
+	}
+
+	if err = insertTemplateTypes(ctx, tx, t); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateTemplate updates an existing project template and replaces its types
+func UpdateTemplate(ctx context.Context, db *sql.DB, t *ProjectTemplate) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE project_templates
+		SET name = $1, organization_id = $2, title = $3, description = $4, website = $5, time = $6,
+		max_capacity = $7, area = $8, location_address = $9, latitude = $10, longitude = $11,
+		ages = COALESCE(NULLIF($12, ''), 'All Ages'), serve_lead_name = $13, serve_lead_email = $14, leads = $15,
+		updated_at = CURRENT_TIMESTAMP
+		WHERE id = $16
+		RETURNING ages, updated_at
+	`
+
+	if err = tx.QueryRowContext(
+		ctx, query, t.Name, t.OrganizationID, t.Title, t.Description, t.Website, t.Time, t.MaxCapacity, t.Area,
+		t.LocationAddress, t.Latitude, t.Longitude, t.Ages, t.ServeLeadName, t.ServeLeadEmail, templateLeads(t), t.ID,
+	).Scan(&t.Ages, &t.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return uniqueViolationAs(err, ErrTemplateExists)
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM project_template_types WHERE template_id = $1`, t.ID); err != nil {
+		return err
+	}
+	if err = insertTemplateTypes(ctx, tx, t); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteTemplate deletes a project template. Projects created from it are not affected.
+func DeleteTemplate(ctx context.Context, db *sql.DB, id int) error {
+	return execExpectingRow(ctx, db, `DELETE FROM project_templates WHERE id = $1`, id)
+}
+
+// CreateTemplateFromProject saves an existing project as a new template with the given name
+func CreateTemplateFromProject(ctx context.Context, db *sql.DB, projectID int, name string) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO project_templates (name, organization_id, title, description, website, time, max_capacity, area,
+		                               location_address, latitude, longitude, ages, serve_lead_name, serve_lead_email,
+		                               leads)
+		SELECT $2, organization_id, title, description, website, time, max_capacity, COALESCE(area, ''),
+		COALESCE(location_address, ''), COALESCE(latitude, 0), COALESCE(longitude, 0), ages,
+		COALESCE(serve_lead_name, ''), COALESCE(serve_lead_email, ''), leads
+		FROM projects
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING id
+	`
+
+	var id int
+	if err = tx.QueryRowContext(ctx, query, projectID, name).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, uniqueViolationAs(err, ErrTemplateExists)
+	}
+
+	query = `
+		INSERT INTO project_template_types (template_id, type_id)
+		SELECT $1, type_id FROM project_types WHERE project_id = $2
+	`
+	if _, err = tx.ExecContext(ctx, query, id, projectID); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// CreateProjectFromTemplate creates a pending project on the given date from a template
+func CreateProjectFromTemplate(ctx context.Context, db *sql.DB, templateID int, projectDate time.Time) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO projects (title, description, website, time, project_date, max_capacity, area, location_address,
+		                      latitude, longitude, serve_lead_name, serve_lead_email, ages, leads, organization_id,
+		                      status)
+		SELECT title, description, website, time, $2, max_capacity, area, location_address, latitude, longitude,
+		serve_lead_name, serve_lead_email, ages, leads, organization_id, $3
+		FROM project_templates
+		WHERE id = $1
+		RETURNING id
+	`
+
+	var id int
+	if err = tx.QueryRowContext(ctx, query, templateID, projectDate, StatusPending).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+
+	query = `
+		INSERT INTO project_types (project_id, type_id)
+		SELECT $1, type_id FROM project_template_types WHERE template_id = $2
+	`
+	if _, err = tx.ExecContext(ctx, query, id, templateID); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// CloneProjects copies projects, with their types and leads, onto a new date. The copies start out
+// pending with no registrations, and reveal their exact location on the default schedule. The IDs of the
+// new projects are returned in the order given.
+func CloneProjects(ctx context.Context, db *sql.DB, projectIDs []int, projectDate time.Time) ([]int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	cloneQuery := `
+		INSERT INTO projects (title, description, website, time, project_date, max_capacity, area, location_address,
+		                      latitude, longitude, serve_lead_id, serve_lead_name, serve_lead_email, ages, leads,
+		                      organization_id, status, exact_address, exact_latitude, exact_longitude,
+		                      early_access, registration_close_hours, cancellation_close_hours)
+		SELECT title, description, website, time, $2, max_capacity, area, location_address, latitude, longitude,
+		serve_lead_id, serve_lead_name, serve_lead_email, ages, leads, organization_id, $3, exact_address,
+		exact_latitude, exact_longitude, early_access, registration_close_hours, cancellation_close_hours
+		FROM projects
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING id
+	`
+	typesQuery := `
+		INSERT INTO project_types (project_id, type_id)
+		SELECT $1, type_id FROM project_types WHERE project_id = $2
+	`
+
+	ids := make([]int, 0, len(projectIDs))
+	for _, projectID := range projectIDs {
+		var id int
+		if err = tx.QueryRowContext(ctx, cloneQuery, projectID, projectDate, StatusPending).Scan(&id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("%w: project %d", ErrNotFound, projectID)
+			}
+			return nil, err
+		}
+
+		if _, err = tx.ExecContext(ctx, typesQuery, id, projectID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, tx.Commit()
+}
+
+// insertTemplateTypes inserts the types of a template within a transaction
+func insertTemplateTypes(ctx context.Context, tx *sql.Tx, t *ProjectTemplate) error {
+	for _, typ := range t.Types {
+		if _, err := tx.ExecContext(
+			ctx, `INSERT INTO project_template_types (template_id, type_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			t.ID, typ.ID,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateLeads returns the template's leads as JSON, defaulting to an empty list
+func templateLeads(t *ProjectTemplate) []byte {
+	if len(t.Leads) == 0 || string(t.Leads) == "null" {
+		return []byte("[]")
+	}
+	return t.Leads
+}
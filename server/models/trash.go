@@ -0,0 +1,390 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrNotFound is returned when the record to change does not exist or has already been deleted
+	ErrNotFound = errors.New("record not found")
+	// ErrRestoreConflict is returned when restoring a record would duplicate an active record
+	ErrRestoreConflict = errors.New("an active record already exists for this entry")
+)
+
+// uniqueViolation is the postgres error code for a unique constraint violation
+const uniqueViolation = "23505"
+
+// Trash holds every soft deleted record
+type Trash struct {
+	Projects      []DeletedProject      `json:"projects"`
+	Registrations []DeletedRegistration `json:"registrations"`
+	Users         []DeletedUser         `json:"users"`
+}
+
+// DeletedProject is a soft deleted project
+type DeletedProject struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	ProjectDate time.Time `json:"project_date"`
+	DeletedAt   time.Time `json:"deleted_at"`
+}
+
+// DeletedRegistration is a soft deleted or cancelled registration
+type DeletedRegistration struct {
+	ID           int       `json:"id"`
+	UserID       string    `json:"user_id"`
+	Email        string    `json:"email"`
+	ProjectID    int       `json:"project_id"`
+	ProjectTitle string    `json:"project_title"`
+	Status       string    `json:"status"`
+	GuestCount   int       `json:"guest_count"`
+	DeletedAt    time.Time `json:"deleted_at"`
+}
+
+// DeletedUser is a soft deleted user
+type DeletedUser struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// DeleteRegistration soft deletes a registration by its ID
+func DeleteRegistration(ctx context.Context, db *sql.DB, id int) error {
+	query := `UPDATE registrations SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	return execExpectingRow(ctx, db, query, id)
+}
+
+// DeleteUser soft deletes a user by their ID, along with their active registrations
+func DeleteUser(ctx context.Context, db *sql.DB, id string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var deletedAt time.Time
+	query := `UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL RETURNING deleted_at`
+	if err = tx.QueryRowContext(ctx, query, id).Scan(&deletedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	query = `UPDATE registrations SET deleted_at = $1 WHERE user_id = $2 AND deleted_at IS NULL`
+	if _, err = tx.ExecContext(ctx, query, deletedAt, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RestoreProject restores a soft deleted project and the registrations deleted along with it
+func RestoreProject(ctx context.Context, db *sql.DB, id int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE registrations r SET deleted_at = NULL
+		FROM projects p
+		WHERE p.id = $1 AND r.project_id = p.id AND r.deleted_at = p.deleted_at
+	`
+	if _, err = tx.ExecContext(ctx, query, id); err != nil {
+		return restoreError(err)
+	}
+
+	query = `
+		UPDATE projects SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+	if err = execExpectingRow(ctx, tx, query, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RestoreRegistration restores a soft deleted or cancelled registration. Like a new registration, it
+// needs the project to be open with room for the volunteer and their guests.
+func RestoreRegistration(ctx context.Context, db *sql.DB, id int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	var projectID, guestCount int
+	var groupReservationID *int
+	query := `
+		SELECT r.project_id, r.guest_count, r.group_reservation_id
+		FROM registrations r
+		JOIN users u ON u.id = r.user_id AND u.deleted_at IS NULL
+		WHERE r.id = $1 AND r.deleted_at IS NOT NULL
+	`
+	if err = tx.QueryRowContext(ctx, query, id).Scan(&projectID, &guestCount, &groupReservationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	if err = claimProjectSpots(ctx, tx, projectID, 1+guestCount, groupReservationID); err != nil {
+		return err
+	}
+
+	query = `
+		UPDATE registrations SET deleted_at = NULL, status = 'registered', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	if err = execExpectingRow(ctx, tx, query, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RestoreUser restores a soft deleted user and the registrations deleted along with them. Their spots may
+// have been taken since, so like restoring a single registration, each project still in use needs to be
+// open with room for them.
+func RestoreUser(ctx context.Context, db *sql.DB, id string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	if err = claimRestoredUserSpots(ctx, tx, id); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE registrations r SET deleted_at = NULL
+		FROM users u
+		WHERE u.id = $1 AND r.user_id = u.id AND r.deleted_at = u.deleted_at
+	`
+	if _, err = tx.ExecContext(ctx, query, id); err != nil {
+		return restoreError(err)
+	}
+
+	query = `
+		UPDATE users SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+	if err = execExpectingRow(ctx, tx, query, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// claimRestoredUserSpots claims the spots for each registration deleted along with the user on a project
+// that has not been deleted itself
+func claimRestoredUserSpots(ctx context.Context, tx *sql.Tx, id string) error {
+	type claim struct {
+		projectID, spots   int
+		groupReservationID *int
+	}
+
+	rows, err := tx.QueryContext(
+		ctx, `
+		SELECT r.project_id, 1 + r.guest_count, r.group_reservation_id
+		FROM registrations r
+		JOIN users u ON u.id = r.user_id
+		JOIN projects p ON p.id = r.project_id AND p.deleted_at IS NULL
+		WHERE u.id = $1 AND r.deleted_at = u.deleted_at
+		ORDER BY r.project_id
+	`, id,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	claims := []claim{}
+	for rows.Next() {
+		var c claim
+		if err = rows.Scan(&c.projectID, &c.spots, &c.groupReservationID); err != nil {
+			return err
+		}
+		claims = append(claims, c)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range claims {
+		if err = claimProjectSpots(ctx, tx, c.projectID, c.spots, c.groupReservationID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTrash retrieves every soft deleted project, registration and user, most recently deleted first
+func GetTrash(ctx context.Context, db *sql.DB) (*Trash, error) {
+	trash := &Trash{
+		Projects:      []DeletedProject{},
+		Registrations: []DeletedRegistration{},
+		Users:         []DeletedUser{},
+	}
+
+	rows, err := db.QueryContext(
+		ctx, `
+		SELECT id, title, project_date, deleted_at
+		FROM projects
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p DeletedProject
+		if err = rows.Scan(&p.ID, &p.Title, &p.ProjectDate, &p.DeletedAt); err != nil {
+			return nil, err
+		}
+		trash.Projects = append(trash.Projects, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	regRows, err := db.QueryContext(
+		ctx, `
+		SELECT r.id, r.user_id, u.email, r.project_id, p.title, r.status, r.guest_count, r.deleted_at
+		FROM registrations r
+		JOIN users u ON r.user_id = u.id
+		JOIN projects p ON r.project_id = p.id
+		WHERE r.deleted_at IS NOT NULL
+		ORDER BY r.deleted_at DESC
+	`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer regRows.Close()
+
+	for regRows.Next() {
+		var r DeletedRegistration
+		if err = regRows.Scan(
+			&r.ID, &r.UserID, &r.Email, &r.ProjectID, &r.ProjectTitle, &r.Status, &r.GuestCount, &r.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		trash.Registrations = append(trash.Registrations, r)
+	}
+	if err = regRows.Err(); err != nil {
+		return nil, err
+	}
+
+	userRows, err := db.QueryContext(
+		ctx, `
+		SELECT id, email, first_name, last_name, deleted_at
+		FROM users
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer userRows.Close()
+
+	for userRows.Next() {
+		var u DeletedUser
+		if err = userRows.Scan(&u.ID, &u.Email, &u.FirstName, &u.LastName, &u.DeletedAt); err != nil {
+			return nil, err
+		}
+		trash.Users = append(trash.Users, u)
+	}
+
+	return trash, userRows.Err()
+}
+
+// PurgeDeleted permanently removes records that were soft deleted before the cutoff. Removing a user also
+// removes their household, lead invitations and the groups they lead, so users who are still a project's
+// serve lead or lead a group still holding seats are kept.
+func PurgeDeleted(ctx context.Context, db *sql.DB, cutoff time.Time) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	queries := []string{
+		`DELETE FROM registrations WHERE deleted_at < $1`,
+		`DELETE FROM projects WHERE deleted_at < $1`,
+		`DELETE FROM users u WHERE u.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM projects p WHERE p.serve_lead_id = u.id)
+			AND NOT EXISTS (
+				SELECT 1 FROM groups g JOIN group_reservations gr ON gr.group_id = g.id
+				WHERE g.leader_id = u.id AND gr.released_at IS NULL AND gr.release_at > NOW()
+			)`,
+	}
+
+	var purged int64
+	for _, query := range queries {
+		result, err := tx.ExecContext(ctx, query, cutoff)
+		if err != nil {
+			return 0, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		purged += n
+	}
+
+	return purged, tx.Commit()
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// execExpectingRow runs a statement and returns ErrNotFound if it did not affect any rows
+func execExpectingRow(ctx context.Context, db execer, query string, args ...any) error {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return restoreError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// restoreError maps a unique constraint violation to ErrRestoreConflict
+func restoreError(err error) error {
+	return uniqueViolationAs(err, ErrRestoreConflict)
+}
+
+// uniqueViolationAs replaces a unique constraint violation with the target error
+func uniqueViolationAs(err error, target error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return target
+	}
+	return err
+}
@@ -26,7 +26,7 @@ func GetUserByID(ctx context.Context, db *sql.DB, id string) (*User, error) {
 	query := `
 		SELECT id, email, first_name, last_name, phone, text_permission, created_at, updated_at
 		FROM users
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var user User
@@ -50,7 +50,7 @@ func GetUserByEmail(ctx context.Context, db *sql.DB, email string) (*User, error
 	query := `
 		SELECT id, email, first_name, last_name, phone, text_permission, created_at, updated_at
 		FROM users
-		WHERE email = $1
+		WHERE email = $1 AND deleted_at IS NULL
 	`
 
 	var user User
@@ -94,7 +94,7 @@ func UpdateUser(ctx context.Context, db *sql.DB, user *User) error {
 	query := `
 		UPDATE users
 		SET email = $1, first_name = $2, last_name = $3, phone = $4, text_permission = $5, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $6
+		WHERE id = $6 AND deleted_at IS NULL
 		RETURNING updated_at
 	`
 
@@ -115,6 +115,7 @@ func GetAllUsers(ctx context.Context, db *sql.DB) ([]User, error) {
 	query := `
 		SELECT id, email, first_name, last_name, phone, text_permission, created_at, updated_at
 		FROM users
+		WHERE deleted_at IS NULL
 		ORDER BY last_name
 	`
 
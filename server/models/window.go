@@ -0,0 +1,211 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Audiences a registration window can be for
+const (
+	AudienceGroupLeaders = "group-leaders"
+	AudiencePublic       = "public"
+)
+
+// eventDateLayout is how event dates are written in the API and stored in the database
+const eventDateLayout = "2006-01-02"
+
+// ErrInvalidWindow is returned when a registration window has an unknown audience or closes before it opens
+var ErrInvalidWindow = errors.New("windows need a group-leaders or public audience and must close after opening")
+
+// RegistrationWindow is when one audience may register for the projects of an event, the projects on
+// one date. Group leaders can also register in the public window.
+type RegistrationWindow struct {
+	ID        int        `json:"id"`
+	EventDate string     `json:"event_date"`
+	Audience  string     `json:"audience"`
+	OpensAt   time.Time  `json:"opens_at"`
+	ClosesAt  *time.Time `json:"closes_at"`
+	// AccessCode lets group leaders without the group-leader role into their window
+	AccessCode string    `json:"access_code,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// RegistrationAccess is whether a volunteer may register for a project now, and if not, when they can
+type RegistrationAccess struct {
+	Open bool `json:"open"`
+	// Audience is the window the volunteer registers in while registration is open
+	Audience    string     `json:"audience,omitempty"`
+	OpensAt     *time.Time `json:"opens_at"`
+	ClosesAt    *time.Time `json:"closes_at"`
+	EarlyAccess bool       `json:"early_access"`
+	Message     string     `json:"message,omitempty"`
+}
+
+// Validate checks the window's event date, audience and times
+func (w *RegistrationWindow) Validate() error {
+	if _, err := time.Parse(eventDateLayout, w.EventDate); err != nil {
+		return fmt.Errorf("%w: event_date must look like 2025-07-12", ErrInvalidWindow)
+	}
+	if w.Audience != AudienceGroupLeaders && w.Audience != AudiencePublic {
+		return ErrInvalidWindow
+	}
+	if w.OpensAt.IsZero() || (w.ClosesAt != nil && !w.ClosesAt.After(w.OpensAt)) {
+		return ErrInvalidWindow
+	}
+	return nil
+}
+
+// openAt reports whether the window is open at the given time
+func (w *RegistrationWindow) openAt(now time.Time) bool {
+	return !now.Before(w.OpensAt) && (w.ClosesAt == nil || now.Before(*w.ClosesAt))
+}
+
+// EventDate is the event a project belongs to: its date in the serve day's timezone
+func EventDate(projectDate time.Time, loc *time.Location) string {
+	return projectDate.In(loc).Format(eventDateLayout)
+}
+
+// CheckRegistrationAccess works out whether a volunteer may register for a project from its event's windows.
+// Events without windows are always open. Early volunteers are group leaders and the members they invite,
+// who may use the group leaders' window as well as the public one; early access projects only take them.
+func CheckRegistrationAccess(
+	windows []RegistrationWindow, earlyAccessProject, early bool, now time.Time,
+) RegistrationAccess {
+	access := RegistrationAccess{EarlyAccess: earlyAccessProject}
+	if earlyAccessProject && !early {
+		access.Message = "This project is reserved for group leaders and their groups"
+		return access
+	}
+	if len(windows) == 0 {
+		access.Open = true
+		return access
+	}
+
+	var next *RegistrationWindow
+	for i := range windows {
+		w := &windows[i]
+		if w.Audience == AudienceGroupLeaders && !early {
+			continue
+		}
+		if w.openAt(now) {
+			access.Open, access.Audience, access.OpensAt, access.ClosesAt = true, w.Audience, &w.OpensAt, w.ClosesAt
+			return access
+		}
+		if now.Before(w.OpensAt) && (next == nil || w.OpensAt.Before(next.OpensAt)) {
+			next = w
+		}
+	}
+
+	if next == nil {
+		access.Message = "Registration has closed"
+		return access
+	}
+	access.Audience, access.OpensAt, access.ClosesAt = next.Audience, &next.OpensAt, next.ClosesAt
+	access.Message = "Registration has not opened yet"
+	return access
+}
+
+// GetRegistrationWindows gets the windows of the events on or after a date, in the order they open
+func GetRegistrationWindows(ctx context.Context, db *sql.DB, from string) ([]RegistrationWindow, error) {
+	return queryRegistrationWindows(ctx, db, `event_date >= $1`, from)
+}
+
+// GetEventWindows gets the windows of one event
+func GetEventWindows(ctx context.Context, db *sql.DB, eventDate string) ([]RegistrationWindow, error) {
+	return queryRegistrationWindows(ctx, db, `event_date = $1`, eventDate)
+}
+
+// GetRegistrationWindow gets an event's window for an audience, or nil if it has none
+func GetRegistrationWindow(ctx context.Context, db *sql.DB, eventDate, audience string) (
+	*RegistrationWindow, error,
+) {
+	windows, err := queryRegistrationWindows(ctx, db, `event_date = $1 AND audience = $2`, eventDate, audience)
+	if err != nil || len(windows) == 0 {
+		return nil, err
+	}
+	return &windows[0], nil
+}
+
+// SaveRegistrationWindow sets when an audience may register for an event, replacing any window it had
+func SaveRegistrationWindow(ctx context.Context, db *sql.DB, w *RegistrationWindow) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO registration_windows (event_date, audience, opens_at, closes_at, access_code)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (event_date, audience) DO UPDATE
+		SET opens_at = EXCLUDED.opens_at, closes_at = EXCLUDED.closes_at, access_code = EXCLUDED.access_code,
+		updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+	return db.QueryRowContext(
+		ctx, query, w.EventDate, w.Audience, w.OpensAt, w.ClosesAt, w.AccessCode,
+	).Scan(&w.ID, &w.CreatedAt, &w.UpdatedAt)
+}
+
+// DeleteRegistrationWindow removes a window, returning it
+func DeleteRegistrationWindow(ctx context.Context, db *sql.DB, id int) (*RegistrationWindow, error) {
+	query := `
+		DELETE FROM registration_windows WHERE id = $1
+		RETURNING id, to_char(event_date, 'YYYY-MM-DD'), audience, opens_at, closes_at, access_code, created_at,
+		updated_at
+	`
+
+	var w RegistrationWindow
+	var closesAt sql.NullTime
+	err := db.QueryRowContext(ctx, query, id).Scan(
+		&w.ID, &w.EventDate, &w.Audience, &w.OpensAt, &closesAt, &w.AccessCode, &w.CreatedAt, &w.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if closesAt.Valid {
+		w.ClosesAt = &closesAt.Time
+	}
+	return &w, nil
+}
+
+// queryRegistrationWindows loads the windows matching the condition
+func queryRegistrationWindows(ctx context.Context, db *sql.DB, condition string, args ...any) (
+	[]RegistrationWindow, error,
+) {
+	query := `
+		SELECT id, to_char(event_date, 'YYYY-MM-DD'), audience, opens_at, closes_at, access_code, created_at,
+		updated_at
+		FROM registration_windows
+		WHERE ` + condition + `
+		ORDER BY event_date, opens_at
+	`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := []RegistrationWindow{}
+	for rows.Next() {
+		var w RegistrationWindow
+		var closesAt sql.NullTime
+		if err = rows.Scan(
+			&w.ID, &w.EventDate, &w.Audience, &w.OpensAt, &closesAt, &w.AccessCode, &w.CreatedAt, &w.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if closesAt.Valid {
+			w.ClosesAt = &closesAt.Time
+		}
+		windows = append(windows, w)
+	}
+
+	return windows, rows.Err()
+}
@@ -0,0 +1,73 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"serve/models"
+)
+
+func TestCheckRegistrationAccess(t *testing.T) {
+	leadersOpen := time.Date(2025, 6, 5, 15, 0, 0, 0, time.UTC)
+	publicOpen := time.Date(2025, 6, 8, 15, 0, 0, 0, time.UTC)
+	publicClose := time.Date(2025, 7, 6, 6, 0, 0, 0, time.UTC)
+	windows := []models.RegistrationWindow{
+		{Audience: models.AudienceGroupLeaders, OpensAt: leadersOpen, ClosesAt: &publicOpen},
+		{Audience: models.AudiencePublic, OpensAt: publicOpen, ClosesAt: &publicClose},
+	}
+	betweenWindows := leadersOpen.Add(24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		windows     []models.RegistrationWindow
+		earlyAccess bool
+		early       bool
+		now         time.Time
+		open        bool
+		audience    string
+		opensAt     *time.Time
+	}{
+		{name: "no windows", now: betweenWindows, open: true},
+		{name: "before any window", windows: windows, early: true, now: leadersOpen.Add(-time.Hour),
+			audience: models.AudienceGroupLeaders, opensAt: &leadersOpen},
+		{name: "group leaders in their window", windows: windows, early: true, now: betweenWindows, open: true,
+			audience: models.AudienceGroupLeaders},
+		{name: "public in the group leaders window", windows: windows, now: betweenWindows,
+			audience: models.AudiencePublic, opensAt: &publicOpen},
+		{name: "public in their window", windows: windows, now: publicOpen, open: true,
+			audience: models.AudiencePublic},
+		{name: "group leaders in the public window", windows: windows, early: true, now: publicOpen, open: true,
+			audience: models.AudiencePublic},
+		{name: "after every window", windows: windows, early: true, now: publicClose},
+		{name: "public on an early access project", windows: windows, earlyAccess: true, now: publicOpen},
+		{name: "group leaders on an early access project", windows: windows, earlyAccess: true, early: true,
+			now: betweenWindows, open: true, audience: models.AudienceGroupLeaders},
+		{name: "public on an early access project without windows", earlyAccess: true, now: publicOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			access := models.CheckRegistrationAccess(tt.windows, tt.earlyAccess, tt.early, tt.now)
+			assert.Equal(t, tt.open, access.Open)
+			assert.Equal(t, tt.audience, access.Audience)
+			assert.Equal(t, tt.earlyAccess, access.EarlyAccess)
+			if tt.opensAt != nil {
+				assert.Equal(t, *tt.opensAt, *access.OpensAt)
+			}
+			if !tt.open {
+				assert.NotEmpty(t, access.Message)
+			}
+		})
+	}
+}
+
+func TestEventDate(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+
+	// projects start at 8:00 UTC, which is still the same day in Denver
+	assert.Equal(t, "2025-07-12", models.EventDate(time.Date(2025, 7, 12, 8, 0, 0, 0, time.UTC), denver))
+}
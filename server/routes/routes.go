@@ -0,0 +1,110 @@
+package routes
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/auth0/go-jwt-middleware/v2"
+	"github.com/gorilla/mux"
+	"serve/config"
+	"serve/handlers"
+	"serve/middleware"
+	"serve/services"
+)
+
+// Dependencies are the services the API routes are built on
+type Dependencies struct {
+	DB           *sql.DB
+	Config       *config.Config
+	EmailService *services.EmailService
+	TextService  *services.TextService
+	Geocoder     services.Geocoder
+	// ValidateToken checks the bearer tokens of signed-in routes
+	ValidateToken jwtmiddleware.ValidateToken
+	// LocalIssuer is set when tokens are signed locally, and mounts the route that mints them
+	LocalIssuer *middleware.LocalIssuer
+}
+
+// NewTokenValidator returns the token validator for the configured auth mode. In local mode it also
+// returns the issuer that signs the tokens it accepts.
+func NewTokenValidator(cfg *config.Config) (jwtmiddleware.ValidateToken, *middleware.LocalIssuer, error) {
+	switch cfg.AuthMode {
+	case config.AuthModeAuth0:
+		validateToken, err := middleware.NewAuth0Validator(cfg)
+		return validateToken, nil, err
+	case config.AuthModeLocal:
+		issuer, err := middleware.NewLocalIssuer(cfg.Auth0Audience)
+		if err != nil {
+			return nil, nil, err
+		}
+		return issuer.ValidateToken, issuer, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown auth mode %q", cfg.AuthMode)
+	}
+}
+
+// Register mounts the health check and every API route, which live under /api
+func Register(r *mux.Router, deps Dependencies) {
+	db, cfg := deps.DB, deps.Config
+	auth := middleware.AuthMiddleware(deps.ValidateToken)
+
+	// Health check, outside /api so it is easy to point load balancers at
+	healthHandler := &handlers.HealthHandler{
+		DB:     db,
+		Config: cfg,
+	}
+	r.HandleFunc("/healthz", healthHandler.GetHealth).Methods(http.MethodGet)
+
+	// API routes (with auth)
+	api := r.PathPrefix("/api").Subrouter()
+
+	// User routes
+	userRouter := api.PathPrefix("/users").Subrouter()
+	handlers.RegisterUserRoutes(userRouter, db, cfg, deps.EmailService, auth)
+
+	// Project routes
+	projectRouter := api.PathPrefix("/projects").Subrouter()
+	handlers.RegisterProjectRoutes(
+		projectRouter, db, cfg, deps.EmailService, deps.TextService, deps.Geocoder, auth,
+		middleware.OptionalAuthMiddleware(deps.ValidateToken),
+	)
+
+	// Group routes
+	groupRouter := api.PathPrefix("/groups").Subrouter()
+	handlers.RegisterGroupRoutes(groupRouter, db, cfg, auth)
+
+	// Calendar feed routes
+	calendarRouter := api.PathPrefix("/calendar").Subrouter()
+	handlers.RegisterCalendarRoutes(calendarRouter, db, cfg)
+
+	// Partner project submission routes
+	submissionRouter := api.PathPrefix("/submissions").Subrouter()
+	handlers.RegisterSubmissionRoutes(submissionRouter, db, cfg, deps.EmailService)
+
+	// Lead invitation routes, answered through the emailed link
+	leadInvitationRouter := api.PathPrefix("/lead-invitations").Subrouter()
+	handlers.RegisterLeadInvitationRoutes(leadInvitationRouter, db)
+
+	// Admin routes
+	adminRouter := api.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(auth)
+	adminRouter.Use(middleware.AccessMiddleware(handlers.AccessLoader(db)))
+	handlers.RegisterAdminRoutes(
+		adminRouter, db, cfg, deps.EmailService, deps.TextService,
+		services.NewLocationValidator(deps.Geocoder, cfg.LocationWarningMeters),
+	)
+
+	// Development token routes, only with local auth
+	if deps.LocalIssuer != nil {
+		authRouter := api.PathPrefix("/auth").Subrouter()
+		handlers.RegisterLocalAuthRoutes(authRouter, deps.LocalIssuer)
+	}
+
+	// Geocoding routes
+	geocodingHandler := &handlers.GeocodingHandler{
+		Geocoder: deps.Geocoder,
+	}
+	api.HandleFunc("/geocode", geocodingHandler.GeocodeAddress).Methods("POST")
+	api.HandleFunc("/geocode/reverse", geocodingHandler.ReverseGeocode).Methods("POST")
+}
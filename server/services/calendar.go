@@ -0,0 +1,182 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"serve/models"
+)
+
+// icalTimeFormat is the UTC date-time form used throughout generated calendars
+const icalTimeFormat = "20060102T150405Z"
+
+// shiftTimePattern matches one end of a shift such as "9", "9am", "9:30 a.m." or "12:00 PM"
+var shiftTimePattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*([ap])?\.?\s*(?:m\.?)?$`)
+
+// shiftSeparator splits the start and end of a shift such as "9am - 12pm" or "9 to 12"
+var shiftSeparator = regexp.MustCompile(`\s*(?:-|–|—|\bto\b)\s*`)
+
+// CalendarEvent is a project as it appears in a calendar
+type CalendarEvent struct {
+	Project models.Project
+	// AllDay is set when the project's shift could not be read, so only its date is known
+	AllDay     bool
+	Start, End time.Time
+}
+
+// NewCalendarEvent places a project on the calendar. The date comes from ProjectDate and the times from
+// the shift in Time, read in the given location; a shift that cannot be read makes an all-day event.
+func NewCalendarEvent(project models.Project, loc *time.Location) CalendarEvent {
+	// project dates are stored as the day at 8:00 UTC, so the UTC date is the local date
+	year, month, day := project.ProjectDate.UTC().Date()
+	date := time.Date(year, month, day, 0, 0, 0, 0, loc)
+
+	start, end, ok := ParseShift(date, project.Time)
+	if !ok {
+		return CalendarEvent{Project: project, AllDay: true, Start: date, End: date.AddDate(0, 0, 1)}
+	}
+	return CalendarEvent{Project: project, Start: start, End: end}
+}
+
+// ParseShift reads a shift such as "9am - 12pm" on the given date. A start without am or pm takes the
+// end's, unless that would put it after the end.
+func ParseShift(date time.Time, shift string) (start, end time.Time, ok bool) {
+	parts := shiftSeparator.Split(strings.ToLower(strings.TrimSpace(shift)), 2)
+	if len(parts) != 2 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	startHour, startMinute, startMeridiem, ok := parseShiftTime(parts[0])
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	endHour, endMinute, endMeridiem, ok := parseShiftTime(parts[1])
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+
+	endHour = to24Hour(endHour, endMeridiem)
+	if startMeridiem == "" {
+		startMeridiem = endMeridiem
+		if to24Hour(startHour, startMeridiem)*60+startMinute > endHour*60+endMinute {
+			startMeridiem = "a"
+		}
+	}
+	startHour = to24Hour(startHour, startMeridiem)
+
+	start = time.Date(date.Year(), date.Month(), date.Day(), startHour, startMinute, 0, 0, date.Location())
+	end = time.Date(date.Year(), date.Month(), date.Day(), endHour, endMinute, 0, 0, date.Location())
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, end, true
+}
+
+// parseShiftTime reads one end of a shift into an hour, minute and "a", "p" or "" for no meridiem
+func parseShiftTime(value string) (hour, minute int, meridiem string, ok bool) {
+	m := shiftTimePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return 0, 0, "", false
+	}
+
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if hour > 23 || minute > 59 || (m[3] != "" && (hour < 1 || hour > 12)) {
+		return 0, 0, "", false
+	}
+	return hour, minute, m[3], true
+}
+
+// to24Hour converts a 12-hour clock hour to 24 hours. Hours without a meridiem are taken as given,
+// except that 1 through 6 are assumed to be in the afternoon.
+func to24Hour(hour int, meridiem string) int {
+	switch {
+	case meridiem == "a" && hour == 12:
+		return 0
+	case meridiem == "p" && hour < 12:
+		return hour + 12
+	case meridiem == "" && hour >= 1 && hour <= 6:
+		return hour + 12
+	}
+	return hour
+}
+
+// BuildCalendar renders events as an iCalendar document. Times are written in UTC so every calendar
+// app shows them in its own timezone, and each project keeps the same UID so changes to it replace the
+// earlier copy.
+func BuildCalendar(name string, events []CalendarEvent, now time.Time) string {
+	var b strings.Builder
+	line := func(l string) { writeFolded(&b, l) }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//JourneyCo//Serve Day//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escapeText(name))
+	line("X-PUBLISHED-TTL:PT1H")
+
+	for _, e := range events {
+		p := e.Project
+		line("BEGIN:VEVENT")
+		line(fmt.Sprintf("UID:project-%d@serve.journeycolorado.com", p.ID))
+		line("DTSTAMP:" + now.UTC().Format(icalTimeFormat))
+		if !p.UpdatedAt.IsZero() {
+			line("LAST-MODIFIED:" + p.UpdatedAt.UTC().Format(icalTimeFormat))
+		}
+		line("SEQUENCE:" + strconv.Itoa(p.Revision))
+		if e.AllDay {
+			line("DTSTART;VALUE=DATE:" + e.Start.Format("20060102"))
+			line("DTEND;VALUE=DATE:" + e.End.Format("20060102"))
+		} else {
+			line("DTSTART:" + e.Start.UTC().Format(icalTimeFormat))
+			line("DTEND:" + e.End.UTC().Format(icalTimeFormat))
+		}
+		line("SUMMARY:" + escapeText("Serve Day: "+p.Title))
+		line("DESCRIPTION:" + escapeText(p.Description))
+		if p.LocationAddress != "" {
+			line("LOCATION:" + escapeText(p.LocationAddress))
+		}
+		if p.Latitude != 0 || p.Longitude != 0 {
+			line(fmt.Sprintf("GEO:%f;%f", p.Latitude, p.Longitude))
+		}
+		if p.Status == models.StatusDidNotOccur {
+			line("STATUS:CANCELLED")
+		} else {
+			line("STATUS:CONFIRMED")
+		}
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+	return b.String()
+}
+
+// escapeText escapes a value for an iCalendar TEXT property
+func escapeText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`,
+	).Replace(value)
+}
+
+// writeFolded writes a content line, folding it at 75 octets without splitting a UTF-8 character
+func writeFolded(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 { // back up to the start of a character
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,81 @@
+package services_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"serve/models"
+	"serve/services"
+)
+
+func TestParseShift(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+	assert.NoError(t, err)
+	date := time.Date(2025, time.July, 12, 0, 0, 0, 0, denver)
+
+	tests := []struct {
+		shift      string
+		start, end string
+		ok         bool
+	}{
+		{"9am - 12pm", "09:00", "12:00", true},
+		{"9:30 a.m. to 12:15 p.m.", "09:30", "12:15", true},
+		{"8-11am", "08:00", "11:00", true},
+		{"11 - 2pm", "11:00", "14:00", true},
+		{"1 - 4", "13:00", "16:00", true},
+		{"6:00 PM – 8:00 PM", "18:00", "20:00", true},
+		{"All day", "", "", false},
+		{"12pm - 9am", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shift, func(t *testing.T) {
+			start, end, ok := services.ParseShift(date, tt.shift)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.start, start.Format("15:04"))
+				assert.Equal(t, tt.end, end.Format("15:04"))
+			}
+		})
+	}
+}
+
+func TestBuildCalendar(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+	assert.NoError(t, err)
+
+	project := models.Project{
+		ID:              7,
+		Title:           "Yard Work, Castle Rock",
+		Description:     "Raking; mulching\nand planting " + strings.Repeat("bulbs ", 20),
+		Time:            "9am - 12pm",
+		ProjectDate:     time.Date(2025, time.July, 12, 8, 0, 0, 0, time.UTC),
+		LocationAddress: "100 Third St, Castle Rock, CO",
+		Latitude:        39.3722,
+		Longitude:       -104.8561,
+		Revision:        3,
+	}
+	calendar := services.BuildCalendar(
+		"Serve Day", []services.CalendarEvent{services.NewCalendarEvent(project, denver)}, time.Now(),
+	)
+
+	assert.Contains(t, calendar, "UID:project-7@serve.journeycolorado.com\r\n")
+	assert.Contains(t, calendar, "SEQUENCE:3\r\n")
+	assert.Contains(t, calendar, "DTSTART:20250712T150000Z\r\n", "9am in Denver during daylight time is 15:00 UTC")
+	assert.Contains(t, calendar, "DTEND:20250712T180000Z\r\n")
+	assert.Contains(t, calendar, `SUMMARY:Serve Day: Yard Work\, Castle Rock`)
+	assert.Contains(t, calendar, `DESCRIPTION:Raking\; mulching\nand planting`)
+	assert.Contains(t, calendar, "GEO:39.372200;-104.856100\r\n")
+	for _, line := range strings.Split(calendar, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "lines must be folded at 75 octets")
+	}
+
+	project.Time = "TBD"
+	allDay := services.BuildCalendar(
+		"Serve Day", []services.CalendarEvent{services.NewCalendarEvent(project, denver)}, time.Now(),
+	)
+	assert.Contains(t, allDay, "DTSTART;VALUE=DATE:20250712\r\n")
+	assert.Contains(t, allDay, "DTEND;VALUE=DATE:20250713\r\n")
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sync"
+)
+
+const (
+	// roadFactor scales a straight-line distance to a typical distance by road
+	roadFactor = 1.3
+	// averageSpeedKmh is the average speed assumed for a drive across town
+	averageSpeedKmh = 50.0
+)
+
+// DriveEstimate is the estimated drive to a project from the configured origin, usually the church
+// campus, with a link to turn-by-turn directions
+type DriveEstimate struct {
+	Origin        string  `json:"origin"`
+	DistanceKm    float64 `json:"distance_km"`
+	Minutes       int     `json:"minutes"`
+	DirectionsURL string  `json:"directions_url"`
+}
+
+// DriveEstimator estimates drives from a fixed origin address, which is geocoded once on first use
+type DriveEstimator struct {
+	geocoder Geocoder
+	origin   string
+
+	mu       sync.Mutex
+	location *GeocodingResult
+
+	// failedCtx is the request the origin last failed to geocode in, so the rest of that request does
+	// not retry it for every project
+	failedCtx context.Context
+	failure   error
+}
+
+// NewDriveEstimator creates an estimator for drives from the origin address. An empty origin disables
+// estimates.
+func NewDriveEstimator(geocoder Geocoder, origin string) *DriveEstimator {
+	return &DriveEstimator{geocoder: geocoder, origin: origin}
+}
+
+// Estimate returns the estimated drive to a point, or nil when no origin is configured or the point
+// has no coordinates
+func (d *DriveEstimator) Estimate(ctx context.Context, lat, lng float64) (*DriveEstimate, error) {
+	if d == nil || d.origin == "" || (lat == 0 && lng == 0) {
+		return nil, nil
+	}
+
+	origin, err := d.originLocation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	distance := DistanceKm(origin.Latitude, origin.Longitude, lat, lng) * roadFactor
+	params := url.Values{}
+	params.Add("api", "1")
+	params.Add("origin", d.origin)
+	params.Add("destination", fmt.Sprintf("%f,%f", lat, lng))
+	params.Add("travelmode", "driving")
+
+	return &DriveEstimate{
+		Origin:        d.origin,
+		DistanceKm:    math.Round(distance*10) / 10,
+		Minutes:       int(math.Ceil(distance / averageSpeedKmh * 60)),
+		DirectionsURL: "https://www.google.com/maps/dir/?" + params.Encode(),
+	}, nil
+}
+
+// originLocation geocodes the origin, remembering it once found. A failure is remembered for the rest
+// of the request, and the next request tries again.
+func (d *DriveEstimator) originLocation(ctx context.Context) (*GeocodingResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.location != nil {
+		return d.location, nil
+	}
+	if d.failedCtx == ctx {
+		return nil, d.failure
+	}
+
+	location, err := d.geocoder.Geocode(ctx, d.origin)
+	if err != nil {
+		d.failedCtx, d.failure = ctx, fmt.Errorf("error geocoding drive origin: %w", err)
+		return nil, d.failure
+	}
+	d.location = location
+	return d.location, nil
+}
@@ -0,0 +1,28 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"serve/services"
+	"serve/testutils"
+)
+
+func TestDriveEstimatorRemembersFailedOriginForRequest(t *testing.T) {
+	geocoder := testutils.NewFakeGeocoder(nil)
+	estimator := services.NewDriveEstimator(geocoder, "1 Nowhere Ln")
+
+	request, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for range 3 {
+		estimate, err := estimator.Estimate(request, 39.37, -104.85)
+		assert.Error(t, err)
+		assert.Nil(t, estimate)
+	}
+	assert.Len(t, geocoder.Calls(), 1)
+
+	_, err := estimator.Estimate(context.Background(), 39.37, -104.85)
+	assert.Error(t, err)
+	assert.Len(t, geocoder.Calls(), 2)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"html/template"
@@ -15,6 +16,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"golang.org/x/time/rate"
 	"serve/config"
 	"serve/models"
 )
@@ -22,7 +24,12 @@ import (
 const (
 	OneDay       = "one_day.html"
 	OneWeek      = "one_week.html"
+	Cancelled    = "project_cancelled.html"
+	CarpoolMatch = "carpool_match.html"
+	Changed      = "project_changed.html"
+	LeadInvite   = "lead_invitation.html"
 	Registration = "registration.html"
+	Submission   = "submission_received.html"
 	ThankYou     = "thank_you.html"
 	TwoWeeks     = "two_week.html"
 )
@@ -31,6 +38,15 @@ const (
 type EmailService struct {
 	Config *config.Config
 	auth   smtp.Auth
+	// scheduled paces the reminders and change notices the scheduler sends, together, to 150 emails an hour
+	scheduled *rate.Limiter
+}
+
+// emailAttachment is a file attached to an email
+type emailAttachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
 }
 
 // EmailService handles email operations
@@ -48,13 +64,17 @@ func NewEmailService(cfg *config.Config) *EmailService {
 	)
 
 	return &EmailService{
-		Config: cfg,
-		auth:   auth,
+		Config:    cfg,
+		auth:      auth,
+		scheduled: rate.NewLimiter(rate.Every(24*time.Second), 1),
 	}
 }
 
-// SendRegistrationConfirmation sends a confirmation email when a user registers for a project
-func (s *EmailService) SendRegistrationConfirmation(user *models.User, project *models.Project, guests int) {
+// SendRegistrationConfirmation sends a confirmation email when a user registers for a project, with the
+// project attached as a calendar event and a link to the user's calendar feed
+func (s *EmailService) SendRegistrationConfirmation(
+	user *models.User, project *models.Project, guests int, calendarURL string,
+) {
 	ctx, cancel := context.WithTimeout(context.Background(), 24*time.Hour)
 	defer cancel()
 	subject := fmt.Sprintf("Serve Day Project Confirmation")
@@ -62,6 +82,10 @@ func (s *EmailService) SendRegistrationConfirmation(user *models.User, project *
 	// Format dates
 	projectDateFormatted := project.ProjectDate.Format("Monday, January 2, 2006")
 
+	// Private addresses are only included once they have been revealed
+	visible := project.ForVolunteers(time.Now(), s.Config.LocationRevealDays)
+	project = &visible
+
 	// Create email data
 	data := struct {
 		Name            string
@@ -73,6 +97,7 @@ func (s *EmailService) SendRegistrationConfirmation(user *models.User, project *
 		Time            string
 		ProjectDateFull time.Time
 		Guests          int
+		CalendarURL     string
 	}{
 		Name:            fmt.Sprintf("%s %s", user.FirstName, user.LastName),
 		ProjectTitle:    project.Title,
@@ -83,6 +108,19 @@ func (s *EmailService) SendRegistrationConfirmation(user *models.User, project *
 		Time:            project.Time,
 		ProjectDateFull: project.ProjectDate,
 		Guests:          guests,
+		CalendarURL:     calendarURL,
+	}
+
+	var attachments []emailAttachment
+	if loc, err := s.Config.Location(); err != nil {
+		log.Printf("Failed to build calendar attachment: %v", err)
+	} else {
+		event := NewCalendarEvent(*project, loc)
+		attachments = append(attachments, emailAttachment{
+			Filename:    "serve-day.ics",
+			ContentType: "text/calendar",
+			Content:     []byte(BuildCalendar("Serve Day", []CalendarEvent{event}, time.Now())),
+		})
 	}
 
 	ticker := time.NewTicker(1 * time.Minute)
@@ -94,7 +132,7 @@ func (s *EmailService) SendRegistrationConfirmation(user *models.User, project *
 			log.Printf("Email failed after 24 hours: %v", data)
 			return
 		case <-ticker.C:
-			err := s.sendEmail(ctx, user.Email, subject, Registration, data)
+			err := s.sendEmail(ctx, user.Email, subject, Registration, data, attachments...)
 			if err == nil {
 				log.Printf("Email succeeded on attempt %d to %s", attempt, user.Email)
 				return
@@ -127,6 +165,9 @@ func (s *EmailService) SendReminderEmail(registration *models.Registration, days
 	// Format dates
 	projectDateFormatted := registration.Project.ProjectDate.Format("Monday, January 2, 2006")
 
+	// Reminders carry the exact address of a private location once it has been revealed
+	project := registration.Project.ForVolunteers(time.Now(), s.Config.LocationRevealDays)
+
 	// Create email data
 	data := struct {
 		Name             string
@@ -151,7 +192,7 @@ func (s *EmailService) SendReminderEmail(registration *models.Registration, days
 		ServeLeaderName:  registration.Project.ServeLeadName,
 		Guests:           registration.GuestCount,
 		Area:             registration.Project.Area,
-		Address:          registration.Project.LocationAddress,
+		Address:          project.LocationAddress,
 	}
 
 	ctx := context.Background()
@@ -159,8 +200,183 @@ func (s *EmailService) SendReminderEmail(registration *models.Registration, days
 	return s.sendEmail(ctx, registration.User.Email, subject, templateStr, data)
 }
 
+// SendProjectCancelled lets every released registrant know that their project will not take place
+func (s *EmailService) SendProjectCancelled(
+	project *models.Project, registrations []models.Registration, reason string,
+) {
+	ctx := context.Background()
+	subject := fmt.Sprintf("Your Journey Serve Day Project Has Been Cancelled: %s", project.Title)
+	projectDateFormatted := project.ProjectDate.Format("Monday, January 2, 2006")
+
+	// Rate limit to 150 emails per hour (24 seconds between emails)
+	ticker := time.NewTicker(24 * time.Second)
+	defer ticker.Stop()
+
+	for i, reg := range registrations {
+		data := struct {
+			Name         string
+			ProjectTitle string
+			ProjectDate  string
+			Reason       string
+			Guests       int
+		}{
+			Name:         fmt.Sprintf("%s %s", reg.User.FirstName, reg.User.LastName),
+			ProjectTitle: project.Title,
+			ProjectDate:  projectDateFormatted,
+			Reason:       reason,
+			Guests:       reg.GuestCount,
+		}
+
+		if err := s.sendEmailWithRetry(ctx, reg.User.Email, subject, Cancelled, data); err != nil {
+			log.Printf("Failed to send cancellation email to %s: %v", reg.User.Email, err)
+		}
+
+		// Wait for rate limit except for the last email
+		if i < len(registrations)-1 {
+			<-ticker.C
+		}
+	}
+}
+
+// SendProjectChanged tells every registrant what changed about their project. It returns an error only
+// when none of the emails could be sent, so sending again does not repeat it to anyone.
+func (s *EmailService) SendProjectChanged(
+	project *models.Project, registrations []models.Registration, changes []models.ProjectChange,
+) error {
+	ctx := context.Background()
+	subject := fmt.Sprintf("Your Journey Serve Day Project Has Changed: %s", project.Title)
+	projectDateFormatted := project.ProjectDate.Format("Monday, January 2, 2006")
+
+	var lastErr error
+	sent := 0
+	for _, reg := range registrations {
+		data := struct {
+			Name         string
+			ProjectTitle string
+			ProjectDate  string
+			Time         string
+			Address      string
+			Changes      []models.ProjectChange
+		}{
+			Name:         fmt.Sprintf("%s %s", reg.User.FirstName, reg.User.LastName),
+			ProjectTitle: project.Title,
+			ProjectDate:  projectDateFormatted,
+			Time:         project.Time,
+			Address:      project.LocationAddress,
+			Changes:      changes,
+		}
+
+		// Share the scheduler's rate limit with reminders and other change notices
+		if err := s.scheduled.Wait(ctx); err != nil {
+			lastErr = err
+			break
+		}
+		if err := s.sendEmailWithRetry(ctx, reg.User.Email, subject, Changed, data); err != nil {
+			log.Printf("Failed to send project change email to %s: %v", reg.User.Email, err)
+			lastErr = err
+		} else {
+			sent++
+		}
+	}
+
+	if sent == 0 && lastErr != nil {
+		return fmt.Errorf("no project change emails were sent: %w", lastErr)
+	}
+	return nil
+}
+
+// SendSubmissionReceived sends a partner organization the tracking link for their project submission
+func (s *EmailService) SendSubmissionReceived(submission *models.Submission, trackingURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	subject := fmt.Sprintf("Serve Day Project Submission Received: %s", submission.Project.Title)
+	data := struct {
+		Name         string
+		Organization string
+		ProjectTitle string
+		TrackingURL  string
+	}{
+		Name:         submission.ContactName,
+		Organization: submission.Organization,
+		ProjectTitle: submission.Project.Title,
+		TrackingURL:  trackingURL,
+	}
+
+	if err := s.sendEmailWithRetry(ctx, submission.ContactEmail, subject, Submission, data); err != nil {
+		log.Printf("Failed to send submission email to %s: %v", submission.ContactEmail, err)
+	}
+}
+
+// SendLeadInvitation asks a lead-interested volunteer to lead a project, with the link they answer through
+func (s *EmailService) SendLeadInvitation(invitation *models.LeadInvitation, inviteURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	subject := fmt.Sprintf("Will You Lead a Serve Day Project? %s", invitation.ProjectTitle)
+	data := struct {
+		Name         string
+		ProjectTitle string
+		ProjectDate  string
+		Message      string
+		InviteURL    string
+	}{
+		Name:         fmt.Sprintf("%s %s", invitation.FirstName, invitation.LastName),
+		ProjectTitle: invitation.ProjectTitle,
+		ProjectDate:  invitation.ProjectDate.Format("Monday, January 2, 2006"),
+		Message:      invitation.Message,
+		InviteURL:    inviteURL,
+	}
+
+	if err := s.sendEmailWithRetry(ctx, invitation.Email, subject, LeadInvite, data); err != nil {
+		log.Printf("Failed to send lead invitation email to %s: %v", invitation.Email, err)
+	}
+}
+
+// SendCarpoolMatch introduces a driver and rider to each other, sending each the other's contact
+func (s *EmailService) SendCarpoolMatch(project *models.Project, match models.CarpoolMatch) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	subject := fmt.Sprintf("Your Serve Day Carpool: %s", project.Title)
+	for _, pair := range []struct{ to, other models.Carpool }{
+		{match.Driver, match.Rider},
+		{match.Rider, match.Driver},
+	} {
+		data := struct {
+			Name          string
+			ProjectTitle  string
+			ProjectDate   string
+			Time          string
+			Driving       bool
+			PartnerName   string
+			PartnerEmail  string
+			PartnerPhone  string
+			Seats         int
+			DepartureArea string
+		}{
+			Name:          fmt.Sprintf("%s %s", pair.to.User.FirstName, pair.to.User.LastName),
+			ProjectTitle:  project.Title,
+			ProjectDate:   project.ProjectDate.Format("Monday, January 2, 2006"),
+			Time:          project.Time,
+			Driving:       pair.to.Role == models.CarpoolDriver,
+			PartnerName:   fmt.Sprintf("%s %s", pair.other.User.FirstName, pair.other.User.LastName),
+			PartnerEmail:  pair.other.User.Email,
+			PartnerPhone:  pair.other.User.Phone,
+			Seats:         match.Rider.Seats,
+			DepartureArea: match.Driver.DepartureArea,
+		}
+
+		if err := s.sendEmailWithRetry(ctx, pair.to.User.Email, subject, CarpoolMatch, data); err != nil {
+			log.Printf("Failed to send carpool email to %s: %v", pair.to.User.Email, err)
+		}
+	}
+}
+
 // sendEmail is a helper function to send emails
-func (s *EmailService) sendEmail(ctx context.Context, to, subject, templateStr string, data interface{}) error {
+func (s *EmailService) sendEmail(
+	ctx context.Context, to, subject, templateStr string, data interface{}, attachments ...emailAttachment,
+) error {
 	// Parse template
 	p := filepath.Join("templates", templateStr)
 	name := path.Base(p)
@@ -186,6 +402,18 @@ func (s *EmailService) sendEmail(ctx context.Context, to, subject, templateStr s
 			"name":  "Sara Wiest",
 		},
 	}
+	if len(attachments) > 0 {
+		files := make([]map[string]string, 0, len(attachments))
+		for _, a := range attachments {
+			files = append(files, map[string]string{
+				"filename":    a.Filename,
+				"type":        a.ContentType,
+				"content":     base64.StdEncoding.EncodeToString(a.Content),
+				"disposition": "attachment",
+			})
+		}
+		payload["attachments"] = files
+	}
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal email template: %w", err)
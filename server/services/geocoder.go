@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"serve/config"
+	"serve/models"
+)
+
+// geocodeTimeout bounds each request to a geocoding provider
+const geocodeTimeout = 10 * time.Second
+
+// ErrNoResults is returned when a geocoder cannot find an address
+var ErrNoResults = errors.New("no results found for address")
+
+// Geocoder converts addresses to coordinates, and coordinates back to addresses
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) (*GeocodingResult, error)
+	ReverseGeocode(ctx context.Context, lat, lng float64) (*GeocodingResult, error)
+}
+
+// NewGeocoder builds the geocoder configured for the app. Google is used when it is the configured
+// provider, falling back to Nominatim. Results are cached in the database when one is given.
+func NewGeocoder(cfg *config.Config, db *sql.DB) Geocoder {
+	nominatim := NewNominatimGeocoder(cfg.NominatimURL, "JourneyCo Serve Day ("+cfg.MailFrom+")")
+
+	var geocoder Geocoder = nominatim
+	if cfg.GeocoderProvider == "google" && cfg.GoogleMapsAPIKey != "" {
+		geocoder = FallbackGeocoder{NewGoogleGeocoder(cfg.GoogleMapsAPIKey), nominatim}
+	}
+
+	if db == nil {
+		return geocoder
+	}
+	return NewCachedGeocoder(db, geocoder)
+}
+
+// FallbackGeocoder tries each geocoder in turn until one finds the address
+type FallbackGeocoder []Geocoder
+
+// Geocode converts an address to latitude and longitude
+func (f FallbackGeocoder) Geocode(ctx context.Context, address string) (*GeocodingResult, error) {
+	err := ErrNoResults
+	for _, geocoder := range f {
+		var result *GeocodingResult
+		if result, err = geocoder.Geocode(ctx, address); err == nil {
+			return result, nil
+		}
+		if !errors.Is(err, ErrNoResults) {
+			log.Printf("geocoder failed, trying the next one: %v", err)
+		}
+	}
+	return nil, err
+}
+
+// ReverseGeocode finds the address at a latitude and longitude
+func (f FallbackGeocoder) ReverseGeocode(ctx context.Context, lat, lng float64) (*GeocodingResult, error) {
+	err := ErrNoResults
+	for _, geocoder := range f {
+		var result *GeocodingResult
+		if result, err = geocoder.ReverseGeocode(ctx, lat, lng); err == nil {
+			return result, nil
+		}
+		if !errors.Is(err, ErrNoResults) {
+			log.Printf("reverse geocoder failed, trying the next one: %v", err)
+		}
+	}
+	return nil, err
+}
+
+// CachedGeocoder serves addresses it has already geocoded from the database, so repeated lookups
+// need no request to a provider and keep working while the provider is unreachable
+type CachedGeocoder struct {
+	db       *sql.DB
+	geocoder Geocoder
+}
+
+// NewCachedGeocoder wraps a geocoder with the persistent geocode cache
+func NewCachedGeocoder(db *sql.DB, geocoder Geocoder) *CachedGeocoder {
+	return &CachedGeocoder{db: db, geocoder: geocoder}
+}
+
+// Geocode converts an address to latitude and longitude
+func (c *CachedGeocoder) Geocode(ctx context.Context, address string) (*GeocodingResult, error) {
+	key := NormalizeAddress(address)
+	if key == "" {
+		return nil, ErrNoResults
+	}
+
+	cached, err := models.GetGeocodeCacheEntry(ctx, c.db, key)
+	if err != nil {
+		log.Println("error reading geocode cache: ", err)
+	}
+	if cached != nil {
+		return &GeocodingResult{
+			Latitude:  cached.Latitude,
+			Longitude: cached.Longitude,
+			Address:   cached.FormattedAddress,
+			Provider:  cached.Provider,
+		}, nil
+	}
+
+	result, err := c.geocoder.Geocode(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.GeocodeCacheEntry{
+		Address:          key,
+		Latitude:         result.Latitude,
+		Longitude:        result.Longitude,
+		FormattedAddress: result.Address,
+		Provider:         result.Provider,
+	}
+	if err = models.SaveGeocodeCacheEntry(ctx, c.db, entry); err != nil {
+		log.Println("error writing geocode cache: ", err)
+	}
+
+	return result, nil
+}
+
+// ReverseGeocode finds the address at a latitude and longitude. Reverse lookups are not cached.
+func (c *CachedGeocoder) ReverseGeocode(ctx context.Context, lat, lng float64) (*GeocodingResult, error) {
+	return c.geocoder.ReverseGeocode(ctx, lat, lng)
+}
+
+// NormalizeAddress reduces an address to the form used as its cache key: lower case, with runs of
+// whitespace collapsed and surrounding punctuation removed
+func NormalizeAddress(address string) string {
+	return strings.Trim(strings.Join(strings.Fields(strings.ToLower(address)), " "), " ,.;")
+}
@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"serve/services"
+	"serve/testutils"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	assert.Equal(t, "100 third st, castle rock, co", services.NormalizeAddress("  100 Third St,\n Castle Rock,  CO. "))
+}
+
+func TestFallbackGeocoder(t *testing.T) {
+	primary := testutils.NewFakeGeocoder(
+		map[string]services.GeocodingResult{"100 Third St": {Latitude: 39.37, Longitude: -104.86}},
+	)
+	secondary := testutils.NewFakeGeocoder(
+		map[string]services.GeocodingResult{"200 Fourth St": {Latitude: 39.38, Longitude: -104.87}},
+	)
+	geocoder := services.FallbackGeocoder{primary, secondary}
+
+	result, err := geocoder.Geocode(context.Background(), "100 third st")
+	assert.NoError(t, err)
+	assert.Equal(t, 39.37, result.Latitude)
+	assert.Empty(t, secondary.Calls(), "the fallback should not be used when the first geocoder finds the address")
+
+	result, err = geocoder.Geocode(context.Background(), "200 Fourth St")
+	assert.NoError(t, err)
+	assert.Equal(t, 39.38, result.Latitude)
+
+	_, err = geocoder.Geocode(context.Background(), "nowhere")
+	assert.True(t, errors.Is(err, services.ErrNoResults))
+}
+
+func TestNominatimGeocoder(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/search", r.URL.Path)
+				assert.Equal(t, "serve-test", r.Header.Get("User-Agent"))
+				if r.URL.Query().Get("q") == "nowhere" {
+					_, _ = w.Write([]byte(`[]`))
+					return
+				}
+				_, _ = w.Write([]byte(`[{"lat":"39.3722","lon":"-104.8561","display_name":"Castle Rock, CO"}]`))
+			},
+		),
+	)
+	defer server.Close()
+
+	geocoder := services.NewNominatimGeocoder(server.URL, "serve-test")
+
+	result, err := geocoder.Geocode(context.Background(), "Castle Rock")
+	assert.NoError(t, err)
+	assert.Equal(t, 39.3722, result.Latitude)
+	assert.Equal(t, -104.8561, result.Longitude)
+	assert.Equal(t, "Castle Rock, CO", result.Address)
+	assert.Equal(t, "nominatim", result.Provider)
+
+	_, err = geocoder.Geocode(context.Background(), "nowhere")
+	assert.True(t, errors.Is(err, services.ErrNoResults))
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"serve/models"
+)
+
+// earthRadiusKm is the mean radius of the earth, used for great-circle distances
+const earthRadiusKm = 6371.0
+
+// LocationCheck is the result of comparing a project's address with its coordinates. The geocoded
+// coordinates and formatted address are offered to the admin as the normalized location.
+type LocationCheck struct {
+	FormattedAddress string  `json:"formatted_address,omitempty"`
+	Latitude         float64 `json:"latitude,omitempty"`
+	Longitude        float64 `json:"longitude,omitempty"`
+	DistanceKm       float64 `json:"distance_km"`
+	Warning          string  `json:"warning,omitempty"`
+}
+
+// LocationValidator checks that project coordinates agree with the project address
+type LocationValidator struct {
+	geocoder  Geocoder
+	warningKm float64
+}
+
+// NewLocationValidator creates a validator that warns when coordinates are more than warningMeters
+// from where the address geocodes
+func NewLocationValidator(geocoder Geocoder, warningMeters int) *LocationValidator {
+	return &LocationValidator{geocoder: geocoder, warningKm: float64(warningMeters) / 1000}
+}
+
+// Check geocodes the address and compares it with the given coordinates. It returns nil when there is
+// nothing to check: the address is empty or still to be determined. Missing coordinates (0, 0) are not
+// compared; the caller can fill them in from the check.
+func (v *LocationValidator) Check(ctx context.Context, address string, lat, lng float64) (*LocationCheck, error) {
+	if address == "" || models.IsApproximateLocation(address) {
+		return nil, nil
+	}
+
+	result, err := v.geocoder.Geocode(ctx, address)
+	if err != nil {
+		if errors.Is(err, ErrNoResults) {
+			return &LocationCheck{Warning: "The address could not be found; check it for typos"}, nil
+		}
+		return nil, err
+	}
+
+	check := &LocationCheck{
+		FormattedAddress: result.Address,
+		Latitude:         result.Latitude,
+		Longitude:        result.Longitude,
+	}
+	if lat == 0 && lng == 0 {
+		return check, nil
+	}
+
+	check.DistanceKm = DistanceKm(lat, lng, result.Latitude, result.Longitude)
+	if check.DistanceKm > v.warningKm {
+		check.Warning = fmt.Sprintf("The coordinates are %.1f km from the address", check.DistanceKm)
+	}
+	return check, nil
+}
+
+// DistanceKm returns the great-circle distance between two points in kilometers
+func DistanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,59 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"serve/services"
+	"serve/testutils"
+)
+
+func TestLocationValidator(t *testing.T) {
+	geocoder := testutils.NewFakeGeocoder(map[string]services.GeocodingResult{
+		"100 Third St": {Latitude: 39.3722, Longitude: -104.8561, Address: "100 Third St, Castle Rock, CO 80104"},
+	})
+	validator := services.NewLocationValidator(geocoder, 1000)
+
+	tests := []struct {
+		name        string
+		address     string
+		lat, lng    float64
+		wantCheck   bool
+		wantWarning bool
+	}{
+		{"matching coordinates", "100 third st", 39.3725, -104.8565, true, false},
+		{"coordinates in the wrong county", "100 Third St", 39.7392, -104.9903, true, true},
+		{"missing coordinates", "100 Third St", 0, 0, true, false},
+		{"unknown address", "1 Nowhere Ln", 39.37, -104.85, true, true},
+		{"address to be determined", "TBD - Castle Rock", 39.37, -104.85, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check, err := validator.Check(context.Background(), tt.address, tt.lat, tt.lng)
+			assert.NoError(t, err)
+			if !tt.wantCheck {
+				assert.Nil(t, check)
+				return
+			}
+			assert.NotNil(t, check)
+			assert.Equal(t, tt.wantWarning, check.Warning != "", check.Warning)
+		})
+	}
+}
+
+func TestReverseGeocode(t *testing.T) {
+	geocoder := services.FallbackGeocoder{
+		testutils.NewFakeGeocoder(nil),
+		testutils.NewFakeGeocoder(map[string]services.GeocodingResult{
+			"100 Third St": {Latitude: 39.3722, Longitude: -104.8561, Address: "100 Third St, Castle Rock, CO 80104"},
+		}),
+	}
+
+	result, err := geocoder.ReverseGeocode(context.Background(), 39.3722, -104.8561)
+	assert.NoError(t, err)
+	assert.Equal(t, "100 Third St, Castle Rock, CO 80104", result.Address)
+
+	assert.InDelta(t, 42.4, services.DistanceKm(39.3722, -104.8561, 39.7392, -104.9903), 1.0)
+}
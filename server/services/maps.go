@@ -1,17 +1,20 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
-	"os"
 )
 
-// MapsService handles interactions with the Google Maps API
-type MapsService struct {
-	apiKey string
+// GoogleGeocoder geocodes addresses with the Google Places text search API and reverse geocodes
+// coordinates with the Google Geocoding API
+type GoogleGeocoder struct {
+	apiKey     string
+	baseURL    string
+	reverseURL string
+	client     *http.Client
 }
 
 // GeocodingResult represents the result from geocoding
@@ -19,6 +22,7 @@ type GeocodingResult struct {
 	Latitude  float64 `json:"latitude"`
 	Longitude float64 `json:"longitude"`
 	Address   string  `json:"formatted_address"`
+	Provider  string  `json:"provider"`
 }
 
 // GooglePlacesResponse represents the response from Google Places API
@@ -35,43 +39,50 @@ type GooglePlacesResponse struct {
 	Status string `json:"status"`
 }
 
-// NewMapsService creates a new Maps service
-func NewMapsService() *MapsService {
-	return &MapsService{
-		apiKey: os.Getenv("GOOGLE_MAPS_API_KEY"),
+// NewGoogleGeocoder creates a new Google geocoder
+func NewGoogleGeocoder(apiKey string) *GoogleGeocoder {
+	return &GoogleGeocoder{
+		apiKey:     apiKey,
+		baseURL:    "https://maps.googleapis.com/maps/api/place/textsearch/json",
+		reverseURL: "https://maps.googleapis.com/maps/api/geocode/json",
+		client:     &http.Client{Timeout: geocodeTimeout},
 	}
 }
 
-// GeocodeAddress converts an address to latitude and longitude
-func (s *MapsService) GeocodeAddress(address string) (*GeocodingResult, error) {
-	// Build the request URL
-	baseURL := "https://maps.googleapis.com/maps/api/place/textsearch/json"
+// Geocode converts an address to latitude and longitude
+func (g *GoogleGeocoder) Geocode(ctx context.Context, address string) (*GeocodingResult, error) {
 	params := url.Values{}
 	params.Add("query", address)
-	params.Add("key", s.apiKey)
-	requestURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
+	params.Add("key", g.apiKey)
 
-	// Send the request
-	resp, err := http.Get(requestURL)
-	if err != nil {
-		return nil, fmt.Errorf("error sending geocoding request: %v", err)
-	}
-	defer resp.Body.Close()
+	return g.firstResult(ctx, g.baseURL+"?"+params.Encode(), address)
+}
 
-	// Read and parse the response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading geocoding response: %v", err)
-	}
+// ReverseGeocode finds the address at a latitude and longitude
+func (g *GoogleGeocoder) ReverseGeocode(ctx context.Context, lat, lng float64) (*GeocodingResult, error) {
+	params := url.Values{}
+	params.Add("latlng", fmt.Sprintf("%f,%f", lat, lng))
+	params.Add("key", g.apiKey)
+
+	return g.firstResult(ctx, g.reverseURL+"?"+params.Encode(), fmt.Sprintf("%f,%f", lat, lng))
+}
 
+// firstResult sends a Google request and returns its first result
+func (g *GoogleGeocoder) firstResult(ctx context.Context, requestURL, query string) (*GeocodingResult, error) {
 	var placesResponse GooglePlacesResponse
-	if err := json.Unmarshal(body, &placesResponse); err != nil {
-		return nil, fmt.Errorf("error parsing geocoding response: %v", err)
+	if err := getJSON(ctx, g.client, requestURL, nil, &placesResponse); err != nil {
+		return nil, err
 	}
 
-	// Check if the response has results
-	if placesResponse.Status != "OK" || len(placesResponse.Results) == 0 {
-		return nil, fmt.Errorf("no results found for address: %s", address)
+	switch placesResponse.Status {
+	case "OK":
+	case "ZERO_RESULTS":
+		return nil, fmt.Errorf("%w: %s", ErrNoResults, query)
+	default:
+		return nil, fmt.Errorf("geocoding request failed with status %s", placesResponse.Status)
+	}
+	if len(placesResponse.Results) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoResults, query)
 	}
 
 	// Get the first result
@@ -80,5 +91,32 @@ func (s *MapsService) GeocodeAddress(address string) (*GeocodingResult, error) {
 		Latitude:  result.Geometry.Location.Lat,
 		Longitude: result.Geometry.Location.Lng,
 		Address:   result.FormattedAddress,
+		Provider:  "google",
 	}, nil
 }
+
+// getJSON sends a GET request and decodes the JSON response into target
+func getJSON(ctx context.Context, client *http.Client, requestURL string, header http.Header, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("error creating geocoding request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending geocoding request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("geocoding request failed with status code %d", resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("error parsing geocoding response: %w", err)
+	}
+	return nil
+}
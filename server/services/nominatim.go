@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// NominatimGeocoder geocodes addresses with an OpenStreetMap Nominatim server. It needs no API key,
+// which makes it a fallback when Google is unavailable.
+type NominatimGeocoder struct {
+	baseURL   string
+	userAgent string
+	client    *http.Client
+}
+
+// nominatimPlace is a single result from the Nominatim search API
+type nominatimPlace struct {
+	Lat         string `json:"lat"`
+	Lon         string `json:"lon"`
+	DisplayName string `json:"display_name"`
+	Error       string `json:"error"`
+}
+
+// NewNominatimGeocoder creates a new Nominatim geocoder. Nominatim's usage policy requires a user
+// agent that identifies the application.
+func NewNominatimGeocoder(baseURL, userAgent string) *NominatimGeocoder {
+	return &NominatimGeocoder{
+		baseURL:   baseURL,
+		userAgent: userAgent,
+		client:    &http.Client{Timeout: geocodeTimeout},
+	}
+}
+
+// Geocode converts an address to latitude and longitude
+func (g *NominatimGeocoder) Geocode(ctx context.Context, address string) (*GeocodingResult, error) {
+	params := url.Values{}
+	params.Add("q", address)
+	params.Add("format", "jsonv2")
+	params.Add("limit", "1")
+
+	var places []nominatimPlace
+	header := http.Header{"User-Agent": {g.userAgent}}
+	if err := getJSON(ctx, g.client, g.baseURL+"/search?"+params.Encode(), header, &places); err != nil {
+		return nil, err
+	}
+
+	if len(places) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoResults, address)
+	}
+
+	return places[0].result()
+}
+
+// ReverseGeocode finds the address at a latitude and longitude
+func (g *NominatimGeocoder) ReverseGeocode(ctx context.Context, lat, lng float64) (*GeocodingResult, error) {
+	params := url.Values{}
+	params.Add("lat", strconv.FormatFloat(lat, 'f', -1, 64))
+	params.Add("lon", strconv.FormatFloat(lng, 'f', -1, 64))
+	params.Add("format", "jsonv2")
+
+	var place nominatimPlace
+	header := http.Header{"User-Agent": {g.userAgent}}
+	if err := getJSON(ctx, g.client, g.baseURL+"/reverse?"+params.Encode(), header, &place); err != nil {
+		return nil, err
+	}
+
+	// Nominatim reports a point it cannot place as an error in an otherwise successful response
+	if place.Error != "" || place.Lat == "" {
+		return nil, fmt.Errorf("%w: %f,%f", ErrNoResults, lat, lng)
+	}
+
+	return place.result()
+}
+
+// result converts a Nominatim place to a geocoding result
+func (p nominatimPlace) result() (*GeocodingResult, error) {
+	lat, err := strconv.ParseFloat(p.Lat, 64)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing geocoding latitude: %w", err)
+	}
+	lng, err := strconv.ParseFloat(p.Lon, 64)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing geocoding longitude: %w", err)
+	}
+
+	return &GeocodingResult{
+		Latitude:  lat,
+		Longitude: lng,
+		Address:   p.DisplayName,
+		Provider:  "nominatim",
+	}, nil
+}
@@ -24,6 +24,7 @@ func CreateAssessment(cfg *config.Config, token string) error {
 	cli, err := recaptcha.NewClient(ctx)
 	if err != nil {
 		fmt.Printf("Error creating reCAPTCHA client\n")
+		return err
 	}
 	defer cli.Close()
 
@@ -1,28 +1,40 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"sync"
 	"time"
 
+	"serve/config"
 	"serve/models"
 )
 
-// Scheduler handles scheduling of email reminders
+// Scheduler handles scheduling of email reminders, project change notices and trash retention
 type Scheduler struct {
 	DB           *sql.DB
+	Config       *config.Config
 	EmailService *EmailService
 	TextService  *TextService
 	stop         chan struct{}
+
+	// sendingNotices holds the projects whose change notices are being sent, so they are not picked up
+	// again before they are cleared
+	sendingMu      sync.Mutex
+	sendingNotices map[int]bool
 }
 
 // NewScheduler creates a new scheduler service
-func NewScheduler(db *sql.DB, emailService *EmailService, textService *TextService) *Scheduler {
+func NewScheduler(db *sql.DB, cfg *config.Config, emailService *EmailService, textService *TextService) *Scheduler {
 	return &Scheduler{
 		DB:           db,
+		Config:       cfg,
 		EmailService: emailService,
 		TextService:  textService,
 		stop:         make(chan struct{}),
+
+		sendingNotices: map[int]bool{},
 	}
 }
 
@@ -30,7 +42,28 @@ func NewScheduler(db *sql.DB, emailService *EmailService, textService *TextServi
 func (s *Scheduler) Start() {
 	log.Println("Starting email reminder scheduler...")
 
+	// Reminders take hours to send, so they run on their own loop rather than holding up change notices
+	go s.runDaily()
+
+	// Change notices wait for edits to go quiet, so check for due ones often
+	noticeTicker := time.NewTicker(time.Minute)
+	defer noticeTicker.Stop()
+
+	for {
+		select {
+		case <-noticeTicker.C:
+			s.processChangeNotices()
+		case <-s.stop:
+			log.Println("Stopping email reminder scheduler...")
+			return
+		}
+	}
+}
+
+// runDaily purges the trash and sends reminders on startup and then once a day
+func (s *Scheduler) runDaily() {
 	// Run immediately on startup
+	s.purgeTrash()
 	s.processReminders()
 
 	// Set up a ticker to run daily at a specific time (e.g., 8:00 AM)
@@ -40,9 +73,9 @@ func (s *Scheduler) Start() {
 	for {
 		select {
 		case <-ticker.C:
+			s.purgeTrash()
 			s.processReminders()
 		case <-s.stop:
-			log.Println("Stopping email reminder scheduler...")
 			return
 		}
 	}
@@ -53,6 +86,124 @@ func (s *Scheduler) Stop() {
 	close(s.stop)
 }
 
+// purgeTrash permanently removes soft deleted records older than the configured retention period
+func (s *Scheduler) purgeTrash() {
+	if s.Config.TrashRetentionDays <= 0 {
+		return
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -s.Config.TrashRetentionDays)
+	purged, err := models.PurgeDeleted(context.Background(), s.DB, cutoff)
+	if err != nil {
+		log.Printf("Error purging deleted records: %v", err)
+		return
+	}
+
+	log.Printf("Purged %d records deleted before %s", purged, cutoff.Format(time.RFC3339))
+}
+
+// processChangeNotices sends registrants the project changes whose edits have settled. Notices stay
+// queued until they are sent, so one that fails is tried again on the next run.
+func (s *Scheduler) processChangeNotices() {
+	ctx := context.Background()
+	now := time.Now()
+	notices, err := models.GetDueProjectChanges(
+		ctx, s.DB,
+		now.Add(-time.Duration(s.Config.ChangeNoticeQuietMinutes)*time.Minute),
+		now.Add(-time.Duration(s.Config.ChangeNoticeMaxWaitMinutes)*time.Minute),
+	)
+	if err != nil {
+		log.Printf("Error getting project change notices: %v", err)
+		return
+	}
+
+	for _, notice := range notices {
+		if !s.startSendingNotice(notice.ProjectID) {
+			continue
+		}
+
+		project, err := models.GetProjectByID(ctx, s.DB, notice.ProjectID)
+		if err != nil {
+			log.Printf("Error getting project %d for change notice: %v", notice.ProjectID, err)
+			s.finishSendingNotice(notice.ProjectID)
+			continue
+		}
+
+		var changes []models.ProjectChange
+		if project != nil {
+			changes = project.VolunteerChanges(notice.Changes, now, s.Config.LocationRevealDays)
+		}
+		var registered []models.Registration
+		if len(changes) > 0 {
+			registrations, err := models.GetProjectRegistrations(ctx, s.DB, notice.ProjectID)
+			if err != nil {
+				log.Printf("Error getting registrations for project %d change notice: %v", notice.ProjectID, err)
+				s.finishSendingNotice(notice.ProjectID)
+				continue
+			}
+			for _, reg := range registrations {
+				if reg.Status == "registered" {
+					registered = append(registered, reg)
+				}
+			}
+		}
+
+		if len(registered) == 0 {
+			// there is no one to tell
+			s.clearChangeNotice(ctx, notice)
+			continue
+		}
+
+		volunteerProject := project.ForVolunteers(now, s.Config.LocationRevealDays)
+		log.Printf("Sending project %d change notice to %d registrants", notice.ProjectID, len(registered))
+		go s.sendChangeNotice(notice, &volunteerProject, registered, changes)
+	}
+}
+
+// sendChangeNotice emails and texts a change notice to the project's registrants, clearing it once sent.
+// If none of the emails could be sent the notice stays queued for the next run.
+func (s *Scheduler) sendChangeNotice(
+	notice models.ProjectChangeNotice, project *models.Project, registered []models.Registration,
+	changes []models.ProjectChange,
+) {
+	if err := s.EmailService.SendProjectChanged(project, registered, changes); err != nil {
+		log.Printf("Error sending project %d change notice, will retry: %v", notice.ProjectID, err)
+		s.finishSendingNotice(notice.ProjectID)
+		return
+	}
+	s.TextService.SendProjectChangedText(project, registered, changes)
+
+	s.clearChangeNotice(context.Background(), notice)
+}
+
+// clearChangeNotice removes a notice that has been dealt with and lets the project's notices be sent again
+func (s *Scheduler) clearChangeNotice(ctx context.Context, notice models.ProjectChangeNotice) {
+	defer s.finishSendingNotice(notice.ProjectID)
+	if err := models.ClearProjectChangeNotice(ctx, s.DB, notice); err != nil {
+		log.Printf("Error clearing project %d change notice: %v", notice.ProjectID, err)
+	}
+}
+
+// startSendingNotice claims a project's change notice for sending, reporting false if it is already being sent
+func (s *Scheduler) startSendingNotice(projectID int) bool {
+	s.sendingMu.Lock()
+	defer s.sendingMu.Unlock()
+
+	if s.sendingNotices[projectID] {
+		return false
+	}
+	s.sendingNotices[projectID] = true
+	return true
+}
+
+// finishSendingNotice releases a project's change notice once it has been sent or given up on
+func (s *Scheduler) finishSendingNotice(projectID int) {
+	s.sendingMu.Lock()
+	defer s.sendingMu.Unlock()
+
+	delete(s.sendingNotices, projectID)
+}
+
 // processReminders processes all reminders
 func (s *Scheduler) processReminders() {
 	log.Println("Processing email reminders...")
@@ -77,15 +228,15 @@ func (s *Scheduler) processReminderForDays(days int) {
 
 	// send emails - we are rate limited to 200 emails per hour by mailtrap, so we will limit ourselves to 150
 	// just to be safe. This is in case additional people register or obtain emails in the hour while we are
-	// sending registration emails
-	ticker := time.NewTicker(24 * time.Second) // 3600/180 = 24s per email
-	defer ticker.Stop()
-
+	// sending registration emails. Change notices share the same limit.
 	for _, reg := range registrations {
+		if err := s.EmailService.scheduled.Wait(context.Background()); err != nil {
+			log.Printf("Error waiting to send %d days reminder emails: %v", days, err)
+			return
+		}
 		if err := s.EmailService.SendReminderEmail(&reg, days); err != nil {
 			log.Printf("Error sending %d days reminder email to %s: %v", days, reg.User.Email, err)
 		}
-		<-ticker.C // Wait for the next tick before sending the next email
 	}
 
 	// send text messages - not doing this in 2025
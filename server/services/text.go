@@ -9,6 +9,7 @@ import (
 	"log"
 	"net/http"
 	"slices"
+	"strings"
 	"time"
 
 	"serve/config"
@@ -159,6 +160,73 @@ func (s *TextService) SendReminderText(list []models.Registration, daysLeft int)
 	return req.sendText()
 }
 
+// SendProjectCancelledText lets released registrants who allow texts know their project was cancelled
+func (s *TextService) SendProjectCancelledText(project *models.Project, registrations []models.Registration) {
+	var allowedList []models.Registration
+	for _, reg := range registrations {
+		if reg.User.TextPermission { // exclude users who do not want texts
+			allowedList = append(allowedList, reg)
+		}
+	}
+	if len(allowedList) == 0 {
+		return
+	}
+
+	req := ClearStreamRequest{
+		From:       clearstreamTextFrom,
+		TextHeader: "Journey Serve Day",
+		TextBody: fmt.Sprintf(
+			"Your Serve Day project %s has been cancelled. Check your email for details.", project.Title,
+		),
+		List:   allowedList,
+		APIKey: s.APIKey,
+	}
+
+	if err := req.sendText(); err != nil {
+		log.Printf("Failed to send cancellation texts for project %d: %v", project.ID, err)
+	}
+}
+
+// SendProjectChangedText gives registrants who allow texts a summary of what changed about their project.
+// Descriptions are left to the email, as they are too long for a text.
+func (s *TextService) SendProjectChangedText(
+	project *models.Project, registrations []models.Registration, changes []models.ProjectChange,
+) {
+	var allowedList []models.Registration
+	for _, reg := range registrations {
+		if reg.User.TextPermission { // exclude users who do not want texts
+			allowedList = append(allowedList, reg)
+		}
+	}
+	if len(allowedList) == 0 {
+		return
+	}
+
+	var summary []string
+	for _, c := range changes {
+		if c.Field == "description" {
+			summary = append(summary, "the description was updated")
+			continue
+		}
+		summary = append(summary, fmt.Sprintf("%s is now %s", strings.ToLower(c.Label), c.After))
+	}
+
+	req := ClearStreamRequest{
+		From:       clearstreamTextFrom,
+		TextHeader: "Journey Serve Day",
+		TextBody: fmt.Sprintf(
+			"Your Serve Day project %s has changed: %s. Check your email for details.",
+			project.Title, strings.Join(summary, "; "),
+		),
+		List:   allowedList,
+		APIKey: s.APIKey,
+	}
+
+	if err := req.sendText(); err != nil {
+		log.Printf("Failed to send change texts for project %d: %v", project.ID, err)
+	}
+}
+
 func (s *TextService) SendTestText() error {
 	req := ClearStreamRequest{
 		From:       clearstreamTextFrom,
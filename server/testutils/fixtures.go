@@ -3,6 +3,8 @@ package testutils
 import (
 	"database/sql"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // CreateTestProject creates a test project in the database
@@ -23,6 +25,25 @@ func CreateTestProject(db *sql.DB) (int, error) {
 	return projectID, err
 }
 
+// CreateTestRegistration creates a user who registered for the project by email, as anonymous
+// volunteers do, and returns the user's ID
+func CreateTestRegistration(db *sql.DB, projectID int, email string) (string, error) {
+	userID := uuid.NewString()
+	if _, err := db.Exec(
+		`INSERT INTO users (id, email, first_name, last_name, phone)
+		VALUES ($1, $2, 'Test', 'Volunteer', '5555555555')`,
+		userID, email,
+	); err != nil {
+		return "", err
+	}
+
+	_, err := db.Exec(
+		`INSERT INTO registrations (user_id, project_id, status, guest_count) VALUES ($1, $2, 'registered', 0)`,
+		userID, projectID,
+	)
+	return userID, err
+}
+
 // CleanTestData removes all test data from the database
 func CleanTestData(db *sql.DB) error {
 	_, err := db.Exec(
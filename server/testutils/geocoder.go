@@ -0,0 +1,67 @@
+package testutils
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"serve/services"
+)
+
+// FakeGeocoder is a Geocoder for tests that answers from a fixed set of addresses without any
+// network requests. Addresses are matched in their normalized form.
+type FakeGeocoder struct {
+	mu      sync.Mutex
+	results map[string]services.GeocodingResult
+	calls   []string
+}
+
+// NewFakeGeocoder creates a fake geocoder that knows the given addresses
+func NewFakeGeocoder(results map[string]services.GeocodingResult) *FakeGeocoder {
+	f := &FakeGeocoder{results: map[string]services.GeocodingResult{}}
+	for address, result := range results {
+		f.results[services.NormalizeAddress(address)] = result
+	}
+	return f
+}
+
+// Geocode returns the known result for the address, or services.ErrNoResults
+func (f *FakeGeocoder) Geocode(_ context.Context, address string) (*services.GeocodingResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, address)
+	result, ok := f.results[services.NormalizeAddress(address)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", services.ErrNoResults, address)
+	}
+	if result.Provider == "" {
+		result.Provider = "fake"
+	}
+	return &result, nil
+}
+
+// ReverseGeocode returns the known result at the coordinates, or services.ErrNoResults
+func (f *FakeGeocoder) ReverseGeocode(_ context.Context, lat, lng float64) (*services.GeocodingResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, fmt.Sprintf("%f,%f", lat, lng))
+	for _, result := range f.results {
+		if math.Abs(result.Latitude-lat) < 1e-4 && math.Abs(result.Longitude-lng) < 1e-4 {
+			if result.Provider == "" {
+				result.Provider = "fake"
+			}
+			return &result, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %f,%f", services.ErrNoResults, lat, lng)
+}
+
+// Calls returns the addresses and coordinates the fake has been asked to look up, in order
+func (f *FakeGeocoder) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
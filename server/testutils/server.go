@@ -4,9 +4,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"net/http/httptest"
 	"os"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
@@ -15,7 +18,8 @@ import (
 	"github.com/integralist/go-findroot/find"
 	_ "github.com/lib/pq"
 	"serve/config"
-	"serve/handlers"
+	"serve/middleware"
+	"serve/routes"
 	"serve/services"
 )
 
@@ -24,15 +28,19 @@ type TestServer struct {
 	Server *httptest.Server
 	DB     *sql.DB
 	Router *mux.Router
+	Issuer *middleware.LocalIssuer
 }
 
 // NewTestServer creates a new test server with a test database
 func NewTestServer() *TestServer {
-	// Set test environment
+	// Set test environment, signing tokens locally instead of with Auth0
 	err := os.Setenv("DEV_MODE", "true")
 	if err != nil {
 		log.Fatal("Failed to load dev_mode env var:", err)
 	}
+	if err = os.Setenv("AUTH_MODE", config.AuthModeLocal); err != nil {
+		log.Fatal("Failed to load auth_mode env var:", err)
+	}
 
 	// Initialize test config
 	cfg, err := config.Load()
@@ -50,10 +58,23 @@ func NewTestServer() *TestServer {
 	emailService := services.NewEmailService(cfg)
 	textService := services.NewTextService(cfg)
 
+	validateToken, issuer, err := routes.NewTokenValidator(cfg)
+	if err != nil {
+		log.Fatal("Failed to set up token validation:", err)
+	}
+
 	// Register routes
-	api := router.PathPrefix("/api").Subrouter()
-	projectRouter := api.PathPrefix("/projects").Subrouter()
-	handlers.RegisterProjectRoutes(projectRouter, db, cfg, emailService, textService)
+	routes.Register(
+		router, routes.Dependencies{
+			DB:            db,
+			Config:        cfg,
+			EmailService:  emailService,
+			TextService:   textService,
+			Geocoder:      NewFakeGeocoder(nil),
+			ValidateToken: validateToken,
+			LocalIssuer:   issuer,
+		},
+	)
 
 	// Create test server
 	ts := httptest.NewServer(router)
@@ -62,7 +83,38 @@ func NewTestServer() *TestServer {
 		Server: ts,
 		DB:     db,
 		Router: router,
+		Issuer: issuer,
+	}
+}
+
+// Token mints a bearer token for the subject with the given Auth0 permissions. "edit:projects" grants
+// every admin permission; "role:viewer" and the like grant a single role.
+func (ts *TestServer) Token(subject string, permissions ...string) string {
+	return ts.TokenWithClaims(subject, middleware.CustomClaims{Permissions: permissions})
+}
+
+// TokenWithClaims mints a bearer token for the subject with the given permissions and profile claims
+func (ts *TestServer) TokenWithClaims(subject string, claims middleware.CustomClaims) string {
+	token, err := ts.Issuer.Mint(subject, claims, time.Hour)
+	if err != nil {
+		log.Fatal("Failed to mint token:", err)
+	}
+	return token
+}
+
+// NewRequest builds a request to the test server, signed in with the token when it is not empty
+func (ts *TestServer) NewRequest(method, path, token string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, ts.Server.URL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
+	return req, nil
 }
 
 // Close closes the test server and database connections